@@ -0,0 +1,801 @@
+// Search API served by k8sx grpc-serve. Regenerate the Go code after changing it with:
+//   protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api/searchpb/search.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: api/searchpb/search.proto
+
+package searchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SearchRequest takes exactly one of ip and name. They are plain fields rather than a oneof,
+// which would keep only the last one sent, so requests setting both can be rejected.
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IP address or CIDR range matched against pod IPs, host IPs and service IPs
+	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Pod name, matching names containing it unless filters.exact_name is set
+	Name          string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Filters       *Filters `protobuf:"bytes,3,opt,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_api_searchpb_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFilters() *Filters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+// Filters narrow a search; unset fields keep the defaults of the server flags
+type Filters struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Context to search instead of all contexts
+	Context string `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	// Namespaces to search instead of all namespaces
+	Namespaces []string `protobuf:"bytes,2,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	// Server-side field selector for pod lists, e.g. status.phase=Running
+	FieldSelector string `protobuf:"bytes,3,opt,name=field_selector,json=fieldSelector,proto3" json:"field_selector,omitempty"`
+	// Keep only pods in the Running phase
+	RunningOnly bool `protobuf:"varint,4,opt,name=running_only,json=runningOnly,proto3" json:"running_only,omitempty"`
+	// Keep only pods running as this service account
+	ServiceAccount string `protobuf:"bytes,5,opt,name=service_account,json=serviceAccount,proto3" json:"service_account,omitempty"`
+	// Keep only pods whose top owner kind is one of these ("none" = no owner)
+	OwnerKinds []string `protobuf:"bytes,6,rep,name=owner_kinds,json=ownerKinds,proto3" json:"owner_kinds,omitempty"`
+	// Keep only pods matching all annotation filters: "key" (present) or "key=glob"
+	Annotations []string `protobuf:"bytes,7,rep,name=annotations,proto3" json:"annotations,omitempty"`
+	// Match whole pod names only
+	ExactName bool `protobuf:"varint,8,opt,name=exact_name,json=exactName,proto3" json:"exact_name,omitempty"`
+	// Stop the search once this many matches are found (0 = server default)
+	MaxResults    int32 `protobuf:"varint,9,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Filters) Reset() {
+	*x = Filters{}
+	mi := &file_api_searchpb_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Filters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filters) ProtoMessage() {}
+
+func (x *Filters) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filters.ProtoReflect.Descriptor instead.
+func (*Filters) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Filters) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *Filters) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+func (x *Filters) GetFieldSelector() string {
+	if x != nil {
+		return x.FieldSelector
+	}
+	return ""
+}
+
+func (x *Filters) GetRunningOnly() bool {
+	if x != nil {
+		return x.RunningOnly
+	}
+	return false
+}
+
+func (x *Filters) GetServiceAccount() string {
+	if x != nil {
+		return x.ServiceAccount
+	}
+	return ""
+}
+
+func (x *Filters) GetOwnerKinds() []string {
+	if x != nil {
+		return x.OwnerKinds
+	}
+	return nil
+}
+
+func (x *Filters) GetAnnotations() []string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Filters) GetExactName() bool {
+	if x != nil {
+		return x.ExactName
+	}
+	return false
+}
+
+func (x *Filters) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+// SearchResult holds the matches found in one namespace of one context
+type SearchResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Context string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	// API server URL of the context's cluster
+	Server    string `protobuf:"bytes,2,opt,name=server,proto3" json:"server,omitempty"`
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Pods      []*Pod `protobuf:"bytes,4,rep,name=pods,proto3" json:"pods,omitempty"`
+	// Services are only returned by IP searches
+	Services      []*Service `protobuf:"bytes,5,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_api_searchpb_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchResult) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *SearchResult) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *SearchResult) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SearchResult) GetPods() []*Pod {
+	if x != nil {
+		return x.Pods
+	}
+	return nil
+}
+
+func (x *SearchResult) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type Pod struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Uid            string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace      string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	PodIp          string                 `protobuf:"bytes,4,opt,name=pod_ip,json=podIp,proto3" json:"pod_ip,omitempty"`
+	PodIps         []string               `protobuf:"bytes,5,rep,name=pod_ips,json=podIps,proto3" json:"pod_ips,omitempty"`
+	HostIp         string                 `protobuf:"bytes,6,opt,name=host_ip,json=hostIp,proto3" json:"host_ip,omitempty"`
+	NodeName       string                 `protobuf:"bytes,7,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	OwnerKind      string                 `protobuf:"bytes,8,opt,name=owner_kind,json=ownerKind,proto3" json:"owner_kind,omitempty"`
+	OwnerName      string                 `protobuf:"bytes,9,opt,name=owner_name,json=ownerName,proto3" json:"owner_name,omitempty"`
+	Phase          string                 `protobuf:"bytes,10,opt,name=phase,proto3" json:"phase,omitempty"`
+	ServiceAccount string                 `protobuf:"bytes,11,opt,name=service_account,json=serviceAccount,proto3" json:"service_account,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Set for pods that completed, failed or are being deleted
+	Terminated bool `protobuf:"varint,14,opt,name=terminated,proto3" json:"terminated,omitempty"`
+	// Address an IP search matched (PodIP or HostIP)
+	MatchedOn     string `protobuf:"bytes,15,opt,name=matched_on,json=matchedOn,proto3" json:"matched_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pod) Reset() {
+	*x = Pod{}
+	mi := &file_api_searchpb_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pod) ProtoMessage() {}
+
+func (x *Pod) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pod.ProtoReflect.Descriptor instead.
+func (*Pod) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Pod) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *Pod) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Pod) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Pod) GetPodIp() string {
+	if x != nil {
+		return x.PodIp
+	}
+	return ""
+}
+
+func (x *Pod) GetPodIps() []string {
+	if x != nil {
+		return x.PodIps
+	}
+	return nil
+}
+
+func (x *Pod) GetHostIp() string {
+	if x != nil {
+		return x.HostIp
+	}
+	return ""
+}
+
+func (x *Pod) GetNodeName() string {
+	if x != nil {
+		return x.NodeName
+	}
+	return ""
+}
+
+func (x *Pod) GetOwnerKind() string {
+	if x != nil {
+		return x.OwnerKind
+	}
+	return ""
+}
+
+func (x *Pod) GetOwnerName() string {
+	if x != nil {
+		return x.OwnerName
+	}
+	return ""
+}
+
+func (x *Pod) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Pod) GetServiceAccount() string {
+	if x != nil {
+		return x.ServiceAccount
+	}
+	return ""
+}
+
+func (x *Pod) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Pod) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Pod) GetTerminated() bool {
+	if x != nil {
+		return x.Terminated
+	}
+	return false
+}
+
+func (x *Pod) GetMatchedOn() string {
+	if x != nil {
+		return x.MatchedOn
+	}
+	return ""
+}
+
+type Service struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace    string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Type         string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ClusterIp    string                 `protobuf:"bytes,4,opt,name=cluster_ip,json=clusterIp,proto3" json:"cluster_ip,omitempty"`
+	ExternalIps  []string               `protobuf:"bytes,5,rep,name=external_ips,json=externalIps,proto3" json:"external_ips,omitempty"`
+	Ports        []*Port                `protobuf:"bytes,6,rep,name=ports,proto3" json:"ports,omitempty"`
+	Selector     map[string]string      `protobuf:"bytes,7,rep,name=selector,proto3" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExternalName string                 `protobuf:"bytes,8,opt,name=external_name,json=externalName,proto3" json:"external_name,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Address an IP search matched (ClusterIP, ExternalIP or LoadBalancerIngress)
+	MatchedOn string `protobuf:"bytes,10,opt,name=matched_on,json=matchedOn,proto3" json:"matched_on,omitempty"`
+	// Cluster IPs of both families on dual-stack services, cluster_ip being the primary one
+	ClusterIps    []string `protobuf:"bytes,11,rep,name=cluster_ips,json=clusterIps,proto3" json:"cluster_ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_api_searchpb_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Service) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Service) GetClusterIp() string {
+	if x != nil {
+		return x.ClusterIp
+	}
+	return ""
+}
+
+func (x *Service) GetExternalIps() []string {
+	if x != nil {
+		return x.ExternalIps
+	}
+	return nil
+}
+
+func (x *Service) GetPorts() []*Port {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *Service) GetSelector() map[string]string {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *Service) GetExternalName() string {
+	if x != nil {
+		return x.ExternalName
+	}
+	return ""
+}
+
+func (x *Service) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Service) GetMatchedOn() string {
+	if x != nil {
+		return x.MatchedOn
+	}
+	return ""
+}
+
+func (x *Service) GetClusterIps() []string {
+	if x != nil {
+		return x.ClusterIps
+	}
+	return nil
+}
+
+type Port struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Port  int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	// Pod port number or name the service forwards to
+	TargetPort string `protobuf:"bytes,3,opt,name=target_port,json=targetPort,proto3" json:"target_port,omitempty"`
+	// Port allocated on every node (0 = none)
+	NodePort      int32  `protobuf:"varint,4,opt,name=node_port,json=nodePort,proto3" json:"node_port,omitempty"`
+	Protocol      string `protobuf:"bytes,5,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Port) Reset() {
+	*x = Port{}
+	mi := &file_api_searchpb_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Port) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Port) ProtoMessage() {}
+
+func (x *Port) ProtoReflect() protoreflect.Message {
+	mi := &file_api_searchpb_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Port.ProtoReflect.Descriptor instead.
+func (*Port) Descriptor() ([]byte, []int) {
+	return file_api_searchpb_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Port) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Port) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Port) GetTargetPort() string {
+	if x != nil {
+		return x.TargetPort
+	}
+	return ""
+}
+
+func (x *Port) GetNodePort() int32 {
+	if x != nil {
+		return x.NodePort
+	}
+	return 0
+}
+
+func (x *Port) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+var File_api_searchpb_search_proto protoreflect.FileDescriptor
+
+const file_api_searchpb_search_proto_rawDesc = "" +
+	"\n" +
+	"\x19api/searchpb/search.proto\x12\ak8sx.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"_\n" +
+	"\rSearchRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12*\n" +
+	"\afilters\x18\x03 \x01(\v2\x10.k8sx.v1.FiltersR\afilters\"\xb9\x02\n" +
+	"\aFilters\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x1e\n" +
+	"\n" +
+	"namespaces\x18\x02 \x03(\tR\n" +
+	"namespaces\x12%\n" +
+	"\x0efield_selector\x18\x03 \x01(\tR\rfieldSelector\x12!\n" +
+	"\frunning_only\x18\x04 \x01(\bR\vrunningOnly\x12'\n" +
+	"\x0fservice_account\x18\x05 \x01(\tR\x0eserviceAccount\x12\x1f\n" +
+	"\vowner_kinds\x18\x06 \x03(\tR\n" +
+	"ownerKinds\x12 \n" +
+	"\vannotations\x18\a \x03(\tR\vannotations\x12\x1d\n" +
+	"\n" +
+	"exact_name\x18\b \x01(\bR\texactName\x12\x1f\n" +
+	"\vmax_results\x18\t \x01(\x05R\n" +
+	"maxResults\"\xae\x01\n" +
+	"\fSearchResult\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x16\n" +
+	"\x06server\x18\x02 \x01(\tR\x06server\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12 \n" +
+	"\x04pods\x18\x04 \x03(\v2\f.k8sx.v1.PodR\x04pods\x12,\n" +
+	"\bservices\x18\x05 \x03(\v2\x10.k8sx.v1.ServiceR\bservices\"\x93\x04\n" +
+	"\x03Pod\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06pod_ip\x18\x04 \x01(\tR\x05podIp\x12\x17\n" +
+	"\apod_ips\x18\x05 \x03(\tR\x06podIps\x12\x17\n" +
+	"\ahost_ip\x18\x06 \x01(\tR\x06hostIp\x12\x1b\n" +
+	"\tnode_name\x18\a \x01(\tR\bnodeName\x12\x1d\n" +
+	"\n" +
+	"owner_kind\x18\b \x01(\tR\townerKind\x12\x1d\n" +
+	"\n" +
+	"owner_name\x18\t \x01(\tR\townerName\x12\x14\n" +
+	"\x05phase\x18\n" +
+	" \x01(\tR\x05phase\x12'\n" +
+	"\x0fservice_account\x18\v \x01(\tR\x0eserviceAccount\x120\n" +
+	"\x06labels\x18\f \x03(\v2\x18.k8sx.v1.Pod.LabelsEntryR\x06labels\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1e\n" +
+	"\n" +
+	"terminated\x18\x0e \x01(\bR\n" +
+	"terminated\x12\x1d\n" +
+	"\n" +
+	"matched_on\x18\x0f \x01(\tR\tmatchedOn\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xcf\x03\n" +
+	"\aService\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"cluster_ip\x18\x04 \x01(\tR\tclusterIp\x12!\n" +
+	"\fexternal_ips\x18\x05 \x03(\tR\vexternalIps\x12#\n" +
+	"\x05ports\x18\x06 \x03(\v2\r.k8sx.v1.PortR\x05ports\x12:\n" +
+	"\bselector\x18\a \x03(\v2\x1e.k8sx.v1.Service.SelectorEntryR\bselector\x12#\n" +
+	"\rexternal_name\x18\b \x01(\tR\fexternalName\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"matched_on\x18\n" +
+	" \x01(\tR\tmatchedOn\x12\x1f\n" +
+	"\vcluster_ips\x18\v \x03(\tR\n" +
+	"clusterIps\x1a;\n" +
+	"\rSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x88\x01\n" +
+	"\x04Port\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1f\n" +
+	"\vtarget_port\x18\x03 \x01(\tR\n" +
+	"targetPort\x12\x1b\n" +
+	"\tnode_port\x18\x04 \x01(\x05R\bnodePort\x12\x1a\n" +
+	"\bprotocol\x18\x05 \x01(\tR\bprotocol2J\n" +
+	"\rSearchService\x129\n" +
+	"\x06Search\x12\x16.k8sx.v1.SearchRequest\x1a\x15.k8sx.v1.SearchResult0\x01B\x1cZ\x1ak8sx/api/searchpb;searchpbb\x06proto3"
+
+var (
+	file_api_searchpb_search_proto_rawDescOnce sync.Once
+	file_api_searchpb_search_proto_rawDescData []byte
+)
+
+func file_api_searchpb_search_proto_rawDescGZIP() []byte {
+	file_api_searchpb_search_proto_rawDescOnce.Do(func() {
+		file_api_searchpb_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_searchpb_search_proto_rawDesc), len(file_api_searchpb_search_proto_rawDesc)))
+	})
+	return file_api_searchpb_search_proto_rawDescData
+}
+
+var file_api_searchpb_search_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_searchpb_search_proto_goTypes = []any{
+	(*SearchRequest)(nil),         // 0: k8sx.v1.SearchRequest
+	(*Filters)(nil),               // 1: k8sx.v1.Filters
+	(*SearchResult)(nil),          // 2: k8sx.v1.SearchResult
+	(*Pod)(nil),                   // 3: k8sx.v1.Pod
+	(*Service)(nil),               // 4: k8sx.v1.Service
+	(*Port)(nil),                  // 5: k8sx.v1.Port
+	nil,                           // 6: k8sx.v1.Pod.LabelsEntry
+	nil,                           // 7: k8sx.v1.Service.SelectorEntry
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_api_searchpb_search_proto_depIdxs = []int32{
+	1, // 0: k8sx.v1.SearchRequest.filters:type_name -> k8sx.v1.Filters
+	3, // 1: k8sx.v1.SearchResult.pods:type_name -> k8sx.v1.Pod
+	4, // 2: k8sx.v1.SearchResult.services:type_name -> k8sx.v1.Service
+	6, // 3: k8sx.v1.Pod.labels:type_name -> k8sx.v1.Pod.LabelsEntry
+	8, // 4: k8sx.v1.Pod.created_at:type_name -> google.protobuf.Timestamp
+	5, // 5: k8sx.v1.Service.ports:type_name -> k8sx.v1.Port
+	7, // 6: k8sx.v1.Service.selector:type_name -> k8sx.v1.Service.SelectorEntry
+	8, // 7: k8sx.v1.Service.created_at:type_name -> google.protobuf.Timestamp
+	0, // 8: k8sx.v1.SearchService.Search:input_type -> k8sx.v1.SearchRequest
+	2, // 9: k8sx.v1.SearchService.Search:output_type -> k8sx.v1.SearchResult
+	9, // [9:10] is the sub-list for method output_type
+	8, // [8:9] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_api_searchpb_search_proto_init() }
+func file_api_searchpb_search_proto_init() {
+	if File_api_searchpb_search_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_searchpb_search_proto_rawDesc), len(file_api_searchpb_search_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_searchpb_search_proto_goTypes,
+		DependencyIndexes: file_api_searchpb_search_proto_depIdxs,
+		MessageInfos:      file_api_searchpb_search_proto_msgTypes,
+	}.Build()
+	File_api_searchpb_search_proto = out.File
+	file_api_searchpb_search_proto_goTypes = nil
+	file_api_searchpb_search_proto_depIdxs = nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var ownerCountCSVHeader = []string{"Context", "Namespace", "Owner Kind", "Owner Name", "Pods"}
+
+// ownerCount is the number of matching pods belonging to one top owner
+type ownerCount struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	OwnerKind string `json:"ownerKind"`
+	OwnerName string `json:"ownerName,omitempty"`
+	Pods      int    `json:"pods"`
+}
+
+// topOwnerResolver returns the kind and name at the top of a pod's owner chain
+type topOwnerResolver func(pod k8s.PodInfo) (string, string)
+
+// contextTopOwnerResolver resolves top owners with a client for contextName created on first use.
+// Pods of the same ReplicaSet or Job share their top owner, so each owner is only looked up once.
+func contextTopOwnerResolver(ctx context.Context, kubeconfigPath string, contextName string) topOwnerResolver {
+	var client *k8s.K8sClient
+	resolved := map[string][2]string{}
+	return func(pod k8s.PodInfo) (string, string) {
+		// Only ReplicaSets and Jobs have an owner worth an API call
+		if pod.OwnerKind == "" {
+			return k8s.NoOwnerKind, ""
+		}
+		if pod.OwnerKind != "ReplicaSet" && pod.OwnerKind != "Job" {
+			return pod.OwnerKind, pod.OwnerName
+		}
+
+		key := pod.Namespace + "/" + pod.OwnerKind + "/" + pod.OwnerName
+		if owner, ok := resolved[key]; ok {
+			return owner[0], owner[1]
+		}
+		if client == nil {
+			var err error
+			if client, err = k8s.NewK8sClient(kubeconfigPath, contextName, []string{}); err != nil {
+				return pod.OwnerKind, pod.OwnerName
+			}
+		}
+		kind, name := client.TopOwner(ctx, pod)
+		resolved[key] = [2]string{kind, name}
+		return kind, name
+	}
+}
+
+// aggregatePods counts pods by context, namespace and top owner. Counts are sorted by context
+// and namespace, with the owners with the most pods first.
+func aggregatePods(results []k8s.PodResultWithContext, resolver func(contextName string) topOwnerResolver) []ownerCount {
+	resolvers := map[string]topOwnerResolver{}
+	index := map[ownerCount]int{}
+	counts := []ownerCount{}
+	for _, result := range results {
+		resolve, ok := resolvers[result.Context]
+		if !ok {
+			resolve = resolver(result.Context)
+			resolvers[result.Context] = resolve
+		}
+
+		for _, pod := range result.Pods {
+			kind, name := resolve(pod)
+			key := ownerCount{Context: result.Context, Namespace: pod.Namespace, OwnerKind: kind, OwnerName: name}
+			i, ok := index[key]
+			if !ok {
+				i = len(counts)
+				index[key] = i
+				counts = append(counts, key)
+			}
+			counts[i].Pods++
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Context != b.Context {
+			return a.Context < b.Context
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Pods != b.Pods {
+			return a.Pods > b.Pods
+		}
+		if a.OwnerKind != b.OwnerKind {
+			return a.OwnerKind < b.OwnerKind
+		}
+		return a.OwnerName < b.OwnerName
+	})
+	return counts
+}
+
+// ipPodResults returns the pods of IP search results; services have no owner to aggregate by
+func ipPodResults(results []k8s.SearchResultWithContext) []k8s.PodResultWithContext {
+	podResults := []k8s.PodResultWithContext{}
+	for _, result := range results {
+		if len(result.Pods) > 0 {
+			podResults = append(podResults, k8s.PodResultWithContext{Context: result.Context, Server: result.Server, Namespace: result.Namespace, Pods: result.Pods})
+		}
+	}
+	return podResults
+}
+
+// displayAggregate prints the pod counts per top owner in the configured output format
+func displayAggregate(ctx context.Context, config K8sSearchConfig, results []k8s.PodResultWithContext, notFound string) error {
+	counts := aggregatePods(results, func(contextName string) topOwnerResolver {
+		return contextTopOwnerResolver(ctx, config.KubeconfigPath, contextName)
+	})
+	return writeAggregate(config.out(), config, counts, notFound)
+}
+
+// summarizeOwnerCounts counts the pods in pod counts per top owner, grouped by context and namespace
+// like the results the counts were aggregated from
+func summarizeOwnerCounts(counts []ownerCount) searchSummary {
+	summary := searchSummary{}
+	groups := map[string]bool{}
+	for _, count := range counts {
+		groups[count.Context+"/"+count.Namespace] = true
+		summary.Pods += count.Pods
+	}
+	summary.Contexts = len(groups)
+	return summary
+}
+
+// writeAggregate writes pod counts per top owner as a table or in a structured output format
+func writeAggregate(w io.Writer, config K8sSearchConfig, counts []ownerCount, notFound string) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, count := range counts {
+			if err := writeStructured(w, OutputJSONL, count); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputJSON, OutputYAML:
+		return writeResults(w, config, counts, summarizeOwnerCounts(counts))
+	case OutputCSV:
+		rows := make([][]string, 0, len(counts))
+		for _, count := range counts {
+			rows = append(rows, []string{count.Context, count.Namespace, count.OwnerKind, count.OwnerName, fmt.Sprintf("%d", count.Pods)})
+		}
+		return writeCSV(w, ownerCountCSVHeader, rows)
+	}
+
+	if len(counts) == 0 {
+		if !config.Quiet {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
+		}
+		return nil
+	}
+
+	countTable := table.Table{}
+	countTable.SetStyle(tableStyle())
+	countTable.AppendRow(table.Row{"Context", "Namespace", "Owner Kind", "Owner Name", "Pods"})
+	total := 0
+	for _, count := range counts {
+		ownerName := count.OwnerName
+		if ownerName == "" {
+			ownerName = "-"
+		}
+		countTable.AppendRow(table.Row{count.Context, count.Namespace, count.OwnerKind, ownerName, count.Pods})
+		total += count.Pods
+	}
+
+	fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Pods by top owner ==="))
+	fmt.Fprintln(w, countTable.Render())
+	fmt.Fprintf(w, "Total pods found: %d in %d owner(s)\n", total, len(counts))
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureAggregateResults returns pods of two Deployments, a StatefulSet and standalone pods in two contexts
+func fixtureAggregateResults() []k8s.PodResultWithContext {
+	return []k8s.PodResultWithContext{
+		{Context: "prod", Namespace: "web", Pods: []k8s.PodInfo{
+			{Name: "web-1", Namespace: "web", OwnerKind: "ReplicaSet", OwnerName: "web-6f7c"},
+			{Name: "api-1", Namespace: "web", OwnerKind: "ReplicaSet", OwnerName: "api-5b8d"},
+			{Name: "web-2", Namespace: "web", OwnerKind: "ReplicaSet", OwnerName: "web-6f7c"},
+			{Name: "web-3", Namespace: "web", OwnerKind: "ReplicaSet", OwnerName: "web-9a1e"},
+			{Name: "debug", Namespace: "web"},
+		}},
+		{Context: "prod", Namespace: "db", Pods: []k8s.PodInfo{
+			{Name: "db-0", Namespace: "db", OwnerKind: "StatefulSet", OwnerName: "db"},
+			{Name: "db-1", Namespace: "db", OwnerKind: "StatefulSet", OwnerName: "db"},
+		}},
+		{Context: "dev", Namespace: "web", Pods: []k8s.PodInfo{
+			{Name: "web-1", Namespace: "web", OwnerKind: "ReplicaSet", OwnerName: "web-1a2b"},
+			{Name: "scratch", Namespace: "web"},
+			{Name: "shell", Namespace: "web"},
+		}},
+	}
+}
+
+// fixtureTopOwnerResolver resolves ReplicaSets to Deployments by trimming the hash suffix
+func fixtureTopOwnerResolver(contextName string) topOwnerResolver {
+	return func(pod k8s.PodInfo) (string, string) {
+		switch pod.OwnerKind {
+		case "":
+			return k8s.NoOwnerKind, ""
+		case "ReplicaSet":
+			return "Deployment", pod.OwnerName[:len(pod.OwnerName)-5]
+		}
+		return pod.OwnerKind, pod.OwnerName
+	}
+}
+
+// TestAggregatePods tests counting pods by context, namespace and top owner
+func TestAggregatePods(t *testing.T) {
+	counts := aggregatePods(fixtureAggregateResults(), fixtureTopOwnerResolver)
+
+	assert.Equal(t, []ownerCount{
+		{Context: "dev", Namespace: "web", OwnerKind: "none", Pods: 2},
+		{Context: "dev", Namespace: "web", OwnerKind: "Deployment", OwnerName: "web", Pods: 1},
+		{Context: "prod", Namespace: "db", OwnerKind: "StatefulSet", OwnerName: "db", Pods: 2},
+		{Context: "prod", Namespace: "web", OwnerKind: "Deployment", OwnerName: "web", Pods: 3},
+		{Context: "prod", Namespace: "web", OwnerKind: "Deployment", OwnerName: "api", Pods: 1},
+		{Context: "prod", Namespace: "web", OwnerKind: "none", Pods: 1},
+	}, counts)
+
+	assert.Empty(t, aggregatePods(nil, fixtureTopOwnerResolver))
+}
+
+// TestWriteAggregateGolden tests rendering pod counts per top owner in table and structured output
+func TestWriteAggregateGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"aggregate_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"aggregate_json.golden", K8sSearchConfig{OutputFormat: OutputJSON}},
+		{"aggregate_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+	}
+
+	counts := aggregatePods(fixtureAggregateResults(), fixtureTopOwnerResolver)
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeAggregate(&buf, tt.config, counts, "not found"))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+
+	// The not-found message is kept out of the results
+	var buf, errOut bytes.Buffer
+	require.NoError(t, writeAggregate(&buf, K8sSearchConfig{Err: &errOut}, nil, "No pods found"))
+	assert.Empty(t, buf.String())
+	assert.Equal(t, "No pods found\n", errOut.String())
+}
+
+// TestValidateAggregate tests the options --aggregate cannot be combined with
+func TestValidateAggregate(t *testing.T) {
+	assert.NoError(t, validateOutput(K8sSearchConfig{Aggregate: true, OutputFormat: OutputJSON}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Aggregate: true, Interactive: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Aggregate: true, CountOnly: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Aggregate: true, OutputFormat: OutputCSV, OutputDir: "out"}))
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	k8s "k8sx/pkg"
+)
+
+// podColumn is a custom pod table column showing the value of a label or annotation
+type podColumn struct {
+	// annotation sources the value from annotations instead of labels
+	annotation bool
+	key        string
+}
+
+// podColumnSources maps the prefixes accepted by --columns to whether they name an annotation
+var podColumnSources = map[string]bool{
+	"label":      false,
+	"anno":       true,
+	"annotation": true,
+}
+
+// parsePodColumns parses --columns entries like "label:app" or "anno:build/commit". Columns
+// parsed before an invalid entry are returned along with the error.
+func parsePodColumns(specs []string) ([]podColumn, error) {
+	columns := []podColumn{}
+	for _, spec := range specs {
+		source, key, found := strings.Cut(strings.TrimSpace(spec), ":")
+		annotation, ok := podColumnSources[strings.ToLower(source)]
+		if !found || !ok || key == "" {
+			return columns, fmt.Errorf("invalid column %q (expected label:<key> or anno:<key>)", spec)
+		}
+		columns = append(columns, podColumn{annotation: annotation, key: key})
+	}
+	return columns, nil
+}
+
+// header returns the column header, the label or annotation key
+func (c podColumn) header() string {
+	return c.key
+}
+
+// value returns the pod's value for the column, blank when the key is missing
+func (c podColumn) value(pod k8s.PodInfo) string {
+	if c.annotation {
+		return pod.Annotations[c.key]
+	}
+	return pod.Labels[c.key]
+}
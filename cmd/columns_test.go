@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePodColumns tests parsing label and annotation columns
+func TestParsePodColumns(t *testing.T) {
+	columns, err := parsePodColumns([]string{"label:app", "anno:build/commit", "annotation:team"})
+	require.NoError(t, err)
+	assert.Equal(t, []podColumn{{key: "app"}, {annotation: true, key: "build/commit"}, {annotation: true, key: "team"}}, columns)
+
+	for _, spec := range []string{"app", "label:", "field:spec.nodeName"} {
+		_, err := parsePodColumns([]string{spec})
+		assert.Error(t, err, spec)
+	}
+
+	err = validateOutput(K8sSearchConfig{Columns: []string{"app"}})
+	assert.Error(t, err)
+}
+
+// TestRenderPodColumnsGolden tests label and annotation columns in the pod table, blank for missing keys
+func TestRenderPodColumnsGolden(t *testing.T) {
+	results := fixturePodResults()
+	results[0].Pods[0].Annotations = map[string]string{"build/commit": "3f2a9c1"}
+
+	var buf bytes.Buffer
+	config := K8sSearchConfig{Columns: []string{"label:app", "anno:build/commit"}}
+	require.NoError(t, testTableRenderer(config).RenderPodResults(&buf, results))
+	assertGolden(t, "pod_results_columns_table.golden", buf.Bytes())
+}
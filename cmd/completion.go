@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+)
+
+// completionTimeout bounds the API calls made to complete a flag value, so an unreachable cluster
+// does not hang the shell
+const completionTimeout = 5 * time.Second
+
+// CompleteContexts returns the contexts of the kubeconfig starting with toComplete, for completing --context
+func CompleteContexts(kubeconfigPath string, toComplete string) []string {
+	config, err := k8s.LoadKubeConfig(kubeconfigPath)
+	if err != nil {
+		return nil
+	}
+	return completeListValue(k8s.GetContexts(config), toComplete)
+}
+
+// CompleteNamespaces returns the namespaces of a context (empty = current context) for completing
+// --namespaces, listed without probing access to each of them
+func CompleteNamespaces(kubeconfigPath string, contextName string, toComplete string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	namespaces, err := k8s.ListContextNamespaces(ctx, kubeconfigPath, contextName, k8s.SearchOptions{ExecTimeout: completionTimeout})
+	if err != nil {
+		return nil
+	}
+	return completeListValue(namespaces, toComplete)
+}
+
+// completeListValue completes the last element of a comma-separated flag value: candidates starting
+// with it are returned prefixed with the elements already typed, which are not offered again
+func completeListValue(candidates []string, toComplete string) []string {
+	typed, partial := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		typed, partial = toComplete[:i+1], toComplete[i+1:]
+	}
+	done := map[string]bool{}
+	for _, value := range strings.Split(typed, ",") {
+		done[value] = true
+	}
+
+	completions := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, partial) && !done[candidate] {
+			completions = append(completions, typed+candidate)
+		}
+	}
+	return completions
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompleteContexts tests completing --context from the contexts of the kubeconfig
+func TestCompleteContexts(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+  name: prod-us
+- context:
+    cluster: test-cluster
+  name: prod-eu
+- context:
+    cluster: test-cluster
+  name: dev
+`), 0644))
+
+	assert.Equal(t, []string{"dev", "prod-eu", "prod-us"}, CompleteContexts(kubeconfigPath, ""))
+	assert.Equal(t, []string{"prod-eu", "prod-us"}, CompleteContexts(kubeconfigPath, "prod"))
+	assert.Empty(t, CompleteContexts(filepath.Join(t.TempDir(), "missing"), ""))
+}
+
+// TestCompleteListValue tests completing the last element of a comma-separated value
+func TestCompleteListValue(t *testing.T) {
+	namespaces := []string{"default", "kube-system", "web", "webhooks"}
+
+	assert.Equal(t, namespaces, completeListValue(namespaces, ""))
+	assert.Equal(t, []string{"web", "webhooks"}, completeListValue(namespaces, "we"))
+	assert.Equal(t, []string{"web,default", "web,kube-system", "web,webhooks"}, completeListValue(namespaces, "web,"))
+	assert.Equal(t, []string{"default,web,kube-system"}, completeListValue(namespaces, "default,web,k"))
+	assert.Empty(t, completeListValue(namespaces, "x"))
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	k8s "k8sx/pkg"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the config file looked up in the home directory
+const ConfigFileName = ".k8sx.yaml"
+
+// DefaultConfigPath returns the path of the config file in the home directory
+func DefaultConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ConfigFileName)
+}
+
+// configGroupsKey is the config file key holding the named context groups
+const configGroupsKey = "groups"
+
+// ConfigFile holds the contents of the config file
+type ConfigFile struct {
+	// Flags maps flag names to their default values
+	Flags map[string]interface{}
+	// Groups maps group names to the contexts searched with --group
+	Groups map[string][]string
+}
+
+// LoadConfigFile reads the config file: flag defaults keyed by flag name, plus the context groups.
+// A missing file is only an error when the path was given explicitly.
+func LoadConfigFile(path string, explicit bool) (*ConfigFile, error) {
+	file := &ConfigFile{Flags: map[string]interface{}{}, Groups: map[string][]string{}}
+	if path == "" {
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return file, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &file.Flags); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if file.Flags == nil {
+		// An empty file decodes to a nil map
+		file.Flags = map[string]interface{}{}
+	}
+
+	if groups, ok := file.Flags[configGroupsKey]; ok {
+		delete(file.Flags, configGroupsKey)
+		if file.Groups, err = parseGroups(groups); err != nil {
+			return nil, fmt.Errorf("invalid %s in config file %s: %w", configGroupsKey, path, err)
+		}
+	}
+	return file, nil
+}
+
+// parseGroups converts the decoded groups section to lists of context names
+func parseGroups(value interface{}) (map[string][]string, error) {
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a map of group names to context lists")
+	}
+
+	groups := map[string][]string{}
+	for name, contexts := range entries {
+		list, ok := contexts.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("group %q must be a non-empty list of contexts", name)
+		}
+		for _, item := range list {
+			contextName, ok := item.(string)
+			if !ok || contextName == "" {
+				return nil, fmt.Errorf("group %q contains an invalid context name", name)
+			}
+			groups[name] = append(groups[name], contextName)
+		}
+	}
+	return groups, nil
+}
+
+// GroupContexts returns the contexts of a named context group
+func (f *ConfigFile) GroupContexts(name string) ([]string, error) {
+	contexts, ok := f.Groups[name]
+	if !ok {
+		names := make([]string, 0, len(f.Groups))
+		for group := range f.Groups {
+			names = append(names, group)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%w: unknown context group %q (no groups are defined in the config file)", k8s.ErrInvalidOptions, name)
+		}
+		return nil, fmt.Errorf("%w: unknown context group %q (defined: %s)", k8s.ErrInvalidOptions, name, strings.Join(names, ", "))
+	}
+	return contexts, nil
+}
+
+// LoadNamespacesFile reads the namespaces listed in a file, one per line. Whitespace is trimmed,
+// and blank lines and comments starting with # are ignored.
+func LoadNamespacesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read namespaces file: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	namespaces := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if namespace := strings.TrimSpace(line); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("%w: namespaces file %s lists no namespaces", k8s.ErrInvalidOptions, path)
+	}
+	return namespaces, nil
+}
+
+// MergeNamespaces appends the namespaces from extra missing from namespaces, keeping their order
+func MergeNamespaces(namespaces []string, extra []string) []string {
+	seen := map[string]bool{}
+	merged := []string{}
+	for _, namespace := range append(append([]string{}, namespaces...), extra...) {
+		if !seen[namespace] {
+			seen[namespace] = true
+			merged = append(merged, namespace)
+		}
+	}
+	return merged
+}
+
+// ApplyFlagDefaults fills the flags that were not set on the command line, first from the
+// environment variables in env (flag name -> variable name) and then from the config file values.
+// Flags set this way are not marked as changed, so they still behave as defaults.
+func ApplyFlagDefaults(flags *pflag.FlagSet, env map[string]string, values map[string]interface{}) error {
+	// Reject unknown keys so typos in the config file do not go unnoticed
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if flags.Lookup(key) == nil {
+			return fmt.Errorf("unknown flag %q in config file", key)
+		}
+	}
+
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+
+		if name, ok := env[flag.Name]; ok {
+			if value := os.Getenv(name); value != "" {
+				if setErr := setFlagValue(flag, strings.Split(value, ",")); setErr != nil {
+					err = fmt.Errorf("invalid value for %s: %w", name, setErr)
+				}
+				return
+			}
+		}
+
+		if value, ok := values[flag.Name]; ok {
+			if setErr := setFlagValue(flag, configValues(value)); setErr != nil {
+				err = fmt.Errorf("invalid value for %q in config file: %w", flag.Name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// setFlagValue sets a flag from one or more values, replacing the whole list for slice flags
+// and joining the values back for scalar flags
+func setFlagValue(flag *pflag.Flag, values []string) error {
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		items := []string{}
+		for _, value := range values {
+			if value != "" {
+				items = append(items, value)
+			}
+		}
+		return slice.Replace(items)
+	}
+	return flag.Value.Set(strings.Join(values, ","))
+}
+
+// configValues converts a decoded YAML value to the strings a flag is set from
+func configValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			values = append(values, configValues(item)...)
+		}
+		return values
+	case float64:
+		// YAML numbers are decoded as floats, which must not be printed in exponent form
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case nil:
+		return []string{""}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFlagSet returns a flag set with the kinds of flags k8sx uses
+func testFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("kubeconfig", "/default/config", "")
+	flags.StringSlice("namespaces", nil, "")
+	flags.StringArray("annotation", nil, "")
+	flags.String("context", "", "")
+	flags.Int("retries", 2, "")
+	flags.Duration("precheck-timeout", 5*time.Second, "")
+	flags.Bool("no-dedup", false, "")
+	return flags
+}
+
+// TestLoadConfigFile tests reading, parsing and missing config files
+func TestLoadConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, ConfigFileName)
+	require.NoError(t, os.WriteFile(path, []byte("kubeconfig: /tmp/kubeconfig\nnamespaces: [default, web]\n"), 0644))
+
+	file, err := LoadConfigFile(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/kubeconfig", file.Flags["kubeconfig"])
+	assert.Equal(t, []interface{}{"default", "web"}, file.Flags["namespaces"])
+
+	// A missing default config file is fine, a missing explicit one is not
+	file, err = LoadConfigFile(filepath.Join(tempDir, "missing.yaml"), false)
+	require.NoError(t, err)
+	assert.Empty(t, file.Flags)
+
+	_, err = LoadConfigFile(filepath.Join(tempDir, "missing.yaml"), true)
+	assert.Error(t, err)
+
+	// Empty files decode to no values
+	empty := filepath.Join(tempDir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, []byte(""), 0644))
+	file, err = LoadConfigFile(empty, true)
+	require.NoError(t, err)
+	assert.Empty(t, file.Flags)
+	assert.Empty(t, file.Groups)
+
+	invalid := filepath.Join(tempDir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("namespaces: [default\n"), 0644))
+	_, err = LoadConfigFile(invalid, true)
+	assert.Error(t, err)
+}
+
+// TestConfigGroups tests reading named context groups from the config file
+func TestConfigGroups(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, ConfigFileName)
+	content := "output: json\ngroups:\n  prod: [prod-us, prod-eu]\n  dev: [dev]\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	file, err := LoadConfigFile(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"output": "json"}, file.Flags)
+
+	contexts, err := file.GroupContexts("prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod-us", "prod-eu"}, contexts)
+
+	_, err = file.GroupContexts("staging")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+	assert.Contains(t, err.Error(), "defined: dev, prod")
+
+	// Groups must be non-empty lists of context names
+	for _, invalid := range []string{"groups: [prod]\n", "groups:\n  prod: []\n", "groups:\n  prod: prod-us\n"} {
+		require.NoError(t, os.WriteFile(path, []byte(invalid), 0644))
+		_, err = LoadConfigFile(path, true)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestLoadNamespacesFile tests reading namespaces one per line and merging them with inline namespaces
+func TestLoadNamespacesFile(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "ns.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# payments team\n  payments-prod  \n\npayments-staging # shared\r\nweb\n"), 0644))
+
+	namespaces, err := LoadNamespacesFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments-prod", "payments-staging", "web"}, namespaces)
+	assert.Equal(t, []string{"web", "default", "payments-prod", "payments-staging"}, MergeNamespaces([]string{"web", "default"}, namespaces))
+
+	// Missing files and files without namespaces are invalid options
+	_, err = LoadNamespacesFile(filepath.Join(tempDir, "missing.txt"))
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+
+	comments := filepath.Join(tempDir, "comments.txt")
+	require.NoError(t, os.WriteFile(comments, []byte("# nothing yet\n\n"), 0644))
+	_, err = LoadNamespacesFile(comments)
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+}
+
+// TestApplyFlagDefaults tests the precedence flag > environment variable > config file > built-in default
+func TestApplyFlagDefaults(t *testing.T) {
+	env := map[string]string{
+		"kubeconfig": "TEST_K8SX_KUBECONFIG",
+		"namespaces": "TEST_K8SX_NAMESPACES",
+		"context":    "TEST_K8SX_CONTEXT",
+	}
+	t.Setenv("TEST_K8SX_NAMESPACES", "default, web")
+	t.Setenv("TEST_K8SX_CONTEXT", "from-env")
+
+	values := map[string]interface{}{
+		"namespaces":       []interface{}{"kube-system"},
+		"context":          "from-config",
+		"kubeconfig":       "/config/kubeconfig",
+		"retries":          float64(5),
+		"precheck-timeout": "10s",
+		"annotation":       []interface{}{"team=a,b", "owner"},
+	}
+
+	flags := testFlagSet()
+	require.NoError(t, flags.Parse([]string{"--context", "from-flag"}))
+	require.NoError(t, ApplyFlagDefaults(flags, env, values))
+
+	context, _ := flags.GetString("context")
+	assert.Equal(t, "from-flag", context)
+
+	namespaces, _ := flags.GetStringSlice("namespaces")
+	assert.Equal(t, []string{"default", "web"}, namespaces)
+
+	kubeconfig, _ := flags.GetString("kubeconfig")
+	assert.Equal(t, "/config/kubeconfig", kubeconfig)
+
+	retries, _ := flags.GetInt("retries")
+	assert.Equal(t, 5, retries)
+
+	timeout, _ := flags.GetDuration("precheck-timeout")
+	assert.Equal(t, 10*time.Second, timeout)
+
+	// List items are kept whole, even when they contain commas
+	annotations, _ := flags.GetStringArray("annotation")
+	assert.Equal(t, []string{"team=a,b", "owner"}, annotations)
+
+	noDedup, _ := flags.GetBool("no-dedup")
+	assert.False(t, noDedup)
+
+	// Values applied from the environment or config file are still defaults
+	assert.False(t, flags.Lookup("namespaces").Changed)
+	assert.False(t, flags.Lookup("kubeconfig").Changed)
+}
+
+// TestApplyFlagDefaultsErrors tests unknown keys and invalid values in the config file
+func TestApplyFlagDefaultsErrors(t *testing.T) {
+	err := ApplyFlagDefaults(testFlagSet(), nil, map[string]interface{}{"namespace": "web"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "namespace")
+
+	err = ApplyFlagDefaults(testFlagSet(), nil, map[string]interface{}{"retries": "many"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "retries")
+}
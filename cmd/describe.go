@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// podDescription is the structured output of the describe command
+type podDescription struct {
+	Context string          `json:"context"`
+	Owner   string          `json:"owner,omitempty"`
+	Pod     k8s.PodInfo     `json:"pod"`
+	Events  []k8s.EventInfo `json:"events"`
+}
+
+// DescribeK8sPod prints a condensed description of a single pod including its recent events
+func DescribeK8sPod(config K8sSearchConfig, namespace string, name string, eventLimit int) error {
+	if name == "" {
+		return fmt.Errorf("%w: pod name cannot be empty", k8s.ErrInvalidQuery)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+	if config.OutputFormat == OutputCSV || config.OutputFormat == OutputGraph || config.OutputFormat == OutputTemplate {
+		return fmt.Errorf("%w: %s output is not supported by describe", k8s.ErrInvalidOptions, config.OutputFormat)
+	}
+
+	// Create K8s client
+	client, err := k8s.NewK8sClient(config.KubeconfigPath, config.ContextName, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to create K8s client: %w", err)
+	}
+	client.Options = config.searchOptions()
+
+	// Fall back to the context's namespace like kubectl does
+	if namespace == "" {
+		namespace = "default"
+		if kubeContext, ok := client.Config.Contexts[client.ContextName]; ok && kubeContext.Namespace != "" {
+			namespace = kubeContext.Namespace
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pod, err := client.GetPod(ctx, namespace, name)
+	if err != nil {
+		return fmt.Errorf("failed to get pod: %w", err)
+	}
+
+	events, err := client.GetPodEvents(ctx, namespace, name, eventLimit)
+	if err != nil {
+		// Events are optional, the pod is still described without them
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("Could not list events: %v", err))
+		events = []k8s.EventInfo{}
+	}
+
+	owner := clientOwnerResolver(ctx, client)
+	if !config.isTableOutput() {
+		return writeStructured(config.out(), config.OutputFormat, podDescription{
+			Context: client.ContextName,
+			Owner:   owner(pod),
+			Pod:     pod,
+			Events:  events,
+		})
+	}
+
+	fmt.Fprintln(config.out(), text.FgGreen.Sprintf("\n=== Pod %s/%s in Context: %s ===", pod.Namespace, pod.Name, client.ContextName))
+	fmt.Fprintln(config.out(), renderPodDetail(client.ContextName, pod, owner))
+
+	fmt.Fprintln(config.out(), text.FgGreen.Sprintf("\n=== Events ==="))
+	if len(events) == 0 {
+		fmt.Fprintln(config.out(), text.FgYellow.Sprintf("No events found"))
+		return nil
+	}
+	fmt.Fprintln(config.out(), renderEventTable(events))
+
+	return nil
+}
+
+// renderPodDetail renders all details of a pod as a two column table
+func renderPodDetail(contextName string, pod k8s.PodInfo, owner ownerResolver) string {
+	rows := []table.Row{
+		{"Context", contextName},
+		{"Namespace", pod.Namespace},
+		{"Pod Name", pod.Name},
+		{"UID", pod.UID},
+		{"Phase", pod.Phase},
+		{"QoS Class", pod.QOSClass},
+		{"Node", pod.NodeName},
+		{"Service Account", pod.ServiceAccount},
+		{"Pod IP", formatIPs(pod.PodIP, pod.PodIPs, ", ")},
+		{"Host IP", formatIPs(pod.HostIP, pod.HostIPs, ", ")},
+		{"Owner", formatOwner(pod.OwnerKind, owner(pod))},
+		{"Age", formatAge(pod)},
+		{"Labels", formatMap(pod.Labels)},
+		{"Annotations", formatMap(pod.Annotations)},
+	}
+	if pod.MatchedOn != "" {
+		rows = append(rows, table.Row{"Matched On", formatMatchedOn(pod.MatchedOn)})
+	}
+	if len(pod.Managers) > 0 {
+		rows = append(rows, table.Row{"Managers", strings.Join(pod.Managers, ", ")})
+	}
+	if len(pod.Containers) > 0 {
+		rows = append(rows, table.Row{"Containers", renderContainerTable(pod.Containers)})
+	}
+	return renderDetailTable(rows)
+}
+
+// renderEventTable renders pod events, highlighting warnings
+func renderEventTable(events []k8s.EventInfo) string {
+	eventTable := table.Table{}
+	eventTable.SetStyle(tableStyle())
+	eventTable.AppendRow(table.Row{"Type", "Reason", "Age", "Count", "Message"})
+
+	for _, event := range events {
+		eventType := event.Type
+		if eventType == "Warning" {
+			eventType = text.FgYellow.Sprint(eventType)
+		}
+
+		age := "<unknown>"
+		if !event.LastTimestamp.IsZero() {
+			age = duration.HumanDuration(now().Sub(event.LastTimestamp))
+		}
+
+		eventTable.AppendRow(table.Row{eventType, event.Reason, age, event.Count, event.Message})
+	}
+	return eventTable.Render()
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// ErrDetectDuplicatesRequiresIP is returned when --detect-duplicates is set for a query that is not an IP or CIDR range
+var ErrDetectDuplicatesRequiresIP = fmt.Errorf("%w: --detect-duplicates only applies to IP searches", k8s.ErrInvalidOptions)
+
+// reportDuplicateIPs surfaces the addresses an IP search found assigned in more than one cluster once
+// the results are written: tables get a section after the results and other structured formats than
+// json, whose envelope carries them, a notice on stderr
+func reportDuplicateIPs(config K8sSearchConfig, duplicates []k8s.DuplicateIP) {
+	if config.OutputFormat == OutputJSON {
+		return
+	}
+
+	w := config.out()
+	if !config.isTableOutput() {
+		if w = config.notices(); w == nil || len(duplicates) == 0 {
+			return
+		}
+	}
+	writeDuplicateIPs(w, config, duplicates)
+}
+
+// writeDuplicateIPs writes the duplicate IPs as a table, or a line saying there are none
+func writeDuplicateIPs(w io.Writer, config K8sSearchConfig, duplicates []k8s.DuplicateIP) {
+	if len(duplicates) == 0 {
+		if config.isVerbose() {
+			fmt.Fprintln(w, text.FgGreen.Sprintf("\nNo IP is assigned in more than one cluster"))
+		}
+		return
+	}
+
+	duplicateTable := table.Table{}
+	duplicateTable.SetStyle(tableStyle())
+	duplicateTable.AppendRow(table.Row{"IP", "Context", "Namespace", "Kind", "Name"})
+	for _, duplicate := range duplicates {
+		for _, holder := range duplicate.Holders {
+			duplicateTable.AppendRow(table.Row{duplicate.IP, contextLabel(holder.Context, holder.Server), holder.Namespace, holder.Kind, holder.Name})
+		}
+	}
+
+	fmt.Fprintln(w, text.FgRed.Sprintf("\n=== Duplicate IPs: %d IP(s) assigned in more than one cluster ===", len(duplicates)))
+	fmt.Fprintln(w, duplicateTable.Render())
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureDuplicateIPs returns a pod IP assigned in two clusters
+func fixtureDuplicateIPs() []k8s.DuplicateIP {
+	return []k8s.DuplicateIP{{IP: "10.0.0.5", Holders: []k8s.IPHolder{
+		{Context: "eu", Server: "https://eu.example.com:6443", Namespace: "web", Kind: "Pod", Name: "web-1"},
+		{Context: "us", Server: "https://us.example.com:6443", Namespace: "api", Kind: "Pod", Name: "api-1"},
+	}}}
+}
+
+// TestDuplicateIPsGolden tests the duplicate IPs section written after table results
+func TestDuplicateIPsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	reportDuplicateIPs(K8sSearchConfig{Out: &buf}, fixtureDuplicateIPs())
+	assertGolden(t, "duplicate_ips_table.golden", buf.Bytes())
+
+	buf.Reset()
+	reportDuplicateIPs(K8sSearchConfig{Out: &buf}, nil)
+	assert.Contains(t, buf.String(), "No IP is assigned in more than one cluster")
+}
+
+// TestDuplicateIPsEnvelope tests that json output carries the duplicate IPs in the envelope
+func TestDuplicateIPsEnvelope(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{OutputFormat: OutputJSON, Out: &out}
+	config.startReport()
+	config.report.setDuplicates(fixtureDuplicateIPs())
+	require.NoError(t, writeIPResults(&out, config, fixtureIPResults()))
+	reportDuplicateIPs(config, fixtureDuplicateIPs())
+
+	var envelope struct {
+		Duplicates []k8s.DuplicateIP `json:"duplicates"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+	assert.Equal(t, fixtureDuplicateIPs(), envelope.Duplicates)
+
+	assert.Error(t, validateOutput(K8sSearchConfig{DetectDuplicates: true, ContextName: "prod"}))
+}
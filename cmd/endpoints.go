@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var endpointCSVHeader = []string{"Context", "Namespace", "Service", "Address", "Ready", "Ports", "Node", "Target Kind", "Target Name", "Source"}
+
+// ErrEndpointKindRequiresIP is returned for --kind endpoint with a query that is not an IP address or CIDR range
+var ErrEndpointKindRequiresIP = fmt.Errorf("%w: --kind endpoint only applies to IP searches", k8s.ErrInvalidOptions)
+
+// IsEndpointKind reports whether --kind names endpoints, which makes IP searches match endpoint addresses
+func IsEndpointKind(kind string) bool {
+	return k8s.IsEndpointKind(kind)
+}
+
+// SearchK8sEndpointsAllContexts searches the endpoint addresses of services for an IP address or CIDR range
+// across all contexts and all (or specified) namespaces, e.g. to find the service of an IP from a connection error
+func SearchK8sEndpointsAllContexts(config K8sSearchConfig, ip string) error {
+	if err := validateIPQuery(config, ip); err != nil {
+		return err
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	namespaces := allContextsNamespaces(&config, "endpoint IP", ip)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	results, err := k8s.SearchEndpointsAllContexts(ctx, config.KubeconfigPath, ip, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupEndpointResults(results)
+	}
+
+	return displayEndpointResults(ctx, config, results, fmt.Sprintf("No endpoints found for IP: %s across all contexts and namespaces", ip))
+}
+
+// displayEndpointResults prints endpoint search results in the configured output format
+func displayEndpointResults(ctx context.Context, config K8sSearchConfig, results []k8s.EndpointResultWithContext, notFound string) error {
+	defer writeTruncationNotice(config, summarizeEndpointResults(results).total())
+	defer writeNoAccessNotice(config, summarizeEndpointResults(results).total())
+	defer writeSummaryJSON(config, summarizeEndpointResults(results))
+	defer config.history.setMatches(summarizeEndpointResults(results))
+
+	if config.CountOnly {
+		return writeSummary(config, summarizeEndpointResults(results))
+	}
+
+	// A custom renderer takes over all output, including empty results
+	if config.Renderer == nil && config.isTableOutput() && len(results) == 0 {
+		if !config.Quiet {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
+		}
+		return nil
+	}
+	return config.renderer(ctx).RenderEndpointResults(config.out(), results)
+}
+
+// writeEndpointResults writes endpoint search results in a non-table output format
+func writeEndpointResults(w io.Writer, config K8sSearchConfig, results []k8s.EndpointResultWithContext) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, result := range results {
+			for i := range result.Endpoints {
+				record := jsonlRecord{Kind: "Endpoint", Context: result.Context, Server: result.Server, Namespace: result.Namespace, Endpoint: &result.Endpoints[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		rows := [][]string{}
+		for _, result := range results {
+			for _, endpoint := range result.Endpoints {
+				rows = append(rows, []string{
+					result.Context,
+					result.Namespace,
+					endpoint.Service,
+					endpoint.Address,
+					fmt.Sprintf("%t", endpoint.Ready),
+					strings.Join(endpoint.Ports, ","),
+					endpoint.NodeName,
+					endpoint.TargetKind,
+					endpoint.TargetName,
+					endpoint.Source,
+				})
+			}
+		}
+
+		if config.OutputDir != "" {
+			return writeCSVFile(config.OutputDir, "endpoints.csv", endpointCSVHeader, rows)
+		}
+		return writeCSV(w, endpointCSVHeader, rows)
+	}
+	return writeResults(w, config, results, summarizeEndpointResults(results))
+}
+
+// renderEndpointTable renders endpoints as a table, highlighting addresses that are not ready
+func renderEndpointTable(endpoints []k8s.EndpointInfo) string {
+	endpointTable := table.Table{}
+	endpointTable.SetStyle(tableStyle())
+	endpointTable.AppendRow(table.Row{"Service", "Address", "Ready", "Ports", "Node", "Target", "Source"})
+
+	for _, endpoint := range endpoints {
+		ready := "true"
+		if !endpoint.Ready {
+			ready = text.FgYellow.Sprint("false")
+		}
+		target := ""
+		if endpoint.TargetName != "" {
+			target = fmt.Sprintf("%s/%s", endpoint.TargetKind, endpoint.TargetName)
+		}
+		endpointTable.AppendRow(table.Row{
+			endpoint.Service,
+			endpoint.Address,
+			ready,
+			strings.Join(endpoint.Ports, "\n"),
+			endpoint.NodeName,
+			target,
+			endpoint.Source,
+		})
+	}
+	return endpointTable.Render()
+}
+
+// summarizeEndpointResults counts endpoints in endpoint search results
+func summarizeEndpointResults(results []k8s.EndpointResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results), Endpoints: new(int)}
+	for _, result := range results {
+		*summary.Endpoints += len(result.Endpoints)
+	}
+	return summary
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureEndpointResults returns the endpoints of a pod IP, one of them not ready
+func fixtureEndpointResults() []k8s.EndpointResultWithContext {
+	return []k8s.EndpointResultWithContext{
+		{Context: "prod", Server: "https://prod.example.com:6443", Namespace: "default", Endpoints: []k8s.EndpointInfo{
+			{Service: "nginx", Namespace: "default", Address: "10.0.0.1", Ready: true, Ports: []string{"80/TCP (http)", "443/TCP (https)"}, NodeName: "node-1", TargetKind: "Pod", TargetName: "nginx-7d9c-abcde", Source: "nginx-x7k2p"},
+			{Service: "nginx-canary", Namespace: "default", Address: "10.0.0.1", Ready: false, Ports: []string{"80/TCP (http)"}, NodeName: "node-1", TargetKind: "Pod", TargetName: "nginx-7d9c-abcde", Source: "nginx-canary"},
+		}},
+	}
+}
+
+// TestRenderEndpointResultsGolden tests endpoint search result rendering in table and structured output
+func TestRenderEndpointResultsGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"endpoint_results_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"endpoint_results_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+		{"endpoint_results_jsonl.golden", K8sSearchConfig{OutputFormat: OutputJSONL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			renderer := Renderer(structuredRenderer{config: tt.config})
+			if tt.config.isTableOutput() {
+				renderer = testTableRenderer(tt.config)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.RenderEndpointResults(&buf, fixtureEndpointResults()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestEndpointSummary tests that endpoint counts replace the pod count in the summary
+func TestEndpointSummary(t *testing.T) {
+	summary := summarizeEndpointResults(fixtureEndpointResults())
+	assert.Equal(t, 2, summary.total())
+
+	var buf bytes.Buffer
+	printSummary(&buf, summary)
+	assert.Contains(t, buf.String(), "Total endpoints found: 2")
+	assert.NotContains(t, buf.String(), "pods")
+}
+
+// TestSearchEndpointsInvalidIP tests that endpoint searches only take IP addresses and CIDR ranges
+func TestSearchEndpointsInvalidIP(t *testing.T) {
+	err := SearchK8sEndpointsAllContexts(K8sSearchConfig{Kind: "endpoint"}, "nginx")
+	require.Error(t, err)
+	assert.Equal(t, ExitInvalidInput, ExitCode(err))
+	assert.True(t, IsEndpointKind("EndpointSlices"))
+	assert.False(t, IsEndpointKind("service"))
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"io"
+
+	k8s "k8sx/pkg"
+)
+
+// searchProblem describes a context or namespace a search skipped or failed to search
+type searchProblem struct {
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Error     string `json:"error"`
+}
+
+// searchReport collects the contexts and namespaces skipped by a search and the searches that failed,
+// reported next to the results in json output and counted by --summary-json. The search callbacks
+// filling it are serialized.
+type searchReport struct {
+	skipped []searchProblem
+	errors  []searchProblem
+	// duplicates are the IPs --detect-duplicates found assigned in more than one cluster
+	duplicates []k8s.DuplicateIP
+	// written is set once the envelope was written, so a failure afterwards does not write another one
+	written bool
+}
+
+// startReport starts collecting skipped contexts and failures for the json envelope or the --summary-json
+// line. It returns nil when neither is written and the report of an enclosing search, e.g. before a fallback
+// to a name search, when one is already collecting.
+func (c *K8sSearchConfig) startReport() *searchReport {
+	if c.OutputFormat != OutputJSON && !c.writesSummaryJSON() {
+		return nil
+	}
+	if c.report == nil {
+		c.report = &searchReport{}
+	}
+	return c.report
+}
+
+// skip records a skipped context or namespace; a nil report ignores it
+func (r *searchReport) skip(contextName, namespace string, err error) {
+	if r != nil {
+		r.skipped = appendProblem(r.skipped, searchProblem{Context: contextName, Namespace: namespace, Error: err.Error()})
+	}
+}
+
+// fail records a failed search of a context or namespace, or of the whole search without either; a nil report ignores it
+func (r *searchReport) fail(contextName, namespace string, err error) {
+	if r != nil {
+		r.errors = appendProblem(r.errors, searchProblem{Context: contextName, Namespace: namespace, Error: err.Error()})
+	}
+}
+
+// setDuplicates records the duplicate IPs found in the results; a nil report ignores them
+func (r *searchReport) setDuplicates(duplicates []k8s.DuplicateIP) {
+	if r != nil {
+		r.duplicates = duplicates
+	}
+}
+
+// appendProblem appends problem unless it was recorded already, e.g. by the search a fallback search followed
+func appendProblem(problems []searchProblem, problem searchProblem) []searchProblem {
+	for _, recorded := range problems {
+		if recorded == problem {
+			return problems
+		}
+	}
+	return append(problems, problem)
+}
+
+// jsonEnvelope is the top-level object of json output, carrying the skipped contexts and namespaces
+// and the failures along with the results so consumers get parseable output even on partial failure
+type jsonEnvelope struct {
+	Results interface{}     `json:"results"`
+	Skipped []searchProblem `json:"skipped"`
+	Errors  []searchProblem `json:"errors"`
+	Summary searchSummary   `json:"summary"`
+	// Duplicates is only set by IP searches with --detect-duplicates
+	Duplicates []k8s.DuplicateIP `json:"duplicates,omitempty"`
+}
+
+// writeResults writes search results as json, yaml or jsonl; json results are wrapped in the envelope
+func writeResults(w io.Writer, config K8sSearchConfig, results interface{}, summary searchSummary) error {
+	if config.OutputFormat != OutputJSON {
+		return writeStructured(w, config.OutputFormat, results)
+	}
+	return writeEnvelope(w, config.report, results, summary)
+}
+
+// writeEnvelope writes results with the problems collected by report (nil = none) as a json envelope
+func writeEnvelope(w io.Writer, report *searchReport, results interface{}, summary searchSummary) error {
+	envelope := jsonEnvelope{Results: results, Skipped: []searchProblem{}, Errors: []searchProblem{}, Summary: summary}
+	if report != nil {
+		report.written = true
+		envelope.Skipped = append(envelope.Skipped, report.skipped...)
+		envelope.Errors = append(envelope.Errors, report.errors...)
+		envelope.Duplicates = report.duplicates
+	}
+	return writeStructured(w, OutputJSON, envelope)
+}
+
+// WithJSONErrors runs search and, when it fails before writing its json output, writes an envelope
+// without results describing the error instead, so json consumers always get parseable output.
+// The error is still returned, e.g. for the exit code. Other output formats run search unchanged.
+func WithJSONErrors(config K8sSearchConfig, search func(config K8sSearchConfig) error) error {
+	report := config.startReport()
+	err := search(config)
+	if err != nil && config.OutputFormat == OutputJSON && !report.written {
+		report.fail("", "", err)
+		if writeErr := writeEnvelope(config.out(), report, []interface{}{}, searchSummary{}); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
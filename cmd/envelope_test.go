@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodedEnvelope is the json envelope as read by consumers
+type decodedEnvelope struct {
+	Results []k8s.SearchResultWithContext `json:"results"`
+	Skipped []searchProblem               `json:"skipped"`
+	Errors  []searchProblem               `json:"errors"`
+	Summary searchSummary                 `json:"summary"`
+}
+
+// TestJSONEnvelope tests that json results carry the skipped contexts and failures collected during the search
+func TestJSONEnvelope(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{OutputFormat: OutputJSON, Out: &out}
+	config.startReport()
+
+	opts := config.searchOptions()
+	opts.OnContextSkipped("staging", errors.New("cluster unreachable"))
+	opts.OnContextSkipped("staging", errors.New("cluster unreachable"))
+	opts.OnNamespaceSkipped("prod", "web", errors.New("too many requests"))
+	opts.OnSearchFailed("prod", "db", errors.New("internal error"))
+
+	require.NoError(t, writeIPResults(&out, config, fixtureIPResults()))
+
+	var envelope decodedEnvelope
+	require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+	assert.Len(t, envelope.Results, 2)
+	assert.Equal(t, []searchProblem{
+		{Context: "staging", Error: "cluster unreachable"},
+		{Context: "prod", Namespace: "web", Error: "too many requests"},
+	}, envelope.Skipped)
+	assert.Equal(t, []searchProblem{{Context: "prod", Namespace: "db", Error: "internal error"}}, envelope.Errors)
+	assert.Equal(t, 2, envelope.Summary.Pods)
+	require.NotNil(t, envelope.Summary.Services)
+	assert.Equal(t, 3, *envelope.Summary.Services)
+
+	// Other structured formats keep the bare results
+	out.Reset()
+	require.NoError(t, writeIPResults(&out, K8sSearchConfig{OutputFormat: OutputYAML}, fixtureIPResults()))
+	assert.NotContains(t, out.String(), "skipped")
+}
+
+// TestWithJSONErrors tests that a failed json search still writes a parseable envelope, once
+func TestWithJSONErrors(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{OutputFormat: OutputJSON, Out: &out}
+	err := WithJSONErrors(config, func(config K8sSearchConfig) error {
+		return errors.New("failed to load kubeconfig")
+	})
+	require.EqualError(t, err, "failed to load kubeconfig")
+
+	var envelope decodedEnvelope
+	require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+	assert.Empty(t, envelope.Results)
+	assert.NotNil(t, envelope.Skipped)
+	assert.Equal(t, []searchProblem{{Error: "failed to load kubeconfig"}}, envelope.Errors)
+
+	// A failure after the results were written does not add a second document
+	out.Reset()
+	err = WithJSONErrors(config, func(config K8sSearchConfig) error {
+		if err := writeIPResults(config.out(), config, fixtureIPResults()); err != nil {
+			return err
+		}
+		return errors.New("late failure")
+	})
+	require.Error(t, err)
+	require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+
+	// Other output formats leave the error to the caller
+	out.Reset()
+	err = WithJSONErrors(K8sSearchConfig{OutputFormat: OutputYAML, Out: &out}, func(config K8sSearchConfig) error {
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+	assert.Empty(t, out.String())
+}
+
+// TestSummaryJSON tests the compact json summary line printed after the result tables
+func TestSummaryJSON(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{SummaryJSON: true, Out: &out, Err: &bytes.Buffer{}}
+	config.startReport()
+
+	opts := config.searchOptions()
+	opts.OnContextSkipped("staging", errors.New("cluster unreachable"))
+	opts.OnSearchFailed("prod", "db", errors.New("internal error"))
+
+	writeSummaryJSON(config, summarizeIPResults(fixtureIPResults()))
+	assert.Equal(t, `{"contexts":2,"pods":2,"services":3,"skipped":1,"errors":1}`+"\n", out.String())
+
+	// A failed table search writes no envelope
+	out.Reset()
+	err := WithJSONErrors(config, func(config K8sSearchConfig) error {
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+	assert.Empty(t, out.String())
+
+	assert.Error(t, validateOutput(K8sSearchConfig{SummaryJSON: true, OutputFormat: OutputJSON}))
+}
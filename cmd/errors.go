@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Exit codes for the kinds of errors a command can fail with
+const (
+	ExitError        = 1
+	ExitInvalidInput = 2
+	ExitKubeconfig   = 3
+	ExitNoAccess     = 4
+	ExitUnreachable  = 5
+)
+
+// ExitCode returns the process exit code for an error returned by a command (0 for nil)
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, k8s.ErrInvalidIP), errors.Is(err, k8s.ErrInvalidQuery), errors.Is(err, k8s.ErrInvalidOptions):
+		return ExitInvalidInput
+	case errors.Is(err, k8s.ErrKubeconfig):
+		return ExitKubeconfig
+	case errors.Is(err, k8s.ErrNoAccess), k8s.IsPermissionError(err):
+		return ExitNoAccess
+	case errors.Is(err, k8s.ErrUnreachable):
+		return ExitUnreachable
+	default:
+		return ExitError
+	}
+}
+
+// PrintError prints an error returned by a command in red
+func PrintError(w io.Writer, err error) {
+	fmt.Fprintln(w, text.FgRed.Sprintf("Error: %v", err))
+}
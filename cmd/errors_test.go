@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestExitCode tests mapping command errors to exit codes
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), ExitError},
+		{fmt.Errorf("%w: 10.0.0", k8s.ErrInvalidIP), ExitInvalidInput},
+		{fmt.Errorf("%w: name cannot be empty", k8s.ErrInvalidQuery), ExitInvalidInput},
+		{fmt.Errorf("invalid search options: %w", k8s.SearchOptions{OwnerKinds: []string{" "}}.Validate()), ExitInvalidInput},
+		{fmt.Errorf("failed to create K8s client: %w", k8s.ErrKubeconfig), ExitKubeconfig},
+		{fmt.Errorf("failed to search: %w", k8s.ErrNoAccess), ExitNoAccess},
+		{apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", nil), ExitNoAccess},
+		{k8s.ErrUnreachable, ExitUnreachable},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ExitCode(tt.err), "%v", tt.err)
+	}
+}
+
+// TestSearchErrorsNotPrinted tests that failing searches return errors without writing them
+func TestSearchErrorsNotPrinted(t *testing.T) {
+	var buf bytes.Buffer
+	config := K8sSearchConfig{Out: &buf}
+
+	err := SearchK8sByIP(config, "10.0.0")
+	assert.ErrorIs(t, err, k8s.ErrInvalidIP)
+
+	err = SearchK8sByNameAllContexts(config, "")
+	assert.ErrorIs(t, err, k8s.ErrInvalidQuery)
+
+	config.OutputFormat = "xml"
+	err = SearchK8sByName(config, "nginx")
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	k8s "k8sx/pkg"
+)
+
+// backendResolver returns the pods behind the endpoints of a service
+type backendResolver func(svc k8s.ServiceInfo) ([]k8s.EndpointPod, error)
+
+// contextBackendResolver resolves service backends with a client for contextName created on first use
+func contextBackendResolver(ctx context.Context, kubeconfigPath string, contextName string) backendResolver {
+	var client *k8s.K8sClient
+	return func(svc k8s.ServiceInfo) ([]k8s.EndpointPod, error) {
+		if client == nil {
+			var err error
+			if client, err = k8s.NewK8sClient(kubeconfigPath, contextName, []string{}); err != nil {
+				return nil, err
+			}
+		}
+		return client.GetServiceBackends(ctx, svc.Namespace, svc.Name)
+	}
+}
+
+// GraphRenderer renders IP search results as a Graphviz DOT graph of the services and pods found,
+// the pods behind the services' endpoints, the top owners of the pods and the nodes they run on
+type GraphRenderer struct {
+	backends func(contextName string) backendResolver
+	owner    func(contextName string) topOwnerResolver
+}
+
+// NewGraphRenderer creates a graph renderer that resolves endpoints and owners with the contexts of config
+func NewGraphRenderer(ctx context.Context, config K8sSearchConfig) *GraphRenderer {
+	return &GraphRenderer{
+		backends: func(contextName string) backendResolver {
+			return contextBackendResolver(ctx, config.KubeconfigPath, contextName)
+		},
+		owner: func(contextName string) topOwnerResolver {
+			return contextTopOwnerResolver(ctx, config.KubeconfigPath, contextName)
+		},
+	}
+}
+
+// errGraphUnsupported is returned for searches other than IP searches with --output graph
+var errGraphUnsupported = fmt.Errorf("%w: --output graph is only supported for IP searches", k8s.ErrInvalidOptions)
+
+// RenderIPResults writes the relationship graph of the matched services and pods
+func (r *GraphRenderer) RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error {
+	graph := newDotGraph()
+	for _, result := range results {
+		backends := r.backends(result.Context)
+		owner := r.owner(result.Context)
+
+		// Matched pods first, so they are drawn as matched when they also back a matched service
+		for _, pod := range result.Pods {
+			graph.pod(result.Context, pod, owner, true)
+		}
+
+		for _, svc := range result.Services {
+			serviceID := graph.node(result.Context, "Service", svc.Namespace, svc.Name, svc.ClusterIP, true)
+			pods, err := backends(svc)
+			if err != nil {
+				graph.comment(fmt.Sprintf("endpoints of service %s/%s in context %s: %v", svc.Namespace, svc.Name, result.Context, err))
+				continue
+			}
+			for _, backend := range pods {
+				podID := graph.pod(result.Context, backend.Pod, owner, false)
+				label := "ready"
+				if !backend.Ready {
+					label = "not ready"
+				}
+				graph.edge(serviceID, podID, label, !backend.Ready)
+			}
+		}
+	}
+	return graph.write(w)
+}
+
+// RenderPodResults rejects name search results, which have no service to start a graph from
+func (r *GraphRenderer) RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error {
+	return errGraphUnsupported
+}
+
+// RenderIngressResults rejects hostname search results
+func (r *GraphRenderer) RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error {
+	return errGraphUnsupported
+}
+
+// RenderWorkloadResults rejects workload search results
+func (r *GraphRenderer) RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error {
+	return errGraphUnsupported
+}
+
+// RenderEndpointResults rejects endpoint search results
+func (r *GraphRenderer) RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error {
+	return errGraphUnsupported
+}
+
+// RenderResourceResults rejects resource search results
+func (r *GraphRenderer) RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error {
+	return errGraphUnsupported
+}
+
+// dotGraph collects the nodes of each context and the edges between them, each added once
+type dotGraph struct {
+	contexts []string
+	nodes    map[string][]string
+	edges    []string
+	comments []string
+	seen     map[string]bool
+}
+
+func newDotGraph() *dotGraph {
+	return &dotGraph{nodes: map[string][]string{}, seen: map[string]bool{}}
+}
+
+// node adds a resource of a context and returns its ID; resources the search matched are drawn bold
+func (g *dotGraph) node(contextName, kind, namespace, name, detail string, matched bool) string {
+	id := strings.Join([]string{contextName, kind, namespace, name}, "/")
+	if g.seen[id] {
+		return id
+	}
+	g.seen[id] = true
+
+	label := kind + "\n" + name
+	if namespace != "" {
+		label = kind + "\n" + namespace + "/" + name
+	}
+	if detail != "" {
+		label += "\n" + detail
+	}
+	attributes := fmt.Sprintf("label=%q", label)
+	if matched {
+		attributes += ", penwidth=2"
+	}
+
+	if _, ok := g.nodes[contextName]; !ok {
+		g.contexts = append(g.contexts, contextName)
+	}
+	g.nodes[contextName] = append(g.nodes[contextName], fmt.Sprintf("%q [%s];", id, attributes))
+	return id
+}
+
+// pod adds a pod with edges from its top owner and to the node it runs on, and returns its ID
+func (g *dotGraph) pod(contextName string, pod k8s.PodInfo, owner topOwnerResolver, matched bool) string {
+	podID := g.node(contextName, "Pod", pod.Namespace, pod.Name, pod.PodIP, matched)
+	if kind, name := owner(pod); kind != k8s.NoOwnerKind {
+		g.edge(g.node(contextName, kind, pod.Namespace, name, "", false), podID, "owns", false)
+	}
+	if pod.NodeName != "" {
+		g.edge(podID, g.node(contextName, "Node", "", pod.NodeName, pod.HostIP, false), "runs on", false)
+	}
+	return podID
+}
+
+// edge adds a labeled edge, dashed when set
+func (g *dotGraph) edge(from, to, label string, dashed bool) {
+	key := from + "->" + to
+	if g.seen[key] {
+		return
+	}
+	g.seen[key] = true
+
+	attributes := fmt.Sprintf("label=%q", label)
+	if dashed {
+		attributes += ", style=dashed"
+	}
+	g.edges = append(g.edges, fmt.Sprintf("%q -> %q [%s];", from, to, attributes))
+}
+
+// comment records a problem building the graph, kept in the output as a DOT comment
+func (g *dotGraph) comment(comment string) {
+	g.comments = append(g.comments, "// "+strings.ReplaceAll(comment, "\n", " "))
+}
+
+// write renders the graph in DOT, with one cluster per context
+func (g *dotGraph) write(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph k8sx {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, comment := range g.comments {
+		fmt.Fprintf(&b, "  %s\n", comment)
+	}
+	for i, contextName := range g.contexts {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%q;\n", contextName)
+		for _, node := range g.nodes[contextName] {
+			fmt.Fprintf(&b, "    %s\n", node)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(&b, "  %s\n", edge)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGraphRenderer returns a graph renderer backing the nginx services with the fixture pods
+// and failing to resolve the endpoints of any other service
+func testGraphRenderer() *GraphRenderer {
+	return &GraphRenderer{
+		backends: func(contextName string) backendResolver {
+			return func(svc k8s.ServiceInfo) ([]k8s.EndpointPod, error) {
+				if svc.Name != "nginx" {
+					return nil, errors.New("endpoints not found")
+				}
+				pods := fixturePods()
+				return []k8s.EndpointPod{{Pod: pods[0], Ready: true}, {Pod: pods[1], Ready: false}}, nil
+			}
+		},
+		owner: func(contextName string) topOwnerResolver {
+			return func(pod k8s.PodInfo) (string, string) {
+				if pod.OwnerKind == "ReplicaSet" {
+					return "Deployment", "nginx"
+				}
+				return k8s.NoOwnerKind, ""
+			}
+		},
+	}
+}
+
+func TestRenderIPResultsGraph(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testGraphRenderer().RenderIPResults(&buf, fixtureIPResults()))
+	assertGolden(t, "ip_results_graph.golden", buf.Bytes())
+}
+
+func TestGraphUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorIs(t, testGraphRenderer().RenderPodResults(&buf, fixturePodResults()), k8s.ErrInvalidOptions)
+	assert.ErrorIs(t, testGraphRenderer().RenderIngressResults(&buf, fixtureIngressResults()), k8s.ErrInvalidOptions)
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, validateOutput(K8sSearchConfig{OutputFormat: OutputGraph}))
+	assert.Error(t, validateOutput(K8sSearchConfig{OutputFormat: OutputGraph, CountOnly: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{OutputFormat: OutputGraph, Aggregate: true}))
+}
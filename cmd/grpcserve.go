@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"k8sx/api/searchpb"
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcSearchServer serves the SearchService of api/searchpb using defaults from the CLI flags
+type grpcSearchServer struct {
+	searchpb.UnimplementedSearchServiceServer
+	searchServer
+}
+
+// GRPCServe starts a gRPC server exposing the search functions as the SearchService of api/searchpb,
+// with the standard health service and server reflection (e.g. for grpcurl).
+// Flags in config act as defaults that each request can override with its filters.
+func GRPCServe(config K8sSearchConfig, listen string) error {
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+
+	fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Listening for gRPC on %s", listener.Addr()))
+	return newGRPCServer(config).Serve(listener)
+}
+
+// newGRPCServer creates the gRPC server with the search, health and reflection services registered
+func newGRPCServer(config K8sSearchConfig) *grpc.Server {
+	// Requests run concurrently in the background, so no progress is drawn
+	config.NoProgress = true
+
+	server := grpc.NewServer()
+	searchpb.RegisterSearchServiceServer(server, &grpcSearchServer{searchServer: searchServer{defaults: config}})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+	return server
+}
+
+// Search streams the matches of each namespace as soon as it is searched
+func (s *grpcSearchServer) Search(req *searchpb.SearchRequest, stream searchpb.SearchService_SearchServer) error {
+	if (req.GetIp() == "") == (req.GetName() == "") {
+		return status.Error(codes.InvalidArgument, "exactly one of ip or name is required")
+	}
+
+	filters := req.GetFilters()
+	config := s.requestConfig(filters.GetContext(), strings.Join(filters.GetNamespaces(), ","))
+	applyGRPCFilters(&config, filters)
+	if ip := req.GetIp(); ip != "" {
+		if err := validateIPQuery(config, ip); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	opts := config.searchOptions()
+	if err := opts.Validate(); err != nil {
+		return grpcError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), 120*time.Second)
+	defer cancel()
+
+	// Results are deduplicated as they come, keeping those of the first context reporting a cluster
+	var deduper *k8s.ResultDeduper
+	if !config.NoDedup {
+		deduper = k8s.NewResultDeduper()
+	}
+
+	// The search serializes the result callbacks; a failed send, e.g. a client gone, stops the search
+	var sendErr error
+	var once sync.Once
+	send := func(result *searchpb.SearchResult) {
+		if err := stream.Send(result); err != nil {
+			once.Do(func() {
+				sendErr = err
+				cancel()
+			})
+		}
+	}
+
+	var err error
+	if ip := req.GetIp(); ip != "" {
+		opts.OnIPResult = func(result k8s.SearchResultWithContext) {
+			if deduper != nil {
+				var ok bool
+				if result, ok = deduper.IPResult(result); !ok {
+					return
+				}
+			}
+			send(newSearchResultProto(result.Context, result.Server, result.Namespace, result.Pods, result.Services))
+		}
+		_, err = k8s.SearchByIPAllContexts(ctx, config.KubeconfigPath, ip, config.Namespaces, config.contexts(), opts)
+	} else {
+		opts.OnPodResult = func(result k8s.PodResultWithContext) {
+			if deduper != nil {
+				var ok bool
+				if result, ok = deduper.PodResult(result); !ok {
+					return
+				}
+			}
+			send(newSearchResultProto(result.Context, result.Server, result.Namespace, result.Pods, nil))
+		}
+		_, err = k8s.SearchByNameAllContexts(ctx, config.KubeconfigPath, req.GetName(), config.Namespaces, config.contexts(), opts)
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// applyGRPCFilters overrides the defaults of config with the filters set in a request
+func applyGRPCFilters(config *K8sSearchConfig, filters *searchpb.Filters) {
+	if filters.GetFieldSelector() != "" {
+		config.FieldSelector = filters.GetFieldSelector()
+	}
+	if filters.GetRunningOnly() {
+		config.RunningOnly = true
+	}
+	if filters.GetServiceAccount() != "" {
+		config.ServiceAccount = filters.GetServiceAccount()
+	}
+	if len(filters.GetOwnerKinds()) > 0 {
+		config.OwnerKinds = filters.GetOwnerKinds()
+	}
+	if len(filters.GetAnnotations()) > 0 {
+		config.Annotations = filters.GetAnnotations()
+	}
+	if filters.GetExactName() {
+		config.ExactName = true
+	}
+	if filters.GetMaxResults() > 0 {
+		config.MaxResults = int(filters.GetMaxResults())
+	}
+}
+
+// grpcError converts an error of a search into a gRPC status with the code matching its exit code
+func grpcError(err error) error {
+	code := codes.Internal
+	switch ExitCode(err) {
+	case ExitInvalidInput:
+		code = codes.InvalidArgument
+	case ExitKubeconfig:
+		code = codes.FailedPrecondition
+	case ExitNoAccess:
+		code = codes.PermissionDenied
+	case ExitUnreachable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// newSearchResultProto converts the matches of a namespace into a SearchResult message
+func newSearchResultProto(contextName, server, namespace string, pods []k8s.PodInfo, services []k8s.ServiceInfo) *searchpb.SearchResult {
+	result := &searchpb.SearchResult{Context: contextName, Server: server, Namespace: namespace}
+	for _, pod := range pods {
+		result.Pods = append(result.Pods, &searchpb.Pod{
+			Uid:            pod.UID,
+			Name:           pod.Name,
+			Namespace:      pod.Namespace,
+			PodIp:          pod.PodIP,
+			PodIps:         pod.PodIPs,
+			HostIp:         pod.HostIP,
+			NodeName:       pod.NodeName,
+			OwnerKind:      pod.OwnerKind,
+			OwnerName:      pod.OwnerName,
+			Phase:          pod.Phase,
+			ServiceAccount: pod.ServiceAccount,
+			Labels:         pod.Labels,
+			CreatedAt:      timestamppb.New(pod.CreatedAt.Time),
+			Terminated:     pod.Terminated,
+			MatchedOn:      pod.MatchedOn,
+		})
+	}
+	for _, svc := range services {
+		service := &searchpb.Service{
+			Name:         svc.Name,
+			Namespace:    svc.Namespace,
+			Type:         svc.Type,
+			ClusterIp:    svc.ClusterIP,
+			ClusterIps:   svc.ClusterIPs,
+			ExternalIps:  svc.ExternalIPs,
+			Selector:     svc.Selector,
+			ExternalName: svc.ExternalName,
+			CreatedAt:    timestamppb.New(svc.CreatedAt.Time),
+			MatchedOn:    svc.MatchedOn,
+		}
+		for _, port := range svc.Ports {
+			service.Ports = append(service.Ports, &searchpb.Port{
+				Name:       port.Name,
+				Port:       port.Port,
+				TargetPort: port.TargetPort,
+				NodePort:   port.NodePort,
+				Protocol:   port.Protocol,
+			})
+		}
+		result.Services = append(result.Services, service)
+	}
+	return result
+}
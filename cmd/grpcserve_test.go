@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8sx/api/searchpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// grpcTestClient starts the gRPC server over an in-memory connection, searching the API server at server
+func grpcTestClient(t *testing.T, server string) searchpb.SearchServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := newGRPCServer(K8sSearchConfig{KubeconfigPath: writeServerKubeconfig(t, server)})
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return searchpb.NewSearchServiceClient(conn)
+}
+
+// receiveAll reads a Search stream to its end
+func receiveAll(stream grpc.ServerStreamingClient[searchpb.SearchResult]) ([]*searchpb.SearchResult, error) {
+	results := []*searchpb.SearchResult{}
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+}
+
+// TestGRPCSearch tests streaming the matches of each namespace over gRPC
+func TestGRPCSearch(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		namespace := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")[0]
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pods"):
+			json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: namespace, UID: types.UID("uid-" + namespace), Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{NodeName: "node-1", ServiceAccountName: "web"},
+				Status:     corev1.PodStatus{PodIP: "10.0.0.1", Phase: corev1.PodRunning},
+			}}})
+		case strings.HasSuffix(r.URL.Path, "/services"):
+			json.NewEncoder(w).Encode(corev1.ServiceList{Items: []corev1.Service{{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+				Spec: corev1.ServiceSpec{
+					Type:      corev1.ServiceTypeClusterIP,
+					ClusterIP: "10.96.0.1",
+					Ports:     []corev1.ServicePort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+				},
+			}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+		}
+	}))
+	defer api.Close()
+	client := grpcTestClient(t, api.URL)
+	ctx := context.Background()
+
+	stream, err := client.Search(ctx, &searchpb.SearchRequest{
+		Name:    "web",
+		Filters: &searchpb.Filters{Namespaces: []string{"default", "payments"}, ServiceAccount: "web"},
+	})
+	require.NoError(t, err)
+	results, err := receiveAll(stream)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	namespaces := []string{}
+	for _, result := range results {
+		assert.Equal(t, "prod", result.Context)
+		require.Len(t, result.Pods, 1)
+		assert.Equal(t, "web-1", result.Pods[0].Name)
+		assert.Equal(t, "web", result.Pods[0].ServiceAccount)
+		assert.Equal(t, map[string]string{"app": "web"}, result.Pods[0].Labels)
+		namespaces = append(namespaces, result.Namespace)
+	}
+	assert.ElementsMatch(t, []string{"default", "payments"}, namespaces)
+
+	// IP searches also stream the services
+	stream, err = client.Search(ctx, &searchpb.SearchRequest{
+		Ip:      "10.96.0.1",
+		Filters: &searchpb.Filters{Namespaces: []string{"default"}},
+	})
+	require.NoError(t, err)
+	results, err = receiveAll(stream)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Services, 1)
+	assert.Equal(t, "web", results[0].Services[0].Name)
+	assert.Equal(t, int32(80), results[0].Services[0].Ports[0].Port)
+
+	// Filters that exclude every pod stream nothing
+	stream, err = client.Search(ctx, &searchpb.SearchRequest{
+		Name:    "web",
+		Filters: &searchpb.Filters{Namespaces: []string{"default"}, ServiceAccount: "other"},
+	})
+	require.NoError(t, err)
+	results, err = receiveAll(stream)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// TestGRPCSearchInvalid tests that invalid requests fail with InvalidArgument
+func TestGRPCSearchInvalid(t *testing.T) {
+	client := grpcTestClient(t, "https://127.0.0.1:1")
+
+	for _, req := range []*searchpb.SearchRequest{
+		{},
+		{Ip: "10.96.0.1", Name: "web"},
+		{Ip: "not-an-ip"},
+		{Name: "web", Filters: &searchpb.Filters{FieldSelector: "metadata.labels=x"}},
+	} {
+		stream, err := client.Search(context.Background(), req)
+		require.NoError(t, err)
+		_, err = receiveAll(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// DefaultHistorySize is the number of searches kept in the history file, older ones are dropped
+const DefaultHistorySize = 500
+
+// HistoryEntry is a search recorded in the history file
+type HistoryEntry struct {
+	Time     time.Time `json:"time"`
+	Query    string    `json:"query"`
+	Contexts []string  `json:"contexts"`
+	Matches  int       `json:"matches"`
+}
+
+// DefaultHistoryPath returns the path of the history file in the user cache directory
+func DefaultHistoryPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "k8sx", "history.jsonl")
+}
+
+// searchHistory collects the contexts searched and the matches found by the running search
+// for its history entry. It is safe for concurrent use.
+type searchHistory struct {
+	mu       sync.Mutex
+	contexts map[string]bool
+	matches  int
+}
+
+// addContext records a searched context; a nil history ignores it
+func (h *searchHistory) addContext(timing k8s.ContextTiming) {
+	if h == nil || timing.Skipped {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.contexts[timing.Context] = true
+}
+
+// setMatches records the number of matches shown; a nil history ignores it
+func (h *searchHistory) setMatches(summary searchSummary) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.matches = summary.total()
+}
+
+// WithHistory runs search and, when config.HistoryFile is set and the search succeeds, appends
+// the query, the contexts searched and the number of matches to the history file, keeping the last
+// HistorySize searches. Failing to record the search is reported as a notice, not as an error.
+func WithHistory(config K8sSearchConfig, query string, search func(config K8sSearchConfig) error) error {
+	if config.HistoryFile == "" || config.DryRun {
+		return search(config)
+	}
+
+	history := &searchHistory{contexts: map[string]bool{}}
+	config.history = history
+	if err := search(config); err != nil {
+		return err
+	}
+
+	entry := HistoryEntry{Time: now(), Query: query, Contexts: []string{}, Matches: history.matches}
+	for contextName := range history.contexts {
+		entry.Contexts = append(entry.Contexts, contextName)
+	}
+	sort.Strings(entry.Contexts)
+	// Single-context searches do not go through the per-context search of all-contexts searches
+	if len(entry.Contexts) == 0 && config.ContextName != "" {
+		entry.Contexts = []string{config.ContextName}
+	}
+
+	if err := AppendHistory(config.HistoryFile, entry, config.HistorySize); err != nil {
+		if w := config.notices(); w != nil {
+			fmt.Fprintln(w, text.FgYellow.Sprintf("Could not record the search in the history: %v", err))
+		}
+	}
+	return nil
+}
+
+// AppendHistory appends entry to the history file, keeping only the last size entries (0 = DefaultHistorySize).
+// The file is rewritten through a temporary file, so an interrupted write never truncates it.
+func AppendHistory(path string, entry HistoryEntry, size int) error {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	entries, err := ReadHistory(path)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > size {
+		entries = entries[len(entries)-size:]
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		if err := writeStructured(&buf, OutputJSONL, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	// Removing fails harmlessly once the file was renamed into place
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// ReadHistory reads the searches recorded in the history file, oldest first. A missing file has none,
+// and lines that cannot be parsed, e.g. written by a newer version, are skipped.
+func ReadHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []HistoryEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	entries := []HistoryEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+// ListSearchHistory lists the last limit searches recorded in the history file at path (0 = all),
+// most recent first, as a table or, with --output json, as an array
+func ListSearchHistory(config K8sSearchConfig, path string, limit int) error {
+	switch config.OutputFormat {
+	case "", OutputTable, OutputJSON:
+	default:
+		return fmt.Errorf("%w: history supports table and json output", k8s.ErrInvalidOptions)
+	}
+	if path == "" {
+		return fmt.Errorf("%w: no cache directory to read the history from", k8s.ErrInvalidOptions)
+	}
+
+	entries, err := ReadHistory(path)
+	if err != nil {
+		return err
+	}
+
+	recent := []HistoryEntry{}
+	for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(recent) < limit); i-- {
+		recent = append(recent, entries[i])
+	}
+	return writeHistory(config.out(), config, recent)
+}
+
+// writeHistory writes recorded searches as a table, or as json
+func writeHistory(w io.Writer, config K8sSearchConfig, entries []HistoryEntry) error {
+	if config.OutputFormat == OutputJSON {
+		return writeStructured(w, OutputJSON, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No searches recorded (record them with --history or history: true in the config file)"))
+		return nil
+	}
+
+	historyTable := table.Table{}
+	historyTable.SetStyle(tableStyle())
+	historyTable.AppendRow(table.Row{"Time", "Query", "Contexts", "Matches"})
+	for _, entry := range entries {
+		historyTable.AppendRow(table.Row{
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.Query,
+			strings.Join(entry.Contexts, ", "),
+			strconv.Itoa(entry.Matches),
+		})
+	}
+	fmt.Fprintln(w, historyTable.Render())
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAppendHistory tests appending searches to the history file and dropping the oldest ones
+func TestAppendHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k8sx", "history.jsonl")
+
+	entries, err := ReadHistory(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, query := range []string{"10.0.0.1", "nginx", "payments", "10.0.0.0/24"} {
+		entry := HistoryEntry{Time: start.Add(time.Duration(i) * time.Minute), Query: query, Contexts: []string{"prod"}, Matches: i}
+		require.NoError(t, AppendHistory(path, entry, 3))
+	}
+
+	entries, err = ReadHistory(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "nginx", entries[0].Query)
+	assert.Equal(t, "10.0.0.0/24", entries[2].Query)
+	assert.Equal(t, 3, entries[2].Matches)
+	assert.True(t, entries[2].Time.Equal(start.Add(3*time.Minute)))
+
+	// Lines that cannot be parsed are skipped
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append([]byte("not json\n"), data...), 0o644))
+	entries, err = ReadHistory(path)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+// TestWithHistory tests recording the contexts searched and the matches found by a search
+func TestWithHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	config := K8sSearchConfig{HistoryFile: path, Err: &bytes.Buffer{}}
+
+	err := WithHistory(config, "10.0.0.1", func(config K8sSearchConfig) error {
+		opts := config.searchOptions()
+		opts.OnContextSearched(k8s.ContextTiming{Context: "staging"})
+		opts.OnContextSearched(k8s.ContextTiming{Context: "prod"})
+		opts.OnContextSearched(k8s.ContextTiming{Context: "dev", Skipped: true})
+		config.history.setMatches(summarizeIPResults(fixtureIPResults()))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Failed searches and single-context searches
+	failed := errors.New("search failed")
+	assert.Equal(t, failed, WithHistory(config, "nginx", func(config K8sSearchConfig) error {
+		return failed
+	}))
+	config.ContextName = "prod"
+	require.NoError(t, WithHistory(config, "web", func(config K8sSearchConfig) error {
+		return nil
+	}))
+
+	entries, err := ReadHistory(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "10.0.0.1", entries[0].Query)
+	assert.Equal(t, []string{"prod", "staging"}, entries[0].Contexts)
+	assert.Equal(t, summarizeIPResults(fixtureIPResults()).total(), entries[0].Matches)
+	assert.Equal(t, "web", entries[1].Query)
+	assert.Equal(t, []string{"prod"}, entries[1].Contexts)
+
+	// Without a history file nothing is recorded
+	require.NoError(t, WithHistory(K8sSearchConfig{}, "ignored", func(config K8sSearchConfig) error {
+		assert.Nil(t, config.history)
+		return nil
+	}))
+}
+
+// TestWriteHistoryGolden tests listing recorded searches as a table
+func TestWriteHistoryGolden(t *testing.T) {
+	entries := []HistoryEntry{
+		{Time: time.Date(2024, 5, 1, 12, 5, 0, 0, time.Local), Query: "nginx", Contexts: []string{"prod", "staging"}, Matches: 3},
+		{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local), Query: "10.0.0.1", Contexts: []string{}, Matches: 0},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeHistory(&buf, K8sSearchConfig{}, entries))
+	assertGolden(t, "history_table.golden", buf.Bytes())
+
+	var errOut bytes.Buffer
+	buf.Reset()
+	require.NoError(t, writeHistory(&buf, K8sSearchConfig{Err: &errOut}, []HistoryEntry{}))
+	assert.Empty(t, buf.String())
+	assert.Contains(t, errOut.String(), "No searches recorded")
+}
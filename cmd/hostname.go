@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var ingressCSVHeader = []string{"Context", "Namespace", "Ingress Name", "Hosts", "Backends", "Addresses"}
+
+// IsServiceDNSName reports whether query is an in-cluster service DNS name (<service>.<namespace>.svc[.<cluster domain>])
+func IsServiceDNSName(query string) bool {
+	_, _, ok := k8s.ParseServiceDNSName(query)
+	return ok
+}
+
+// IsHostname is a wrapper for k8s.IsHostname for use in CLI
+func IsHostname(query string) bool {
+	return k8s.IsHostname(query)
+}
+
+// SearchK8sByServiceDNSAllContexts looks up the service named by an in-cluster DNS name in all contexts,
+// falling back to a name search when no such service exists
+func SearchK8sByServiceDNSAllContexts(config K8sSearchConfig, query string) error {
+	service, namespace, ok := k8s.ParseServiceDNSName(query)
+	if !ok {
+		return fmt.Errorf("%w: %s is not a service DNS name", k8s.ErrInvalidQuery, query)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	if config.DryRun {
+		return printSearchPlan(config, []string{namespace})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	if config.isVerbose() {
+		fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Looking up service %s in namespace %s\n", service, namespace))
+	}
+
+	results, err := k8s.SearchByServiceDNSAllContexts(ctx, config.KubeconfigPath, service, namespace, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	if len(results) == 0 {
+		if config.isVerbose() {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No service found for DNS name %s, falling back to name search...\n", query))
+		}
+		return SearchK8sByNameAllContexts(config, query)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupIPResults(results)
+	}
+
+	return displayIPResults(ctx, config, results, fmt.Sprintf("No service found for DNS name: %s", query))
+}
+
+// SearchK8sByHostAllContexts searches ingresses routing a hostname and ExternalName services aliasing it
+// across all contexts and all (or specified) namespaces, falling back to a name search when nothing matches
+func SearchK8sByHostAllContexts(config K8sSearchConfig, host string) error {
+	if !k8s.IsHostname(host) {
+		return fmt.Errorf("%w: %s is not a hostname", k8s.ErrInvalidQuery, host)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	namespaces := allContextsNamespaces(&config, "host", host)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	results, err := k8s.SearchByHostAllContexts(ctx, config.KubeconfigPath, host, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	if len(results) == 0 {
+		if config.isVerbose() {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No ingress or ExternalName service found for host %s, falling back to name search...\n", host))
+		}
+		// Reuse the discovered namespaces instead of discovering them again
+		config.Namespaces = namespaces
+		return SearchK8sByNameAllContexts(config, host)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupIngressResults(results)
+	}
+
+	return displayIngressResults(ctx, config, results)
+}
+
+// displayIngressResults prints ingress search results in the configured output format
+func displayIngressResults(ctx context.Context, config K8sSearchConfig, results []k8s.IngressResultWithContext) error {
+	defer writeTruncationNotice(config, summarizeIngressResults(results).total())
+	defer writeNoAccessNotice(config, summarizeIngressResults(results).total())
+	defer writeSummaryJSON(config, summarizeIngressResults(results))
+	defer config.history.setMatches(summarizeIngressResults(results))
+
+	if config.CountOnly {
+		return writeSummary(config, summarizeIngressResults(results))
+	}
+	return config.renderer(ctx).RenderIngressResults(config.out(), results)
+}
+
+// writeIngressResults writes ingress search results in a non-table output format
+func writeIngressResults(w io.Writer, config K8sSearchConfig, results []k8s.IngressResultWithContext) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, result := range results {
+			for i := range result.Ingresses {
+				record := jsonlRecord{Kind: "Ingress", Context: result.Context, Server: result.Server, Namespace: result.Namespace, Ingress: &result.Ingresses[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			for i := range result.Services {
+				record := jsonlRecord{Kind: "Service", Context: result.Context, Server: result.Server, Namespace: result.Namespace, Service: &result.Services[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		rows := [][]string{}
+		for _, result := range results {
+			for _, ingress := range result.Ingresses {
+				rows = append(rows, []string{
+					result.Context,
+					result.Namespace,
+					ingress.Name,
+					strings.Join(ingress.Hosts, ","),
+					strings.Join(ingress.Backends, ","),
+					strings.Join(ingress.Addresses, ","),
+				})
+			}
+		}
+		serviceRows := [][]string{}
+		for _, result := range results {
+			serviceRows = append(serviceRows, serviceCSVRows(result.Context, result.Namespace, result.Services)...)
+		}
+
+		if config.OutputDir != "" {
+			if err := writeCSVFile(config.OutputDir, "ingresses.csv", ingressCSVHeader, rows); err != nil {
+				return err
+			}
+			if len(serviceRows) == 0 {
+				return nil
+			}
+			return writeCSVFile(config.OutputDir, "services.csv", serviceCSVHeader, serviceRows)
+		}
+
+		if err := writeCSV(w, ingressCSVHeader, rows); err != nil {
+			return err
+		}
+		// ExternalName services follow as a second section, separated by a blank line
+		if len(serviceRows) == 0 {
+			return nil
+		}
+		fmt.Fprintln(w)
+		return writeCSV(w, serviceCSVHeader, serviceRows)
+	}
+	return writeResults(w, config, results, summarizeIngressResults(results))
+}
+
+// renderIngressTable renders ingresses as a table
+func renderIngressTable(ingresses []k8s.IngressInfo) string {
+	ingressTable := table.Table{}
+	ingressTable.SetStyle(tableStyle())
+	ingressTable.AppendRow(table.Row{"Ingress Name", "Hosts", "Backends", "Addresses"})
+
+	for _, ingress := range ingresses {
+		ingressTable.AppendRow(table.Row{
+			ingress.Name,
+			strings.Join(ingress.Hosts, "\n"),
+			strings.Join(ingress.Backends, "\n"),
+			strings.Join(ingress.Addresses, ", "),
+		})
+	}
+	return ingressTable.Render()
+}
+
+// summarizeIngressResults counts ingresses and services in hostname search results
+func summarizeIngressResults(results []k8s.IngressResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results), Services: new(int), Ingresses: new(int)}
+	for _, result := range results {
+		*summary.Ingresses += len(result.Ingresses)
+		*summary.Services += len(result.Services)
+	}
+	return summary
+}
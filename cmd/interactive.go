@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+)
+
+// maxVisibleItems is the number of matches shown at once in the selector
+const maxVisibleItems = 15
+
+// selectItem is one selectable result in the interactive selector
+type selectItem struct {
+	label  string
+	detail func() string
+}
+
+// selectIPResult lets the user pick a pod or service from IP search results and prints its details
+func selectIPResult(ctx context.Context, config K8sSearchConfig, results []k8s.SearchResultWithContext) error {
+	items := []selectItem{}
+	resolvers := newContextResolvers(ctx, config.KubeconfigPath)
+	for _, result := range results {
+		owner := resolvers.owner(result.Context)
+		for _, pod := range result.Pods {
+			items = append(items, podSelectItem(result.Context, pod, owner))
+		}
+		for _, svc := range result.Services {
+			items = append(items, serviceSelectItem(result.Context, svc))
+		}
+	}
+	return runInteractive(config.out(), items)
+}
+
+// selectPodResult lets the user pick a pod from pod search results and prints its details
+func selectPodResult(ctx context.Context, config K8sSearchConfig, results []k8s.PodResultWithContext) error {
+	items := []selectItem{}
+	resolvers := newContextResolvers(ctx, config.KubeconfigPath)
+	for _, result := range results {
+		owner := resolvers.owner(result.Context)
+		for _, pod := range result.Pods {
+			items = append(items, podSelectItem(result.Context, pod, owner))
+		}
+	}
+	return runInteractive(config.out(), items)
+}
+
+// runInteractive runs the selector on the terminal and prints the detail of the chosen item to w
+func runInteractive(w io.Writer, items []selectItem) error {
+	if len(items) == 0 {
+		fmt.Fprintln(w, text.FgYellow.Sprintf("No results to select from"))
+		return nil
+	}
+
+	index, err := runSelector(items)
+	if err != nil {
+		return fmt.Errorf("interactive selection failed: %w", err)
+	}
+	if index < 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, items[index].detail())
+	return nil
+}
+
+// podSelectItem builds the selector entry and detail view for a pod
+func podSelectItem(contextName string, pod k8s.PodInfo, owner ownerResolver) selectItem {
+	return selectItem{
+		label: fmt.Sprintf("pod  %s/%s/%s  %s", contextName, pod.Namespace, pod.Name, formatIPs(pod.PodIP, pod.PodIPs, ",")),
+		detail: func() string {
+			return renderPodDetail(contextName, pod, owner)
+		},
+	}
+}
+
+// serviceSelectItem builds the selector entry and detail view for a service
+func serviceSelectItem(contextName string, svc k8s.ServiceInfo) selectItem {
+	return selectItem{
+		label: fmt.Sprintf("svc  %s/%s/%s  %s", contextName, svc.Namespace, svc.Name, formatClusterIP(svc, ",")),
+		detail: func() string {
+			ports := []string{}
+			for _, port := range svc.Ports {
+				ports = append(ports, formatPort(port))
+			}
+			rows := []table.Row{
+				{"Context", contextName},
+				{"Namespace", svc.Namespace},
+				{"Service Name", svc.Name},
+				{"Type", svc.Type},
+				{"Cluster IP", formatClusterIP(svc, "\n")},
+				{"External IPs", strings.Join(svc.ExternalIPs, ", ")},
+				{"Ports", strings.Join(ports, ", ")},
+				{"Node Ports", strings.Join(formatNodePorts(svc.Ports), ", ")},
+				{"Selector", formatMap(svc.Selector)},
+			}
+			if svc.MatchedOn != "" {
+				rows = append(rows, table.Row{"Matched On", formatMatchedOn(svc.MatchedOn)})
+			}
+			return renderDetailTable(rows)
+		},
+	}
+}
+
+// renderDetailTable renders key/value rows as a two column table
+func renderDetailTable(rows []table.Row) string {
+	detailTable := table.Table{}
+	detailTable.SetStyle(tableStyle())
+	for _, row := range rows {
+		detailTable.AppendRow(row)
+	}
+	return detailTable.Render()
+}
+
+// formatOwner formats the owner kind and owner text for the detail view
+func formatOwner(kind string, owner string) string {
+	if kind == "" {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s %s", kind, owner)
+}
+
+// formatMap formats a map as sorted key=value lines
+func formatMap(values map[string]string) string {
+	lines := make([]string, 0, len(values))
+	for k, v := range values {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// fuzzyMatch reports whether all characters of query appear in order in candidate (case-insensitive)
+func fuzzyMatch(query string, candidate string) bool {
+	// Runes rather than bytes, so queries with non-ASCII characters match too
+	runes := []rune(strings.ToLower(query))
+	candidate = strings.ToLower(candidate)
+
+	i := 0
+	for _, r := range candidate {
+		if i == len(runes) {
+			break
+		}
+		if r == runes[i] {
+			i++
+		}
+	}
+	return i == len(runes)
+}
+
+// filterItems returns the indexes of the items whose label fuzzy-matches query
+func filterItems(items []selectItem, query string) []int {
+	matches := []int{}
+	for i, item := range items {
+		if fuzzyMatch(query, item.label) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// runSelector shows a filter-as-you-type list on the terminal and returns the chosen item index,
+// or -1 when the selection was cancelled
+func runSelector(items []selectItem) (int, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return -1, fmt.Errorf("--interactive requires a terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return -1, fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	query := ""
+	cursor := 0
+	buf := make([]byte, 16)
+
+	for {
+		matches := filterItems(items, query)
+		if cursor >= len(matches) {
+			cursor = len(matches) - 1
+		}
+		if cursor < 0 {
+			cursor = 0
+		}
+		drawSelector(items, matches, query, cursor)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			clearScreen()
+			return -1, fmt.Errorf("failed to read input: %w", err)
+		}
+		key := string(buf[:n])
+
+		switch {
+		case key == "\r" || key == "\n":
+			clearScreen()
+			if len(matches) == 0 {
+				return -1, nil
+			}
+			return matches[cursor], nil
+		case key == "\x1b" || key == "\x03":
+			// Esc or Ctrl-C cancels the selection
+			clearScreen()
+			return -1, nil
+		case key == "\x1b[A" || key == "\x10":
+			// Up arrow or Ctrl-P
+			cursor--
+		case key == "\x1b[B" || key == "\x0e":
+			// Down arrow or Ctrl-N
+			cursor++
+		case key == "\x7f" || key == "\x08":
+			// Backspace
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				cursor = 0
+			}
+		case isPrintable(key):
+			// Pasted or fast typed text can arrive in a single read
+			query += key
+			cursor = 0
+		}
+	}
+}
+
+// isPrintable reports whether key consists only of printable ASCII characters
+func isPrintable(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// drawSelector redraws the selector: the filter prompt followed by a window of matches around the cursor
+func drawSelector(items []selectItem, matches []int, query string, cursor int) {
+	clearScreen()
+	fmt.Printf("Filter: %s\r\n", query)
+	fmt.Printf("%s\r\n", text.FgHiBlack.Sprintf("%d/%d matches - arrows to move, enter to select, esc to cancel", len(matches), len(items)))
+
+	start := 0
+	if cursor >= maxVisibleItems {
+		start = cursor - maxVisibleItems + 1
+	}
+	end := start + maxVisibleItems
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	for i := start; i < end; i++ {
+		label := items[matches[i]].label
+		if i == cursor {
+			fmt.Printf("%s\r\n", text.ReverseVideo.Sprint("> "+label))
+		} else {
+			fmt.Printf("  %s\r\n", label)
+		}
+	}
+}
+
+// clearScreen clears the terminal and moves the cursor to the top left corner
+func clearScreen() {
+	fmt.Print("\x1b[H\x1b[2J")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFuzzyMatch tests matching the characters of a query in order, ignoring case
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query     string
+		candidate string
+		expected  bool
+	}{
+		{"", "nginx-7d9c-abcde", true},
+		{"", "", true},
+		{"ngx", "nginx-7d9c-abcde", true},
+		{"nginx-7d9c-abcde", "nginx-7d9c-abcde", true},
+		{"xgn", "nginx-7d9c-abcde", false},
+		{"NGINX", "nginx-7d9c-abcde", true},
+		{"prod/web", "Prod/Default/Web-1", true},
+		{"nginxx", "nginx", false},
+		{"a", "", false},
+		{"müll", "Müller-api", true},
+		{"ümll", "Müller-api", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, fuzzyMatch(tt.query, tt.candidate), "query %q, candidate %q", tt.query, tt.candidate)
+	}
+}
+
+// TestFilterItems tests keeping the indexes of matching items in their order
+func TestFilterItems(t *testing.T) {
+	items := []selectItem{
+		{label: "prod  default/nginx-7d9c-abcde  10.0.0.5"},
+		{label: "prod  default/redis-0  10.0.0.7"},
+		{label: "dev   shop/nginx-5f6b-xyz12  10.1.0.5"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"", []int{0, 1, 2}},
+		{"nginx", []int{0, 2}},
+		{"DEV", []int{2}},
+		{"prodredis", []int{1}},
+		{"10.0.0", []int{0, 1}},
+		{"mysql", []int{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, filterItems(items, tt.query), "query %q", tt.query)
+	}
+	assert.Empty(t, filterItems(nil, "nginx"))
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -11,14 +12,222 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // K8sSearchConfig represents the configuration for K8s search
 type K8sSearchConfig struct {
 	KubeconfigPath string
 	Namespaces     []string
+	AllNamespaces  bool
 	ContextName    string
+	// Contexts restricts all-contexts searches to these contexts when ContextName is empty (nil = all)
+	Contexts        []string
+	OutputFormat    string
+	OutputDir       string
+	NoDedup         bool
+	ShowContainers  bool
+	CountOnly       bool
+	Interactive     bool
+	Retries         int
+	Since           time.Duration
+	FieldSelector   string
+	Annotations     []string
+	OwnerKinds      []string
+	DryRun          bool
+	PrecheckTimeout time.Duration
+	NoProgress      bool
+	MaxResults      int
+	Aggregate       bool
+	HostIPOnly      bool
+	Timings         bool
+	// ExactName makes name searches match whole pod and workload names instead of names containing the query
+	ExactName bool
+	// Columns adds pod table columns showing labels or annotations, e.g. "label:app" or "anno:build/commit"
+	Columns []string
+	// ContextConcurrency and NamespaceConcurrency are the number of contexts searched in parallel
+	// and of namespaces searched in parallel within each context (0 = 1)
+	ContextConcurrency   int
+	NamespaceConcurrency int
+	// QPS and Burst override the client-side rate limit of each context (0 = client-go defaults)
+	QPS   float32
+	Burst int
+	// ContextTimeout bounds the search of each context in all-contexts searches (0 = no limit)
+	ContextTimeout time.Duration
+	// ExecTimeout bounds the exec credential plugin of each context (0 = no limit)
+	ExecTimeout time.Duration
+	// Quiet leaves only the results in table output, dropping informational messages and summaries;
+	// notices about skipped contexts go to stderr
+	Quiet bool
+	// MatchContainers makes name searches also match init and ephemeral container names
+	MatchContainers bool
+	// ContainerPorts makes port searches also match pods whose containers declare the port
+	ContainerPorts bool
+	// ContextFromNamespace makes all-contexts searches search only the contexts that have one of Namespaces
+	ContextFromNamespace bool
+	// Both searches queries by IP (when they are one) and by name at once instead of auto-detecting their kind
+	Both bool
+	// SummaryJSON prints the summary counts as one line of json after the result tables
+	SummaryJSON bool
+	// DetectDuplicates makes all-contexts IP searches report the IPs assigned in more than one cluster
+	DetectDuplicates bool
+	// RunningOnly keeps only pods in the Running phase
+	RunningOnly bool
+	// ServiceAccount keeps only pods running as this service account and shows it in pod tables
+	ServiceAccount string
+	// Template is the Go template applied to the results with --output template
+	Template string
+	// First stops the search at the first match, like MaxResults of 1 without a truncation notice
+	First bool
+	// HistoryFile receives an entry for every search run through WithHistory ("" = no history),
+	// keeping the last HistorySize searches (0 = DefaultHistorySize)
+	HistoryFile string
+	HistorySize int
+	// Exec is a command run for every matched pod, with {namespace}, {name}, {context} and {pod_ip}
+	// substituted; it is only printed unless Confirm is set
+	Exec    string
+	Confirm bool
+	// Kind makes name searches return workloads of this kind (deployment, statefulset, daemonset) instead of pods
+	Kind string
+	// Resource makes name searches return objects of this group/version/kind, e.g. custom resources, instead of pods
+	Resource string
+	// OutputFile receives the output instead of Out when searches are run through WithOutputFile
+	OutputFile string
+	// Gzip compresses the output of searches run through WithOutputFile, as does a .gz OutputFile
+	Gzip bool
+	// IPContains makes IP searches match pod, host and service IPs containing the query, e.g. ".137"
+	IPContains bool
+	// ShowManager adds a pod table column with the field managers that wrote each pod, e.g. argocd-controller or helm
+	ShowManager bool
+	// Sort orders the pods and services of each table by a column, e.g. "podip" or "-age" for descending
+	Sort string
+	// Out receives all output of a search (nil = stdout)
+	Out io.Writer
+	// Err receives informational messages and notices, keeping them out of the results (nil = Out if set, else stderr)
+	Err io.Writer
+	// Renderer renders search results (nil = table or structured output matching OutputFormat)
+	Renderer Renderer
+
+	// timings collects the per-context timings of the running search when Timings is set
+	timings *timingReport
+	// report collects the skipped contexts and failures of the running search for json output
+	report *searchReport
+	// history collects the contexts searched and matches found for the history entry of the running search
+	history *searchHistory
+	// noAccessibleNamespaces is set when namespace discovery found no namespace the search can access
+	noAccessibleNamespaces bool
+	// namespacesDiscovered is set when the namespaces searched are those namespace discovery found
+	namespacesDiscovered bool
+	// gzipStdout is set while Out compresses the output to stdout, see withGzip
+	gzipStdout bool
+	// singleContext marks searches of the context given with --context, see SearchK8sByIP
+	singleContext bool
+}
+
+// contexts returns the contexts an all-contexts search is restricted to (nil = all)
+func (c K8sSearchConfig) contexts() []string {
+	if c.ContextName == "" {
+		return c.Contexts
+	}
+	return []string{c.ContextName}
+}
+
+// searchScope describes what a search covered, for the message shown when it found nothing
+func (c K8sSearchConfig) searchScope() string {
+	if c.singleContext {
+		return "in context " + c.ContextName
+	}
+	return "across all contexts and namespaces"
+}
+
+// discoveryContext returns the context namespaces are discovered in for all-contexts searches
+// (empty = the current context)
+func (c K8sSearchConfig) discoveryContext() string {
+	if contexts := c.contexts(); len(contexts) > 0 {
+		return contexts[0]
+	}
+	return ""
+}
+
+// searchOptions returns the options applied to every API call of a search
+func (c K8sSearchConfig) searchOptions() k8s.SearchOptions {
+	progress := newProgressBar(c)
+	return k8s.SearchOptions{
+		Retries:         c.Retries,
+		Since:           c.Since,
+		RunningOnly:     c.RunningOnly,
+		ServiceAccount:  c.ServiceAccount,
+		FieldSelector:   c.FieldSelector,
+		Annotations:     c.Annotations,
+		OwnerKinds:      c.OwnerKinds,
+		PrecheckTimeout: c.PrecheckTimeout,
+		OnContextSkipped: func(contextName string, err error) {
+			c.report.skip(contextName, "", err)
+			if w := c.notices(); w != nil {
+				progress.clear()
+				fmt.Fprintln(w, text.FgYellow.Sprintf("Skipping context %s: %v", contextName, err))
+			}
+		},
+		OnNamespaceSkipped: func(contextName string, namespace string, err error) {
+			c.report.skip(contextName, namespace, err)
+			if w := c.notices(); w != nil {
+				progress.clear()
+				fmt.Fprintln(w, text.FgYellow.Sprintf("Skipping namespace %s in context %s, still throttled after retrying: %v", namespace, contextName, err))
+			}
+		},
+		OnSearchFailed: func(contextName string, namespace string, err error) {
+			c.report.fail(contextName, namespace, err)
+			if w := c.notices(); w != nil {
+				progress.clear()
+				if namespace == "" {
+					fmt.Fprintln(w, text.FgRed.Sprintf("Failed to list namespaces in context %s: %v", contextName, err))
+				} else {
+					fmt.Fprintln(w, text.FgRed.Sprintf("Failed to search namespace %s in context %s: %v", namespace, contextName, err))
+				}
+			}
+		},
+		OnProgress:           progress.update,
+		MaxResults:           c.maxResults(),
+		HostIPOnly:           c.HostIPOnly,
+		ServicesOnly:         k8s.IsServiceKind(c.Kind),
+		IPContains:           c.IPContains,
+		ExactName:            c.ExactName,
+		MatchContainers:      c.MatchContainers,
+		ContainerPorts:       c.ContainerPorts,
+		ContextFromNamespace: c.ContextFromNamespace,
+		NamespacesDiscovered: c.namespacesDiscovered,
+		OnContextSearched: func(timing k8s.ContextTiming) {
+			c.timings.add(timing)
+			c.history.addContext(timing)
+		},
+		ContextConcurrency:   c.ContextConcurrency,
+		NamespaceConcurrency: c.NamespaceConcurrency,
+		QPS:                  c.QPS,
+		Burst:                c.Burst,
+		ContextTimeout:       c.ContextTimeout,
+		ExecTimeout:          c.ExecTimeout,
+	}
+}
+
+// resultSort returns the order of pods and services given with Sort, which validateOutput already parsed
+func (c K8sSearchConfig) resultSort() k8s.ResultSort {
+	resultSort, _ := k8s.ParseResultSort(c.Sort)
+	return resultSort
+}
+
+// maxResults returns the cap on the matches of a search, a single one with First
+func (c K8sSearchConfig) maxResults() int {
+	if c.First {
+		return 1
+	}
+	return c.MaxResults
+}
+
+// nameMatch describes how name searches match names, for messages
+func (c K8sSearchConfig) nameMatch() string {
+	if c.ExactName {
+		return "with name"
+	}
+	return "with name containing"
 }
 
 // ValidateIP is a wrapper for k8s.ValidateIP for use in CLI
@@ -26,471 +235,645 @@ func ValidateIP(ip string) bool {
 	return k8s.ValidateIP(ip)
 }
 
-// formatTargetPort properly formats a target port, handling both integer and string (named) ports
-func formatTargetPort(targetPort intstr.IntOrString) string {
-	if targetPort.Type == intstr.String {
-		return targetPort.StrVal
+// ValidateCIDR is a wrapper for k8s.ValidateCIDR for use in CLI
+func ValidateCIDR(cidr string) bool {
+	return k8s.ValidateCIDR(cidr)
+}
+
+// SearchK8sByPortAllContexts searches services exposing a port across all contexts and all (or specified) namespaces
+func SearchK8sByPortAllContexts(config K8sSearchConfig, port string) error {
+	if port == "" {
+		return fmt.Errorf("%w: port cannot be empty", k8s.ErrInvalidQuery)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	namespaces := allContextsNamespaces(&config, "port", port)
+
+	if config.isVerbose() && k8s.IsNodePort(port) {
+		fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Port %s is in the default NodePort range (%d-%d); node ports are unique per cluster\n", port, k8s.DefaultNodePortMin, k8s.DefaultNodePortMax))
+	}
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	// Search across all contexts and namespaces
+	results, err := k8s.SearchByPortAllContexts(ctx, config.KubeconfigPath, port, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupIPResults(results)
+	}
+
+	notFound := fmt.Sprintf("No services found exposing port: %s across all contexts and namespaces", port)
+	if config.ContainerPorts {
+		notFound = fmt.Sprintf("No services or container ports found matching port: %s across all contexts and namespaces", port)
 	}
-	return fmt.Sprintf("%d", targetPort.IntVal)
+	return displayIPResults(ctx, config, results, notFound)
+}
+
+// ValidateUID is a wrapper for k8s.ValidateUID for use in CLI
+func ValidateUID(uid string) bool {
+	return k8s.ValidateUID(uid)
+}
+
+// contextEntry is a kubeconfig context as listed by the ctx command
+type contextEntry struct {
+	Name      string `json:"name"`
+	Current   bool   `json:"current"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
 }
 
-// ListK8sContexts lists all contexts in kubeconfig
-func ListK8sContexts(kubeconfigPath string) error {
-	config, err := k8s.LoadKubeConfig(kubeconfigPath)
+// ListK8sContexts lists all contexts from kubeconfig as a table or, with --output json, as an array
+// of contexts with their cluster, user and namespace
+func ListK8sContexts(config K8sSearchConfig) error {
+	switch config.OutputFormat {
+	case "", OutputTable, OutputJSON:
+	default:
+		return fmt.Errorf("%w: ctx supports table and json output", k8s.ErrInvalidOptions)
+	}
+
+	kubeConfig, err := k8s.LoadKubeConfig(config.KubeconfigPath)
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to load kubeconfig: %v", err))
 		return err
 	}
 
-	contexts := k8s.GetContexts(config)
-	if len(contexts) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No contexts found in kubeconfig"))
+	entries := []contextEntry{}
+	for _, detail := range k8s.GetContextDetails(kubeConfig) {
+		entries = append(entries, contextEntry{
+			Name:      detail.Name,
+			Current:   detail.Name == kubeConfig.CurrentContext,
+			Cluster:   detail.Cluster,
+			User:      detail.User,
+			Namespace: detail.Namespace,
+		})
+	}
+	return writeContexts(config.out(), config, entries)
+}
+
+// writeContexts writes kubeconfig contexts as a table, or as json
+func writeContexts(w io.Writer, config K8sSearchConfig, entries []contextEntry) error {
+	if config.OutputFormat == OutputJSON {
+		return writeStructured(w, OutputJSON, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No contexts found in kubeconfig"))
 		return nil
 	}
 
 	tablex := table.Table{}
-	tablex.SetStyle(table.StyleLight)
+	tablex.SetStyle(tableStyle())
 	tablex.AppendRow(table.Row{"Context Name", "Current"})
 
-	for _, contextName := range contexts {
+	for _, entry := range entries {
 		isCurrent := ""
-		if contextName == config.CurrentContext {
+		if entry.Current {
 			isCurrent = "*"
 		}
-		tablex.AppendRow(table.Row{contextName, isCurrent})
+		tablex.AppendRow(table.Row{entry.Name, isCurrent})
 	}
 
-	fmt.Println(tablex.Render())
+	fmt.Fprintln(w, tablex.Render())
 	return nil
 }
 
-// SearchK8sByIP searches Kubernetes resources by IP address
-func SearchK8sByIP(config K8sSearchConfig, ip string) error {
-	// Validate IP
-	if !k8s.ValidateIP(ip) {
-		fmt.Println(text.FgRed.Sprintf("Invalid IP address: %s", ip))
-		return fmt.Errorf("invalid IP address: %s", ip)
-	}
-
-	// Create K8s client
-	client, err := k8s.NewK8sClient(config.KubeconfigPath, config.ContextName, config.Namespaces)
+// CurrentContext returns the current context of the kubeconfig, which --current restricts searches to
+func CurrentContext(kubeconfigPath string) (string, error) {
+	kubeConfig, err := k8s.LoadKubeConfig(kubeconfigPath)
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to create K8s client: %v", err))
-		return err
+		return "", err
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Search by IP
-	pods, services, err := client.SearchByIP(ctx, ip)
-	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to search by IP: %v", err))
-		return err
+	if kubeConfig.CurrentContext == "" {
+		return "", fmt.Errorf("%w: --current requires a current context, set one with kubectl config use-context", k8s.ErrInvalidOptions)
 	}
+	return kubeConfig.CurrentContext, nil
+}
 
-	// Display results
-	if len(pods) == 0 && len(services) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No resources found for IP: %s", ip))
-		return nil
+// resolveNamespaces returns the namespaces to search in the context of a single-context search: the
+// specified ones, every namespace (empty) with --all-namespaces, the namespace configured for the context
+// in kubeconfig like kubectl, or otherwise those discoverNamespaces finds
+func resolveNamespaces(config *K8sSearchConfig) []string {
+	if len(config.Namespaces) > 0 || config.AllNamespaces {
+		return config.Namespaces
 	}
 
-	// Display pods
-	if len(pods) > 0 {
-		fmt.Println(text.FgGreen.Sprintf("\n=== Pods matching IP: %s ===", ip))
-		podTable := table.Table{}
-		podTable.SetStyle(table.StyleLight)
-		podTable.AppendRow(table.Row{"Namespace", "Pod Name", "Pod IP", "Host IP", "Owner Kind", "Owner Name"})
-
-		for _, pod := range pods {
-			ownerInfo := fmt.Sprintf("%s", pod.OwnerName)
-			if pod.OwnerKind == "ReplicaSet" {
-				// Try to get deployment name
-				deploymentName, err := client.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName)
-				if err == nil {
-					ownerInfo = fmt.Sprintf("%s (Deployment: %s)", pod.OwnerName, deploymentName)
-				}
+	// A kubeconfig failing to load fails the search itself
+	if kubeConfig, err := k8s.LoadKubeConfig(config.KubeconfigPath); err == nil {
+		if namespace := k8s.ContextNamespace(kubeConfig, config.ContextName); namespace != "" {
+			if config.isVerbose() {
+				fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Using namespace %s configured for context %s (use -A or --namespaces to search others)\n", namespace, config.ContextName))
 			}
-
-			podTable.AppendRow(table.Row{
-				pod.Namespace,
-				pod.Name,
-				pod.PodIP,
-				pod.HostIP,
-				pod.OwnerKind,
-				ownerInfo,
-			})
+			return []string{namespace}
 		}
-		fmt.Println(podTable.Render())
 	}
 
-	// Display services
-	if len(services) > 0 {
-		fmt.Println(text.FgGreen.Sprintf("\n=== Services matching IP: %s ===", ip))
-		svcTable := table.Table{}
-		svcTable.SetStyle(table.StyleLight)
-		svcTable.AppendRow(table.Row{"Namespace", "Service Name", "Type", "Cluster IP", "External IPs", "Ports", "Selector"})
+	if config.isVerbose() {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No namespaces specified, attempting to discover accessible namespaces in context %s...", config.ContextName))
+	}
+	return discoverNamespaces(config, config.ContextName)
+}
 
-		for _, svc := range services {
-			ports := []string{}
-			for _, port := range svc.Ports {
-				ports = append(ports, fmt.Sprintf("%d:%s/%s", port.Port, formatTargetPort(port.TargetPort), port.Protocol))
-			}
+// discoverNamespaces returns the namespaces of contextName pods can be listed in for a search without
+// --namespaces, or none (= every namespace) when discovery fails. Other contexts search the namespaces found
+// too, unless their credentials can list pods in all namespaces, see k8s.SearchOptions.NamespacesDiscovered.
+// Discovery finding no accessible namespace is recorded in config for writeNoAccessNotice.
+func discoverNamespaces(config *K8sSearchConfig, contextName string) []string {
+	verbose := config.isVerbose()
+
+	accessible, err := accessibleNamespaces(config.KubeconfigPath, contextName, config.searchOptions())
+	if err == nil && len(accessible) > 0 {
+		config.namespacesDiscovered = true
+		if verbose {
+			fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Found %d accessible namespace(s): %s\n", len(accessible), strings.Join(accessible, ", ")))
+		}
+		return accessible
+	}
 
-			selector := []string{}
-			for k, v := range svc.Selector {
-				selector = append(selector, fmt.Sprintf("%s=%s", k, v))
-			}
+	// Searching all namespaces then usually finds nothing either when permissions are the problem
+	config.noAccessibleNamespaces = err == nil || k8s.IsPermissionError(err)
+	if verbose {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("Could not discover accessible namespaces, will try all namespaces...\n"))
+	}
+	return nil
+}
 
-			svcTable.AppendRow(table.Row{
-				svc.Namespace,
-				svc.Name,
-				svc.Type,
-				svc.ClusterIP,
-				strings.Join(svc.ExternalIPs, ", "),
-				strings.Join(ports, ", "),
-				strings.Join(selector, ", "),
-			})
+// validateIPQuery checks the query of an IP search is an IP address or CIDR range,
+// or with IPContains part of an IP address
+func validateIPQuery(config K8sSearchConfig, ip string) error {
+	if config.IPContains {
+		if !k8s.ValidateIPFragment(ip) {
+			return fmt.Errorf("%w: %s is not part of an IP address", k8s.ErrInvalidIP, ip)
 		}
-		fmt.Println(svcTable.Render())
+		return nil
+	}
+	if !k8s.ValidateIP(ip) && !k8s.ValidateCIDR(ip) {
+		return fmt.Errorf("%w: %s", k8s.ErrInvalidIP, ip)
 	}
-
 	return nil
 }
 
-// SearchK8sByName searches Kubernetes pods by name
+// SearchK8sByIP searches Kubernetes resources by IP address in the context given with --context,
+// in the namespaces resolveNamespaces picks for it
+func SearchK8sByIP(config K8sSearchConfig, ip string) error {
+	config.singleContext = true
+	return SearchK8sByIPAllContexts(config, ip)
+}
+
+// SearchK8sByName searches Kubernetes pods by name in the context given with --context,
+// in the namespaces resolveNamespaces picks for it
 func SearchK8sByName(config K8sSearchConfig, name string) error {
-	if name == "" {
-		fmt.Println(text.FgRed.Sprintf("Name cannot be empty"))
-		return fmt.Errorf("name cannot be empty")
-	}
+	config.singleContext = true
+	return SearchK8sByNameAllContexts(config, name)
+}
 
-	// Create K8s client
-	client, err := k8s.NewK8sClient(config.KubeconfigPath, config.ContextName, config.Namespaces)
-	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to create K8s client: %v", err))
+// SearchK8sByIPAllContexts searches Kubernetes resources by IP across all contexts and all (or specified) namespaces
+func SearchK8sByIPAllContexts(config K8sSearchConfig, ip string) error {
+	if err := validateIPQuery(config, ip); err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
 
-	// Search by name
-	pods, err := client.SearchByName(ctx, name)
-	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to search by name: %v", err))
-		return err
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
 	}
 
-	// Display results
-	if len(pods) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No pods found with name containing: %s", name))
-		return nil
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	kubeconfigPath := config.KubeconfigPath
+	namespaces := allContextsNamespaces(&config, "IP", ip)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
 	}
 
-	fmt.Println(text.FgGreen.Sprintf("\n=== Pods matching name: %s ===", name))
-	podTable := table.Table{}
-	podTable.SetStyle(table.StyleLight)
-	podTable.AppendRow(table.Row{"Namespace", "Pod Name", "Pod IP", "Host IP", "Owner Kind", "Owner Name"})
+	// Search across all contexts and namespaces
+	results, err := k8s.SearchByIPAllContexts(ctx, kubeconfigPath, ip, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
 
-	for _, pod := range pods {
-		ownerInfo := fmt.Sprintf("%s", pod.OwnerName)
-		if pod.OwnerKind == "ReplicaSet" {
-			// Try to get deployment name
-			deploymentName, err := client.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName)
-			if err == nil {
-				ownerInfo = fmt.Sprintf("%s (Deployment: %s)", pod.OwnerName, deploymentName)
-			}
-		}
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupIPResults(results)
+	}
 
-		podTable.AppendRow(table.Row{
-			pod.Namespace,
-			pod.Name,
-			pod.PodIP,
-			pod.HostIP,
-			pod.OwnerKind,
-			ownerInfo,
-		})
+	if !config.DetectDuplicates {
+		return displayIPResults(ctx, config, results, fmt.Sprintf("No resources found for IP: %s %s", ip, config.searchScope()))
 	}
-	fmt.Println(podTable.Render())
 
+	// Overlapping pod CIDRs show up as the same IP in several clusters
+	duplicates := k8s.FindDuplicateIPs(results, ip)
+	config.report.setDuplicates(duplicates)
+	if err := displayIPResults(ctx, config, results, fmt.Sprintf("No resources found for IP: %s %s", ip, config.searchScope())); err != nil {
+		return err
+	}
+	reportDuplicateIPs(config, duplicates)
 	return nil
 }
 
-// SearchK8sByIPAllContexts searches Kubernetes resources by IP across all contexts and all (or specified) namespaces
-func SearchK8sByIPAllContexts(kubeconfigPath string, ip string, namespaces []string) error {
-	// Validate IP
-	if !k8s.ValidateIP(ip) {
-		fmt.Println(text.FgRed.Sprintf("Failed to search: IP address is invalid: %s", ip))
-		return fmt.Errorf("invalid IP address: %s", ip)
+// SearchK8sByIPOrNameAllContexts searches Kubernetes resources by IP (when query is an IP or CIDR range) and pods by name
+// in one pass across all contexts and all (or specified) namespaces, listing pods found both ways once
+func SearchK8sByIPOrNameAllContexts(config K8sSearchConfig, query string) error {
+	if query == "" {
+		return fmt.Errorf("%w: query cannot be empty", k8s.ErrInvalidQuery)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
 	}
 
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	// If no namespaces specified, try to get accessible namespaces automatically
-	if len(namespaces) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No namespaces specified, attempting to discover accessible namespaces..."))
-		accessible, err := GetAccessibleNamespaces(kubeconfigPath, "")
-		if err == nil && len(accessible) > 0 {
-			namespaces = accessible
-			fmt.Println(text.FgCyan.Sprintf("Found %d accessible namespace(s): %s\n", len(namespaces), strings.Join(namespaces, ", ")))
-		} else {
-			fmt.Println(text.FgYellow.Sprintf("Could not discover accessible namespaces, will try all namespaces...\n"))
-		}
-	}
+	kubeconfigPath := config.KubeconfigPath
+	namespaces := allContextsNamespaces(&config, "IP or name", query)
 
-	if len(namespaces) > 0 {
-		fmt.Println(text.FgCyan.Sprintf("Searching in specified namespaces for IP: %s", ip))
-		fmt.Println(text.FgYellow.Sprintf("Namespaces: %s\n", strings.Join(namespaces, ", ")))
-	} else {
-		fmt.Println(text.FgCyan.Sprintf("Searching across all contexts and namespaces for IP: %s", ip))
-		fmt.Println(text.FgYellow.Sprintf("This may take a while...\n"))
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
 	}
 
 	// Search across all contexts and namespaces
-	results, err := k8s.SearchByIPAllContexts(ctx, kubeconfigPath, ip, namespaces)
+	results, err := k8s.SearchByIPOrNameAllContexts(ctx, kubeconfigPath, query, namespaces, config.contexts(), config.searchOptions())
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to search: %v", err))
-		return err
+		return fmt.Errorf("failed to search: %w", err)
 	}
 
-	// Display results
-	if len(results) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No resources found for IP: %s across all contexts and namespaces", ip))
-		return nil
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupIPResults(results)
 	}
 
-	totalPods := 0
-	totalServices := 0
-
-	for _, result := range results {
-		totalPods += len(result.Pods)
-		totalServices += len(result.Services)
-
-		// Display pods
-		if len(result.Pods) > 0 {
-			fmt.Println(text.FgGreen.Sprintf("\n=== Pods in Context: %s, Namespace: %s ===", result.Context, result.Namespace))
-			podTable := table.Table{}
-			podTable.SetStyle(table.StyleLight)
-			podTable.AppendRow(table.Row{"Pod Name", "Pod IP", "Host IP", "Owner Kind", "Owner Name"})
-
-			for _, pod := range result.Pods {
-				ownerInfo := pod.OwnerName
-				if pod.OwnerKind == "ReplicaSet" {
-					// Try to get deployment name
-					client, err := k8s.NewK8sClient(kubeconfigPath, result.Context, []string{result.Namespace})
-					if err == nil {
-						deploymentName, err := client.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName)
-						if err == nil {
-							ownerInfo = fmt.Sprintf("%s (Deployment: %s)", pod.OwnerName, deploymentName)
-						}
-					}
-				}
+	return displayIPResults(ctx, config, results, fmt.Sprintf("No resources found for IP or name: %s across all contexts and namespaces", query))
+}
 
-				podTable.AppendRow(table.Row{
-					pod.Name,
-					pod.PodIP,
-					pod.HostIP,
-					pod.OwnerKind,
-					ownerInfo,
-				})
-			}
-			fmt.Println(podTable.Render())
-		}
+// SearchK8sByNameAllContexts searches Kubernetes pods by name across all contexts and all (or specified) namespaces
+func SearchK8sByNameAllContexts(config K8sSearchConfig, name string) error {
+	return runAllContextsPodSearch(config, "name", name, func(ctx context.Context, namespaces []string, opts k8s.SearchOptions) ([]k8s.PodResultWithContext, error) {
+		return k8s.SearchByNameAllContexts(ctx, config.KubeconfigPath, name, namespaces, config.contexts(), opts)
+	}, fmt.Sprintf("No pods found %s: %s %s", config.nameMatch(), name, config.searchScope()))
+}
 
-		// Display services
-		if len(result.Services) > 0 {
-			fmt.Println(text.FgGreen.Sprintf("\n=== Services in Context: %s, Namespace: %s ===", result.Context, result.Namespace))
-			svcTable := table.Table{}
-			svcTable.SetStyle(table.StyleLight)
-			svcTable.AppendRow(table.Row{"Service Name", "Type", "Cluster IP", "External IPs", "Ports", "Selector"})
-
-			for _, svc := range result.Services {
-				ports := []string{}
-				for _, port := range svc.Ports {
-					ports = append(ports, fmt.Sprintf("%d:%s/%s", port.Port, formatTargetPort(port.TargetPort), port.Protocol))
-				}
+// SearchK8sByUIDAllContexts searches for a pod by UID across all contexts and all (or specified) namespaces
+func SearchK8sByUIDAllContexts(config K8sSearchConfig, uid string) error {
+	return runAllContextsPodSearch(config, "UID", uid, func(ctx context.Context, namespaces []string, opts k8s.SearchOptions) ([]k8s.PodResultWithContext, error) {
+		return k8s.SearchByUIDAllContexts(ctx, config.KubeconfigPath, uid, namespaces, config.contexts(), opts)
+	}, fmt.Sprintf("No pod found with UID: %s across all contexts and namespaces", uid))
+}
 
-				selector := []string{}
-				for k, v := range svc.Selector {
-					selector = append(selector, fmt.Sprintf("%s=%s", k, v))
-				}
+// SearchK8sByNodeAllContexts searches for the pods scheduled on a node across all contexts and namespaces
+func SearchK8sByNodeAllContexts(config K8sSearchConfig, nodeName string) error {
+	return runAllContextsPodSearch(config, "node", nodeName, func(ctx context.Context, namespaces []string, opts k8s.SearchOptions) ([]k8s.PodResultWithContext, error) {
+		return k8s.SearchByNodeAllContexts(ctx, config.KubeconfigPath, nodeName, namespaces, config.contexts(), opts)
+	}, fmt.Sprintf("No pod found on node: %s across all contexts and namespaces", nodeName))
+}
 
-				svcTable.AppendRow(table.Row{
-					svc.Name,
-					svc.Type,
-					svc.ClusterIP,
-					strings.Join(svc.ExternalIPs, ", "),
-					strings.Join(ports, ", "),
-					strings.Join(selector, ", "),
-				})
-			}
-			fmt.Println(svcTable.Render())
-		}
-	}
+// SearchK8sByServiceAccountAllContexts searches for the pods running as a service account across all contexts and namespaces
+func SearchK8sByServiceAccountAllContexts(config K8sSearchConfig, name string) error {
+	return runAllContextsPodSearch(config, "service account", name, func(ctx context.Context, namespaces []string, opts k8s.SearchOptions) ([]k8s.PodResultWithContext, error) {
+		return k8s.SearchByServiceAccountAllContexts(ctx, config.KubeconfigPath, name, namespaces, config.contexts(), opts)
+	}, fmt.Sprintf("No pod found running as service account: %s across all contexts and namespaces", name))
+}
 
-	fmt.Println(text.FgGreen.Sprintf("\n=== Summary ==="))
-	fmt.Printf("Total contexts searched: %d\n", len(results))
-	fmt.Printf("Total pods found: %d\n", totalPods)
-	fmt.Printf("Total services found: %d\n", totalServices)
+// runAllContextsPodSearch validates the options, resolves the namespaces and runs a pod search across all contexts,
+// dropping duplicates of contexts pointing at the same cluster before displaying the results or notFound.
+// search gets the options of the config reporting skipped contexts and timings, not those of the caller's copy.
+func runAllContextsPodSearch(config K8sSearchConfig, queryKind string, query string,
+	search func(ctx context.Context, namespaces []string, opts k8s.SearchOptions) ([]k8s.PodResultWithContext, error), notFound string) error {
+	if query == "" {
+		return fmt.Errorf("%w: %s cannot be empty", k8s.ErrInvalidQuery, queryKind)
+	}
 
-	return nil
-}
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
 
-// SearchK8sByNameAllContexts searches Kubernetes pods by name across all contexts and all (or specified) namespaces
-func SearchK8sByNameAllContexts(kubeconfigPath string, name string, namespaces []string) error {
-	if name == "" {
-		fmt.Println(text.FgRed.Sprintf("Name cannot be empty"))
-		return fmt.Errorf("name cannot be empty")
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
 	}
 
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
+	namespaces := allContextsNamespaces(&config, queryKind, query)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	// Search across all contexts and namespaces
+	results, err := search(ctx, namespaces, config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupPodResults(results)
+	}
+
+	return displayPodResults(ctx, config, results, notFound)
+}
+
+// writeContextRenames reports the contexts of kubeconfig files renamed when merging them
+// because an earlier file has a context of the same name
+func writeContextRenames(config K8sSearchConfig) {
+	renames, err := k8s.KubeconfigRenames(config.KubeconfigPath)
+	if err != nil {
+		return
+	}
+	for _, rename := range renames {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("Context %s of %s is searched as %s, an earlier kubeconfig file has a context of that name", rename.Context, rename.File, rename.Renamed))
+	}
+}
+
+// allContextsNamespaces returns the namespaces an all-contexts search should cover,
+// discovering the accessible ones when none were specified, see discoverNamespaces. The result is empty
+// (= every namespace of each context) with --all-namespaces. Single-context searches use resolveNamespaces.
+func allContextsNamespaces(config *K8sSearchConfig, queryKind string, query string) []string {
+	if config.singleContext {
+		return resolveNamespaces(config)
+	}
+
+	namespaces := config.Namespaces
+	verbose := config.isVerbose()
+
+	// Results use the names merging several kubeconfig files gave to colliding contexts
+	if verbose {
+		writeContextRenames(*config)
+	}
+
 	// If no namespaces specified, try to get accessible namespaces automatically
-	if len(namespaces) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No namespaces specified, attempting to discover accessible namespaces..."))
-		accessible, err := GetAccessibleNamespaces(kubeconfigPath, "")
-		if err == nil && len(accessible) > 0 {
-			namespaces = accessible
-			fmt.Println(text.FgCyan.Sprintf("Found %d accessible namespace(s): %s\n", len(namespaces), strings.Join(namespaces, ", ")))
-		} else {
-			fmt.Println(text.FgYellow.Sprintf("Could not discover accessible namespaces, will try all namespaces...\n"))
+	if len(namespaces) == 0 && !config.AllNamespaces {
+		if verbose {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No namespaces specified, attempting to discover accessible namespaces..."))
 		}
+		namespaces = discoverNamespaces(config, config.discoveryContext())
 	}
 
-	if len(namespaces) > 0 {
-		fmt.Println(text.FgCyan.Sprintf("Searching in specified namespaces for name: %s", name))
-		fmt.Println(text.FgYellow.Sprintf("Namespaces: %s\n", strings.Join(namespaces, ", ")))
-	} else {
-		fmt.Println(text.FgCyan.Sprintf("Searching across all contexts and namespaces for name: %s", name))
-		fmt.Println(text.FgYellow.Sprintf("This may take a while...\n"))
+	if verbose {
+		if len(namespaces) > 0 {
+			fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Searching in specified namespaces for %s: %s", queryKind, query))
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("Namespaces: %s\n", strings.Join(namespaces, ", ")))
+		} else {
+			fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Searching across all contexts and namespaces for %s: %s", queryKind, query))
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("This may take a while...\n"))
+		}
 	}
 
-	// Search across all contexts and namespaces
-	results, err := k8s.SearchByNameAllContexts(ctx, kubeconfigPath, name, namespaces)
+	return namespaces
+}
+
+// printSearchPlan prints the contexts and namespaces a search would scan instead of running it
+func printSearchPlan(config K8sSearchConfig, namespaces []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	plans, err := k8s.PlanSearch(ctx, config.KubeconfigPath, namespaces, config.contexts(), config.searchOptions())
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to search: %v", err))
-		return err
+		return fmt.Errorf("failed to plan search: %w", err)
+	}
+
+	if !config.isTableOutput() {
+		return writeStructured(config.out(), config.OutputFormat, plans)
 	}
 
-	// Display results
-	if len(results) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No pods found with name containing: %s across all contexts and namespaces", name))
+	if len(plans) == 0 {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No contexts or namespaces would be searched"))
 		return nil
 	}
 
-	totalPods := 0
+	planTable := table.Table{}
+	planTable.SetStyle(tableStyle())
+	planTable.AppendRow(table.Row{"Context", "Namespaces"})
+
+	total := 0
+	for _, plan := range plans {
+		planTable.AppendRow(table.Row{plan.Context, strings.Join(plan.Namespaces, ", ")})
+		total += len(plan.Namespaces)
+	}
+
+	fmt.Fprintln(config.out(), text.FgGreen.Sprintf("\n=== Dry run: would search %d namespace(s) in %d context(s) ===", total, len(plans)))
+	fmt.Fprintln(config.out(), planTable.Render())
+	return nil
+}
 
-	for _, result := range results {
-		totalPods += len(result.Pods)
+// displayIPResults prints pod and service search results from all contexts in the configured output format
+func displayIPResults(ctx context.Context, config K8sSearchConfig, results []k8s.SearchResultWithContext, notFound string) error {
+	config.resultSort().SortIPResults(results)
+	defer writeTruncationNotice(config, summarizeIPResults(results).total())
+	defer writeNoAccessNotice(config, summarizeIPResults(results).total())
+	defer writeSummaryJSON(config, summarizeIPResults(results))
+	defer config.history.setMatches(summarizeIPResults(results))
 
-		fmt.Println(text.FgGreen.Sprintf("\n=== Pods in Context: %s, Namespace: %s ===", result.Context, result.Namespace))
-		podTable := table.Table{}
-		podTable.SetStyle(table.StyleLight)
-		podTable.AppendRow(table.Row{"Pod Name", "Pod IP", "Host IP", "Owner Kind", "Owner Name"})
+	if config.CountOnly {
+		return writeSummary(config, summarizeIPResults(results))
+	}
 
-		for _, pod := range result.Pods {
-			ownerInfo := fmt.Sprintf("%s", pod.OwnerName)
-			if pod.OwnerKind == "ReplicaSet" {
-				// Try to get deployment name
-				client, err := k8s.NewK8sClient(kubeconfigPath, result.Context, []string{result.Namespace})
-				if err == nil {
-					deploymentName, err := client.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName)
-					if err == nil {
-						ownerInfo = fmt.Sprintf("%s (Deployment: %s)", pod.OwnerName, deploymentName)
-					}
-				}
+	// Results without pods, e.g. port or service lookups, have nothing to aggregate and are shown as usual
+	if config.Aggregate && summarizeIPResults(results).Pods > 0 {
+		return displayAggregate(ctx, config, ipPodResults(results), notFound)
+	}
+
+	// A custom renderer takes over all output, including empty results
+	if config.Renderer == nil && config.isTableOutput() {
+		// Display results
+		if len(results) == 0 {
+			if !config.Quiet {
+				fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
 			}
+			return nil
+		}
 
-			podTable.AppendRow(table.Row{
-				pod.Name,
-				pod.PodIP,
-				pod.HostIP,
-				pod.OwnerKind,
-				ownerInfo,
-			})
+		if config.Interactive {
+			return selectIPResult(ctx, config, results)
 		}
-		fmt.Println(podTable.Render())
 	}
 
-	fmt.Println(text.FgGreen.Sprintf("\n=== Summary ==="))
-	fmt.Printf("Total contexts searched: %d\n", len(results))
-	fmt.Printf("Total pods found: %d\n", totalPods)
+	if err := config.renderer(ctx).RenderIPResults(config.out(), results); err != nil {
+		return err
+	}
+	return runPodCommands(config, ipPodResults(results))
+}
 
-	return nil
+// displayPodResults prints pod search results from all contexts in the configured output format
+func displayPodResults(ctx context.Context, config K8sSearchConfig, results []k8s.PodResultWithContext, notFound string) error {
+	config.resultSort().SortPodResults(results)
+	defer writeTruncationNotice(config, summarizePodResults(results).total())
+	defer writeNoAccessNotice(config, summarizePodResults(results).total())
+	defer writeSummaryJSON(config, summarizePodResults(results))
+	defer config.history.setMatches(summarizePodResults(results))
+
+	if config.CountOnly {
+		return writeSummary(config, summarizePodResults(results))
+	}
+
+	if config.Aggregate {
+		return displayAggregate(ctx, config, results, notFound)
+	}
+
+	// A custom renderer takes over all output, including empty results
+	if config.Renderer == nil && config.isTableOutput() {
+		// Display results
+		if len(results) == 0 {
+			if !config.Quiet {
+				fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
+			}
+			return nil
+		}
+
+		if config.Interactive {
+			return selectPodResult(ctx, config, results)
+		}
+	}
+
+	if err := config.renderer(ctx).RenderPodResults(config.out(), results); err != nil {
+		return err
+	}
+	return runPodCommands(config, results)
 }
 
-// ListK8sNamespaces lists all namespaces and shows which ones you have permission to access
-func ListK8sNamespaces(kubeconfigPath string, contextName string) error {
+// namespaceAccess is whether pods can be listed in a namespace, as listed by the ns command
+type namespaceAccess struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	HasAccess bool   `json:"hasAccess"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ListK8sNamespaces lists all namespaces and shows which ones you have permission to access,
+// as a table or, with --output json, as an array of namespaces. onlyWithAccess leaves out the
+// namespaces you cannot access, still counting them in the summary.
+func ListK8sNamespaces(config K8sSearchConfig, onlyWithAccess bool) error {
+	switch config.OutputFormat {
+	case "", OutputTable, OutputJSON:
+	default:
+		return fmt.Errorf("%w: ns supports table and json output", k8s.ErrInvalidOptions)
+	}
+
 	// Create K8s client
-	client, err := k8s.NewK8sClient(kubeconfigPath, contextName, []string{})
+	client, err := k8s.NewK8sClientWithOptions(config.KubeconfigPath, config.ContextName, []string{}, probeOptions(config.searchOptions()))
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to create K8s client: %v", err))
-		return err
+		return fmt.Errorf("failed to create K8s client: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	// Get current context name
+	contextName := config.ContextName
 	if contextName == "" {
-		config, err := k8s.LoadKubeConfig(kubeconfigPath)
+		kubeConfig, err := k8s.LoadKubeConfig(config.KubeconfigPath)
 		if err == nil {
-			contextName = config.CurrentContext
+			contextName = kubeConfig.CurrentContext
 		}
 	}
 
-	fmt.Println(text.FgCyan.Sprintf("Listing namespaces from context: %s\n", contextName))
+	if config.isVerbose() {
+		fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Listing namespaces from context: %s\n", contextName))
+	}
 
 	// Get all namespaces
 	namespaceList, err := client.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		fmt.Println(text.FgRed.Sprintf("Failed to list namespaces: %v", err))
-		return err
+		return fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
-	if len(namespaceList.Items) == 0 {
-		fmt.Println(text.FgYellow.Sprintf("No namespaces found"))
-		return nil
+	// Check permissions for each namespace, several at once
+	names := make([]string, 0, len(namespaceList.Items))
+	phases := map[string]string{}
+	for _, ns := range namespaceList.Items {
+		names = append(names, ns.Name)
+		phases[ns.Name] = string(ns.Status.Phase)
 	}
-
-	// Check permissions for each namespace
-	type NamespacePermission struct {
-		Name      string
-		HasAccess bool
-		Status    string
-		Error     string
+	probed := client.ProbeNamespaceAccess(ctx, names, namespaceProbeConcurrency)
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to check access to namespaces: %w", ctx.Err())
 	}
 
-	permissions := []NamespacePermission{}
-
-	for _, ns := range namespaceList.Items {
-		perm := NamespacePermission{
-			Name:   ns.Name,
-			Status: string(ns.Status.Phase),
+	permissions := []namespaceAccess{}
+	for _, access := range probed {
+		perm := namespaceAccess{
+			Name:      access.Namespace,
+			Status:    phases[access.Namespace],
+			HasAccess: access.HasAccess,
 		}
-
-		// Try to list pods to check permission
-		_, err := client.Clientset.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{Limit: 1})
-		if err != nil {
-			perm.HasAccess = false
-			if k8s.IsPermissionError(err) {
+		if access.Err != nil {
+			if k8s.IsPermissionError(access.Err) {
 				perm.Error = "Permission Denied"
 			} else {
-				perm.Error = err.Error()
+				perm.Error = access.Err.Error()
 			}
-		} else {
-			perm.HasAccess = true
 		}
-
 		permissions = append(permissions, perm)
 	}
 
+	return writeNamespaceAccess(config.out(), config, permissions, onlyWithAccess)
+}
+
+// writeNamespaceAccess writes namespace permissions as a table with a summary, or as json,
+// leaving out denied namespaces but for the summary with onlyWithAccess
+func writeNamespaceAccess(w io.Writer, config K8sSearchConfig, permissions []namespaceAccess, onlyWithAccess bool) error {
+	if config.OutputFormat == OutputJSON {
+		if !onlyWithAccess {
+			return writeStructured(w, OutputJSON, permissions)
+		}
+		accessible := []namespaceAccess{}
+		for _, perm := range permissions {
+			if perm.HasAccess {
+				accessible = append(accessible, perm)
+			}
+		}
+		return writeStructured(w, OutputJSON, accessible)
+	}
+
+	if len(permissions) == 0 {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No namespaces found"))
+		return nil
+	}
+
 	// Display results in table
 	tablex := table.Table{}
-	tablex.SetStyle(table.StyleLight)
+	tablex.SetStyle(tableStyle())
 	tablex.AppendRow(table.Row{"Namespace", "Status", "Access", "Notes"})
 
 	accessibleCount := 0
@@ -507,6 +890,9 @@ func ListK8sNamespaces(kubeconfigPath string, contextName string) error {
 			accessStatus = text.FgRed.Sprint("✗ Denied")
 			notes = perm.Error
 			deniedCount++
+			if onlyWithAccess {
+				continue
+			}
 		}
 
 		tablex.AppendRow(table.Row{
@@ -517,13 +903,17 @@ func ListK8sNamespaces(kubeconfigPath string, contextName string) error {
 		})
 	}
 
-	fmt.Println(tablex.Render())
+	if accessibleCount > 0 || !onlyWithAccess {
+		fmt.Fprintln(w, tablex.Render())
+	} else {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No accessible namespaces found"))
+	}
 
 	// Summary
-	fmt.Println(text.FgGreen.Sprintf("\n=== Summary ==="))
-	fmt.Printf("Total namespaces: %d\n", len(permissions))
-	fmt.Printf("Accessible: %d\n", accessibleCount)
-	fmt.Printf("Denied: %d\n", deniedCount)
+	fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Summary ==="))
+	fmt.Fprintf(w, "Total namespaces: %d\n", len(permissions))
+	fmt.Fprintf(w, "Accessible: %d\n", accessibleCount)
+	fmt.Fprintf(w, "Denied: %d\n", deniedCount)
 
 	if accessibleCount > 0 {
 		// Show accessible namespaces as comma-separated list
@@ -533,17 +923,38 @@ func ListK8sNamespaces(kubeconfigPath string, contextName string) error {
 				accessible = append(accessible, perm.Name)
 			}
 		}
-		fmt.Println(text.FgCyan.Sprintf("\nAccessible namespaces (for use with --namespaces flag):"))
-		fmt.Println(strings.Join(accessible, ","))
+		if config.isVerbose() {
+			fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("\nAccessible namespaces (for use with --namespaces flag):"))
+		}
+		fmt.Fprintln(w, strings.Join(accessible, ","))
 	}
 
 	return nil
 }
 
+// namespaceProbeConcurrency is the number of namespaces whose access is probed at once
+const namespaceProbeConcurrency = 16
+
+// probeOptions returns the search options for a client probing namespaces, raising the client-side
+// rate limit to let the probes run in parallel unless --qps or --burst set it
+func probeOptions(opts k8s.SearchOptions) k8s.SearchOptions {
+	if opts.QPS == 0 && opts.Burst == 0 {
+		opts.QPS = 50
+		opts.Burst = 100
+	}
+	return opts
+}
+
 // GetAccessibleNamespaces returns a list of namespaces the user has permission to access
 func GetAccessibleNamespaces(kubeconfigPath string, contextName string) ([]string, error) {
+	return accessibleNamespaces(kubeconfigPath, contextName, k8s.SearchOptions{})
+}
+
+// accessibleNamespaces returns the sorted namespaces of a context pods can be listed in, probing
+// namespaceProbeConcurrency namespaces at once
+func accessibleNamespaces(kubeconfigPath string, contextName string, opts k8s.SearchOptions) ([]string, error) {
 	// Create K8s client
-	client, err := k8s.NewK8sClient(kubeconfigPath, contextName, []string{})
+	client, err := k8s.NewK8sClientWithOptions(kubeconfigPath, contextName, []string{}, probeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -557,18 +968,25 @@ func GetAccessibleNamespaces(kubeconfigPath string, contextName string) ([]strin
 		return nil, err
 	}
 
-	accessible := []string{}
-
-	// Check permissions for each namespace
+	names := make([]string, 0, len(namespaceList.Items))
 	for _, ns := range namespaceList.Items {
-		// Try to list pods to check permission
-		_, err := client.Clientset.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{Limit: 1})
-		if err == nil {
-			// Has access
-			accessible = append(accessible, ns.Name)
-		}
-		// Skip namespaces without access (silently)
+		names = append(names, ns.Name)
+	}
+
+	// Credentials that can list pods in all namespaces can access each of them, so none needs a probe
+	if clusterWide, _ := client.CanListAllNamespaces(ctx); clusterWide {
+		return names, nil
 	}
 
+	// Skip namespaces without access (silently)
+	accessible := []string{}
+	for _, access := range client.ProbeNamespaceAccess(ctx, names, namespaceProbeConcurrency) {
+		if access.HasAccess {
+			accessible = append(accessible, access.Namespace)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to check access to namespaces: %w", ctx.Err())
+	}
 	return accessible, nil
 }
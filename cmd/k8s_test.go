@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolveNamespacesFromContext tests that single-context searches default to the namespace configured for the context
+func TestResolveNamespacesFromContext(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+    namespace: web
+  name: prod
+current-context: prod
+users:
+- name: test-user
+  user:
+    token: test-token
+`), 0644))
+
+	var errOut bytes.Buffer
+	config := K8sSearchConfig{KubeconfigPath: kubeconfigPath, ContextName: "prod", Err: &errOut}
+	assert.Equal(t, []string{"web"}, resolveNamespaces(&config))
+	assert.Contains(t, errOut.String(), "Using namespace web configured for context prod")
+
+	// Namespaces given with --namespaces take precedence
+	config.Namespaces = []string{"default", "kube-system"}
+	assert.Equal(t, []string{"default", "kube-system"}, resolveNamespaces(&config))
+
+	// --all-namespaces searches every namespace
+	config.Namespaces = nil
+	config.AllNamespaces = true
+	assert.Empty(t, resolveNamespaces(&config))
+}
+
+// TestWriteNamespaceAccessGolden tests the ns command output in table and json output, with and without --only-with-access
+func TestWriteNamespaceAccessGolden(t *testing.T) {
+	permissions := []namespaceAccess{
+		{Name: "default", Status: "Active", HasAccess: true},
+		{Name: "kube-system", Status: "Active", Error: "Permission Denied"},
+		{Name: "web", Status: "Terminating", HasAccess: true},
+	}
+
+	tests := []struct {
+		golden         string
+		format         string
+		onlyWithAccess bool
+	}{
+		{"namespaces_table.golden", OutputTable, false},
+		{"namespaces_json.golden", OutputJSON, false},
+		{"namespaces_with_access_table.golden", OutputTable, true},
+		{"namespaces_with_access_json.golden", OutputJSON, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			config := K8sSearchConfig{OutputFormat: tt.format, Err: &bytes.Buffer{}}
+			require.NoError(t, writeNamespaceAccess(&buf, config, permissions, tt.onlyWithAccess))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+
+	// --quiet leaves the list of accessible namespaces without its label
+	var buf, errOut bytes.Buffer
+	config := K8sSearchConfig{Quiet: true, Err: &errOut}
+	require.NoError(t, writeNamespaceAccess(&buf, config, permissions, false))
+	assert.Contains(t, buf.String(), "default,web")
+	assert.Empty(t, errOut.String())
+}
+
+// TestWriteContextsGolden tests the ctx command output in table and json output
+func TestWriteContextsGolden(t *testing.T) {
+	entries := []contextEntry{
+		{Name: "dev", Cluster: "dev-cluster", User: "dev-user"},
+		{Name: "prod", Current: true, Cluster: "prod-cluster", User: "admin", Namespace: "web"},
+	}
+
+	tests := []struct {
+		golden string
+		format string
+	}{
+		{"contexts_table.golden", OutputTable},
+		{"contexts_json.golden", OutputJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeContexts(&buf, K8sSearchConfig{OutputFormat: tt.format}, entries))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestNoAccessNotice tests warning that an empty result is likely due to RBAC when no namespace is accessible
+func TestNoAccessNotice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+	}))
+	defer server.Close()
+
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	var errOut bytes.Buffer
+	config := K8sSearchConfig{KubeconfigPath: kubeconfigPath, Err: &errOut, Quiet: true}
+	assert.Empty(t, allContextsNamespaces(&config, "name", "nginx"))
+	assert.True(t, config.noAccessibleNamespaces)
+
+	writeNoAccessNotice(config, 1)
+	assert.Empty(t, errOut.String())
+	writeNoAccessNotice(config, 0)
+	assert.Contains(t, errOut.String(), "likely due to missing RBAC permissions")
+
+	// Namespaces given explicitly are not discovered
+	errOut.Reset()
+	config = K8sSearchConfig{KubeconfigPath: kubeconfigPath, Err: &errOut, Namespaces: []string{"default"}}
+	allContextsNamespaces(&config, "name", "nginx")
+	writeNoAccessNotice(config, 0)
+	assert.NotContains(t, errOut.String(), "RBAC")
+}
+
+// writeServerKubeconfig writes a kubeconfig with a single context prod pointing at server
+func writeServerKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: `+server+`
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: prod
+current-context: prod
+users:
+- name: test-user
+  user:
+    token: test-token
+`), 0644))
+	return kubeconfigPath
+}
+
+// TestCurrentContext tests resolving the context --current restricts searches to
+func TestCurrentContext(t *testing.T) {
+	current, err := CurrentContext(writeServerKubeconfig(t, "https://127.0.0.1:6443"))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current)
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0644))
+	_, err = CurrentContext(kubeconfigPath)
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+}
+
+// TestFirst tests that --first stops the search at the first match without a truncation notice
+func TestFirst(t *testing.T) {
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		namespace := filepath.Base(filepath.Dir(r.URL.Path))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[`+
+			`{"metadata":{"name":"web-1","namespace":"`+namespace+`"}},`+
+			`{"metadata":{"name":"web-2","namespace":"`+namespace+`"}}]}`)
+	}))
+	defer server.Close()
+
+	var out, errOut bytes.Buffer
+	config := K8sSearchConfig{
+		KubeconfigPath: writeServerKubeconfig(t, server.URL),
+		Namespaces:     []string{"a", "b"},
+		OutputFormat:   OutputJSONL,
+		First:          true,
+		Out:            &out,
+		Err:            &errOut,
+	}
+	assert.Equal(t, 1, config.searchOptions().MaxResults)
+
+	require.NoError(t, SearchK8sByNameAllContexts(config, "web"))
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), `"name":"web-1"`)
+	assert.Equal(t, []string{"/api/v1/namespaces/a/pods"}, requested)
+	assert.NotContains(t, errOut.String(), "truncated")
+}
+
+// TestIPContains tests validating queries matched as part of an IP with --ip-contains
+func TestIPContains(t *testing.T) {
+	config := K8sSearchConfig{IPContains: true}
+	assert.NoError(t, validateIPQuery(config, ".137"))
+	assert.ErrorIs(t, validateIPQuery(config, "web"), k8s.ErrInvalidIP)
+	assert.ErrorIs(t, validateIPQuery(K8sSearchConfig{}, ".137"), k8s.ErrInvalidIP)
+	assert.NoError(t, validateIPQuery(K8sSearchConfig{}, "10.0.0.0/24"))
+	assert.True(t, config.searchOptions().IPContains)
+
+	config.DetectDuplicates = true
+	err := validateOutput(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--ip-contains cannot be combined")
+}
+
+// TestSort tests ordering the pods of each result with --sort, and rejecting unknown keys
+func TestSort(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{OutputFormat: OutputJSONL, Sort: "name", Out: &out, Err: &bytes.Buffer{}}
+	require.NoError(t, validateOutput(config))
+
+	require.NoError(t, displayPodResults(context.Background(), config, fixturePodResults(), "not found"))
+	debug := strings.Index(out.String(), `"name":"debug"`)
+	nginx := strings.Index(out.String(), `"name":"nginx-7d9c-abcde"`)
+	require.True(t, debug >= 0 && nginx >= 0)
+	assert.Less(t, debug, nginx)
+
+	for _, invalid := range []K8sSearchConfig{
+		{Sort: "ip"},
+		{Sort: "age", Aggregate: true},
+		{Sort: "age", Kind: "deployment"},
+	} {
+		assert.Error(t, validateOutput(invalid), "config %+v", invalid)
+	}
+	assert.NoError(t, validateOutput(K8sSearchConfig{Sort: "-podip", Kind: "svc"}))
+}
+
+// TestMergedKubeconfigs tests searching the contexts of several kubeconfig files,
+// reporting the context renamed because both files name it prod
+func TestMergedKubeconfigs(t *testing.T) {
+	newServer := func(pod string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"`+pod+`","namespace":"default"}}]}`)
+		}))
+	}
+	main, oneoff := newServer("web-main"), newServer("web-oneoff")
+	defer main.Close()
+	defer oneoff.Close()
+
+	var out, errOut bytes.Buffer
+	config := K8sSearchConfig{
+		KubeconfigPath: writeServerKubeconfig(t, main.URL) + "," + writeServerKubeconfig(t, oneoff.URL),
+		Namespaces:     []string{"default"},
+		NoDedup:        true,
+		Out:            &out,
+		Err:            &errOut,
+	}
+	require.NoError(t, SearchK8sByNameAllContexts(config, "web"))
+	assert.Contains(t, errOut.String(), "is searched as prod@kubeconfig")
+	assert.Contains(t, out.String(), "Context: prod ("+main.URL+")")
+	assert.Contains(t, out.String(), "Context: prod@kubeconfig ("+oneoff.URL+")")
+	assert.Contains(t, out.String(), "web-oneoff")
+}
+
+// TestClusterWideSearch tests that searches without --namespaces skip probing each namespace and list
+// pods once across all namespaces when the credentials allow it, and discover namespaces otherwise
+func TestClusterWideSearch(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		clusterWide bool
+	}{
+		{"cluster-wide", true},
+		{"forbidden", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := map[string]int{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests[r.URL.Path]++
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/api/v1/pods" && !tt.clusterWide:
+					w.WriteHeader(http.StatusForbidden)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+				case r.URL.Path == "/api/v1/namespaces":
+					fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[`+
+						`{"metadata":{"name":"a"}},{"metadata":{"name":"b"}},{"metadata":{"name":"c"}}]}`)
+				case r.URL.Path == "/api/v1/pods" || r.URL.Path == "/api/v1/namespaces/a/pods":
+					fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"web-1","namespace":"a"}}]}`)
+				default:
+					fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+				}
+			}))
+			defer server.Close()
+
+			var out bytes.Buffer
+			config := K8sSearchConfig{
+				KubeconfigPath: writeServerKubeconfig(t, server.URL),
+				OutputFormat:   OutputJSONL,
+				Out:            &out,
+				Err:            &bytes.Buffer{},
+			}
+			require.NoError(t, SearchK8sByNameAllContexts(config, "web"))
+			assert.Equal(t, 1, strings.Count(out.String(), `"name":"web-1"`))
+
+			perNamespace := 0
+			for path, count := range requests {
+				if strings.HasPrefix(path, "/api/v1/namespaces/") {
+					perNamespace += count
+				}
+			}
+			if tt.clusterWide {
+				// Discovery lists the namespaces without probing each, then the access check of the context
+				// and the search list pods across namespaces
+				assert.Equal(t, map[string]int{"/api/v1/namespaces": 1, "/api/v1/pods": 3}, requests)
+			} else {
+				// The access checks of discovery and of the context, and one probe and one search of each namespace
+				assert.Equal(t, 2, requests["/api/v1/pods"])
+				assert.Equal(t, 6, perNamespace)
+			}
+		})
+	}
+}
+
+// TestClusterWideSearchPerContext tests that each context decides for itself whether to list pods across
+// namespaces, whatever the credentials of the context namespaces are discovered in allow
+func TestClusterWideSearchPerContext(t *testing.T) {
+	newServer := func(clusterWide bool, pod string, perNamespace *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/") {
+				perNamespace.Add(1)
+			}
+			switch {
+			case r.URL.Path == "/api/v1/pods" && !clusterWide:
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+			case r.URL.Path == "/api/v1/namespaces":
+				fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"a"}},{"metadata":{"name":"b"}}]}`)
+			case r.URL.Path == "/api/v1/pods" || r.URL.Path == "/api/v1/namespaces/a/pods":
+				fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"`+pod+`","namespace":"a"}}]}`)
+			default:
+				fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+			}
+		}))
+	}
+	var restrictedRequests, adminRequests atomic.Int32
+	restricted, admin := newServer(false, "web-restricted", &restrictedRequests), newServer(true, "web-admin", &adminRequests)
+	defer restricted.Close()
+	defer admin.Close()
+
+	// Namespaces are discovered in the current context, the restricted one
+	var out bytes.Buffer
+	config := K8sSearchConfig{
+		KubeconfigPath: writeServerKubeconfig(t, restricted.URL) + "," + writeServerKubeconfig(t, admin.URL),
+		OutputFormat:   OutputJSONL,
+		Out:            &out,
+		Err:            &bytes.Buffer{},
+	}
+	require.NoError(t, SearchK8sByNameAllContexts(config, "web"))
+	assert.Contains(t, out.String(), `"name":"web-restricted"`)
+	assert.Contains(t, out.String(), `"name":"web-admin"`)
+
+	// One probe and one search of each namespace in the restricted context, none in the other
+	assert.Equal(t, int32(4), restrictedRequests.Load())
+	assert.Zero(t, adminRequests.Load())
+}
+
+// TestSingleContextSearch tests that searches of the context given with --context report failing namespaces
+// and show the summary like all-contexts searches
+func TestSingleContextSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/a/pods":
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"web-1","namespace":"a"},"status":{"podIP":"10.0.0.5"}}]}`)
+		case "/api/v1/namespaces/b/pods":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"etcd unavailable","code":500}`)
+		case "/api/v1/namespaces/a/services", "/api/v1/namespaces/b/services":
+			fmt.Fprint(w, `{"kind":"ServiceList","apiVersion":"v1","metadata":{},"items":[]}`)
+		default:
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	for _, search := range []func(K8sSearchConfig) error{
+		func(config K8sSearchConfig) error { return SearchK8sByName(config, "web") },
+		func(config K8sSearchConfig) error { return SearchK8sByIP(config, "10.0.0.5") },
+	} {
+		var out, errOut bytes.Buffer
+		config := K8sSearchConfig{
+			KubeconfigPath: writeServerKubeconfig(t, server.URL),
+			ContextName:    "prod",
+			Namespaces:     []string{"a", "b"},
+			Out:            &out,
+			Err:            &errOut,
+		}
+		require.NoError(t, search(config))
+		assert.Contains(t, out.String(), "web-1")
+		assert.Contains(t, out.String(), "=== Summary ===")
+		assert.Contains(t, errOut.String(), "Failed to search namespace b in context prod")
+	}
+
+	var errOut bytes.Buffer
+	config := K8sSearchConfig{KubeconfigPath: writeServerKubeconfig(t, server.URL), ContextName: "prod", Namespaces: []string{"a"}, Err: &errOut, Out: &bytes.Buffer{}}
+	require.NoError(t, SearchK8sByName(config, "db"))
+	assert.Contains(t, errOut.String(), "No pods found")
+	assert.Contains(t, errOut.String(), "db in context prod")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prometheusMetrics records search metrics as Prometheus metrics
+type prometheusMetrics struct {
+	searchDuration    *prometheus.HistogramVec
+	namespacesScanned *prometheus.CounterVec
+	podsMatched       *prometheus.CounterVec
+	servicesMatched   *prometheus.CounterVec
+	permissionDenied  *prometheus.CounterVec
+}
+
+// newPrometheusMetrics creates the search metrics and registers them with registerer
+func newPrometheusMetrics(registerer prometheus.Registerer) *prometheusMetrics {
+	m := &prometheusMetrics{
+		searchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "k8sx_context_search_duration_seconds",
+			Help:    "Time spent searching a single context.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"context"}),
+		namespacesScanned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sx_namespaces_scanned_total",
+			Help: "Number of namespaces scanned.",
+		}, []string{"context"}),
+		podsMatched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sx_pods_matched_total",
+			Help: "Number of pods matching a search.",
+		}, []string{"context"}),
+		servicesMatched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sx_services_matched_total",
+			Help: "Number of services matching a search.",
+		}, []string{"context"}),
+		permissionDenied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sx_permission_denied_skips_total",
+			Help: "Number of namespace lists skipped because of missing permissions.",
+		}, []string{"context"}),
+	}
+
+	registerer.MustRegister(m.searchDuration, m.namespacesScanned, m.podsMatched, m.servicesMatched, m.permissionDenied)
+	return m
+}
+
+func (m *prometheusMetrics) ObserveContextSearch(contextName string, duration time.Duration) {
+	m.searchDuration.WithLabelValues(contextName).Observe(duration.Seconds())
+}
+
+func (m *prometheusMetrics) AddNamespacesScanned(contextName string, count int) {
+	m.namespacesScanned.WithLabelValues(contextName).Add(float64(count))
+}
+
+func (m *prometheusMetrics) AddMatches(contextName string, pods int, services int) {
+	m.podsMatched.WithLabelValues(contextName).Add(float64(pods))
+	m.servicesMatched.WithLabelValues(contextName).Add(float64(services))
+}
+
+func (m *prometheusMetrics) IncPermissionDenied(contextName string) {
+	m.permissionDenied.WithLabelValues(contextName).Inc()
+}
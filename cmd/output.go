@@ -0,0 +1,586 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
+)
+
+// Supported output formats for search results
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputCSV   = "csv"
+	OutputJSONL = "jsonl"
+	OutputGraph = "graph"
+	// OutputTemplate applies the Go template of --template to the results
+	OutputTemplate = "template"
+)
+
+var (
+	podCSVHeader     = []string{"Context", "Namespace", "Pod Name", "Pod IP", "Host IP", "Owner Kind", "Owner Name", "Matched On", "Node"}
+	serviceCSVHeader = []string{"Context", "Namespace", "Service Name", "Type", "Cluster IP", "External IPs", "Ports", "Node Ports", "Selector", "External Name", "Matched On"}
+)
+
+// stdoutIsTerminal reports whether stdout is a terminal, which compressed output would garble
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// validateOutput checks the output format and output directory combination
+func validateOutput(config K8sSearchConfig) error {
+	switch config.OutputFormat {
+	case "", OutputTable, OutputWide, OutputJSON, OutputYAML, OutputCSV, OutputJSONL, OutputGraph, OutputTemplate:
+	default:
+		return fmt.Errorf("unsupported output format: %s (supported: table, wide, json, yaml, csv, jsonl, graph, template)", config.OutputFormat)
+	}
+
+	if config.OutputFormat == OutputTemplate {
+		if _, err := parseOutputTemplate(config.Template); err != nil {
+			return err
+		}
+		if config.CountOnly || config.Aggregate || config.DryRun {
+			return fmt.Errorf("--output template cannot be combined with --count, --aggregate or --dry-run")
+		}
+	} else if config.Template != "" {
+		return fmt.Errorf("--template requires --output template")
+	}
+
+	if config.OutputFormat == OutputGraph && (config.CountOnly || config.Aggregate || config.DryRun || (config.Kind != "" && !k8s.IsServiceKind(config.Kind))) {
+		return fmt.Errorf("--output graph cannot be combined with --count, --aggregate, --dry-run or a workload --kind")
+	}
+
+	if config.OutputDir != "" && config.OutputFormat != OutputCSV {
+		return fmt.Errorf("--output-dir is only supported with --output csv")
+	}
+	if config.OutputDir != "" && config.CountOnly {
+		return fmt.Errorf("--output-dir cannot be combined with --count")
+	}
+	if config.Interactive && (!config.isTableOutput() || config.CountOnly) {
+		return fmt.Errorf("--interactive cannot be combined with --output or --count")
+	}
+	if config.Aggregate && (config.Interactive || config.CountOnly || config.OutputDir != "") {
+		return fmt.Errorf("--aggregate cannot be combined with --interactive, --count or --output-dir")
+	}
+	if config.Kind != "" && (config.Interactive || config.Aggregate) {
+		return fmt.Errorf("--kind cannot be combined with --interactive or --aggregate")
+	}
+	if config.Resource != "" && (config.Kind != "" || config.Interactive || config.Aggregate || config.Exec != "" || config.OutputFormat == OutputGraph) {
+		return fmt.Errorf("--resource cannot be combined with --kind, --interactive, --aggregate, --exec or --output graph")
+	}
+	if config.OutputFile != "" && (config.OutputDir != "" || config.Interactive) {
+		return fmt.Errorf("--output-file cannot be combined with --output-dir or --interactive")
+	}
+	if config.Gzip && (config.OutputDir != "" || config.Interactive) {
+		return fmt.Errorf("--gzip cannot be combined with --output-dir or --interactive")
+	}
+	if config.gzipStdout && stdoutIsTerminal() {
+		return fmt.Errorf("--gzip writes binary data, redirect stdout or use --output-file")
+	}
+	if config.Exec != "" && (!config.isTableOutput() || config.CountOnly || config.Interactive || config.Aggregate || config.Kind != "") {
+		return fmt.Errorf("--exec cannot be combined with --output, --count, --interactive, --aggregate or --kind")
+	}
+	if config.ContextFromNamespace && (len(config.Namespaces) == 0 || config.ContextName != "") {
+		return fmt.Errorf("--context-from-namespace requires --namespaces and cannot be combined with --context")
+	}
+	if config.Both && (config.HostIPOnly || config.Kind != "") {
+		return fmt.Errorf("--both cannot be combined with --host-ip or --kind")
+	}
+	if config.DetectDuplicates && (config.ContextName != "" || config.Aggregate || config.Interactive) {
+		return fmt.Errorf("--detect-duplicates compares all contexts and cannot be combined with --context, --aggregate or --interactive")
+	}
+	if config.IPContains && (config.DetectDuplicates || config.Both) {
+		return fmt.Errorf("--ip-contains cannot be combined with --detect-duplicates or --both")
+	}
+	if _, err := k8s.ParseResultSort(config.Sort); err != nil {
+		return fmt.Errorf("invalid --sort %q, expected one of %s, with a leading - for descending order", config.Sort, strings.Join(k8s.SortKeys, ", "))
+	}
+	if config.Sort != "" && (config.Resource != "" || config.Aggregate || (config.Kind != "" && !k8s.IsServiceKind(config.Kind))) {
+		return fmt.Errorf("--sort only applies to pods and services and cannot be combined with --resource, --aggregate or --kind other than service")
+	}
+	if config.SummaryJSON && !config.isTableOutput() {
+		return fmt.Errorf("--summary-json only applies to table output; json output already includes the summary")
+	}
+	if config.Confirm && config.Exec == "" {
+		return fmt.Errorf("--confirm requires --exec")
+	}
+	if err := validatePodCommand(config.Exec); err != nil {
+		return err
+	}
+	if _, err := parsePodColumns(config.Columns); err != nil {
+		return err
+	}
+	return nil
+}
+
+// out returns the writer all output of a search is written to
+func (c K8sSearchConfig) out() io.Writer {
+	if c.Out == nil {
+		return os.Stdout
+	}
+	return c.Out
+}
+
+// isVerbose reports whether informational messages, like the namespaces being searched, are printed
+// around the result tables; --quiet and structured output leave only the results
+func (c K8sSearchConfig) isVerbose() bool {
+	return c.isTableOutput() && !c.Quiet
+}
+
+// errOut returns the writer informational messages are written to, keeping them out of the results:
+// Err, else the custom writer receiving all output of the search, else stderr
+func (c K8sSearchConfig) errOut() io.Writer {
+	switch {
+	case c.Err != nil:
+		return c.Err
+	case c.Out != nil:
+		return c.Out
+	}
+	return os.Stderr
+}
+
+// notices returns the writer for notices about incomplete or slow searches, like skipped contexts,
+// truncated results and timings, or nil when they are not shown. Even with --quiet they are written
+// to errOut, except for structured output written to a custom writer, which must stay parseable.
+func (c K8sSearchConfig) notices() io.Writer {
+	if !c.isTableOutput() && c.Out != nil && c.Err == nil {
+		return nil
+	}
+	return c.errOut()
+}
+
+// isTableOutput reports whether results are rendered as human readable tables
+func (c K8sSearchConfig) isTableOutput() bool {
+	return c.OutputFormat == "" || c.OutputFormat == OutputTable || c.OutputFormat == OutputWide
+}
+
+// structuredRenderer renders search results as json, yaml, jsonl or csv
+type structuredRenderer struct {
+	config K8sSearchConfig
+}
+
+// RenderIPResults writes pod and service results in the configured output format
+func (r structuredRenderer) RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error {
+	return writeIPResults(w, r.config, results)
+}
+
+// RenderPodResults writes pod results in the configured output format
+func (r structuredRenderer) RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error {
+	return writeNameResults(w, r.config, results)
+}
+
+// RenderIngressResults writes ingress results in the configured output format
+func (r structuredRenderer) RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error {
+	return writeIngressResults(w, r.config, results)
+}
+
+// RenderWorkloadResults writes workload results in the configured output format
+func (r structuredRenderer) RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error {
+	return writeWorkloadResults(w, r.config, results)
+}
+
+// RenderEndpointResults writes endpoint results in the configured output format
+func (r structuredRenderer) RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error {
+	return writeEndpointResults(w, r.config, results)
+}
+
+// RenderResourceResults writes resource results in the configured output format
+func (r structuredRenderer) RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error {
+	return writeResourceResults(w, r.config, results)
+}
+
+// jsonlRecord is a single self-contained line of jsonl output
+type jsonlRecord struct {
+	Kind      string            `json:"kind"`
+	Context   string            `json:"context"`
+	Server    string            `json:"server,omitempty"`
+	Namespace string            `json:"namespace"`
+	Pod       *k8s.PodInfo      `json:"pod,omitempty"`
+	Service   *k8s.ServiceInfo  `json:"service,omitempty"`
+	Ingress   *k8s.IngressInfo  `json:"ingress,omitempty"`
+	Workload  *k8s.WorkloadInfo `json:"workload,omitempty"`
+	Endpoint  *k8s.EndpointInfo `json:"endpoint,omitempty"`
+	Resource  *k8s.ResourceInfo `json:"resource,omitempty"`
+}
+
+// writeStructured writes results as json, jsonl or yaml to w
+func writeStructured(w io.Writer, format string, results interface{}) error {
+	switch format {
+	case OutputJSONL:
+		data, err := json.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+	case OutputJSON:
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+	case OutputYAML:
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		fmt.Fprint(w, string(data))
+	}
+	return nil
+}
+
+// writeIPResults writes IP search results in a non-table output format
+func writeIPResults(w io.Writer, config K8sSearchConfig, results []k8s.SearchResultWithContext) error {
+	if config.OutputFormat == OutputJSONL {
+		for _, result := range results {
+			if err := writePodRecords(w, result.Context, result.Server, result.Namespace, result.Pods); err != nil {
+				return err
+			}
+			for i := range result.Services {
+				record := jsonlRecord{Kind: "Service", Context: result.Context, Server: result.Server, Namespace: result.Namespace, Service: &result.Services[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if config.OutputFormat != OutputCSV {
+		return writeResults(w, config, results, summarizeIPResults(results))
+	}
+
+	podRows := [][]string{}
+	serviceRows := [][]string{}
+	for _, result := range results {
+		podRows = append(podRows, podCSVRows(result.Context, result.Namespace, result.Pods)...)
+		serviceRows = append(serviceRows, serviceCSVRows(result.Context, result.Namespace, result.Services)...)
+	}
+
+	if config.OutputDir != "" {
+		if err := writeCSVFile(config.OutputDir, "pods.csv", podCSVHeader, podRows); err != nil {
+			return err
+		}
+		return writeCSVFile(config.OutputDir, "services.csv", serviceCSVHeader, serviceRows)
+	}
+
+	// Without an output directory both sections go to w, separated by a blank line
+	if err := writeCSV(w, podCSVHeader, podRows); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	return writeCSV(w, serviceCSVHeader, serviceRows)
+}
+
+// writeNameResults writes name search results in a non-table output format
+func writeNameResults(w io.Writer, config K8sSearchConfig, results []k8s.PodResultWithContext) error {
+	if config.OutputFormat == OutputJSONL {
+		for _, result := range results {
+			if err := writePodRecords(w, result.Context, result.Server, result.Namespace, result.Pods); err != nil {
+				return err
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if config.OutputFormat != OutputCSV {
+		return writeResults(w, config, results, summarizePodResults(results))
+	}
+
+	podRows := [][]string{}
+	for _, result := range results {
+		podRows = append(podRows, podCSVRows(result.Context, result.Namespace, result.Pods)...)
+	}
+
+	if config.OutputDir != "" {
+		return writeCSVFile(config.OutputDir, "pods.csv", podCSVHeader, podRows)
+	}
+	return writeCSV(w, podCSVHeader, podRows)
+}
+
+// writePodRecords writes one jsonl line per pod
+func writePodRecords(w io.Writer, contextName, server, namespace string, pods []k8s.PodInfo) error {
+	for i := range pods {
+		record := jsonlRecord{Kind: "Pod", Context: contextName, Server: server, Namespace: namespace, Pod: &pods[i]}
+		if err := writeStructured(w, OutputJSONL, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushResult flushes the records of one namespace result written to compressed output, so
+// `-o jsonl --gzip | zcat` shows them as they are written rather than once the output is closed
+func flushResult(w io.Writer) error {
+	if compressed, ok := w.(*gzip.Writer); ok {
+		return compressed.Flush()
+	}
+	return nil
+}
+
+// podCSVRows converts pods into CSV rows matching podCSVHeader
+func podCSVRows(contextName, namespace string, pods []k8s.PodInfo) [][]string {
+	rows := make([][]string, 0, len(pods))
+	for _, pod := range pods {
+		rows = append(rows, []string{
+			contextName,
+			namespace,
+			pod.Name,
+			formatIPs(pod.PodIP, pod.PodIPs, ","),
+			formatIPs(pod.HostIP, pod.HostIPs, ","),
+			pod.OwnerKind,
+			pod.OwnerName,
+			pod.MatchedOn,
+			pod.NodeName,
+		})
+	}
+	return rows
+}
+
+// serviceCSVRows converts services into CSV rows matching serviceCSVHeader
+func serviceCSVRows(contextName, namespace string, services []k8s.ServiceInfo) [][]string {
+	rows := make([][]string, 0, len(services))
+	for _, svc := range services {
+		ports := []string{}
+		for _, port := range svc.Ports {
+			ports = append(ports, formatPort(port))
+		}
+
+		selector := []string{}
+		for k, v := range svc.Selector {
+			selector = append(selector, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(selector)
+
+		rows = append(rows, []string{
+			contextName,
+			namespace,
+			svc.Name,
+			svc.Type,
+			formatIPs(svc.ClusterIP, svc.ClusterIPs, ","),
+			strings.Join(svc.ExternalIPs, ","),
+			strings.Join(ports, ","),
+			strings.Join(formatNodePorts(svc.Ports), ","),
+			strings.Join(selector, ","),
+			svc.ExternalName,
+			svc.MatchedOn,
+		})
+	}
+	return rows
+}
+
+// writeCSV writes a header and rows as RFC 4180 CSV
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	return nil
+}
+
+// writeCSVFile writes a CSV file named fileName into dir, creating dir if needed
+func writeCSVFile(dir, fileName string, header []string, rows [][]string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fileName)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := writeCSV(file, header, rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// searchSummary holds the counts printed at the end of a search
+type searchSummary struct {
+	Contexts  int  `json:"contexts"`
+	Pods      int  `json:"pods"`
+	Services  *int `json:"services,omitempty"`
+	Ingresses *int `json:"ingresses,omitempty"`
+	Workloads *int `json:"workloads,omitempty"`
+	Endpoints *int `json:"endpoints,omitempty"`
+	Resources *int `json:"resources,omitempty"`
+}
+
+// summarizeIPResults counts pods and services in IP search results
+func summarizeIPResults(results []k8s.SearchResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results), Services: new(int)}
+	for _, result := range results {
+		summary.Pods += len(result.Pods)
+		*summary.Services += len(result.Services)
+	}
+	return summary
+}
+
+// summarizePodResults counts pods in pod search results
+func summarizePodResults(results []k8s.PodResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results)}
+	for _, result := range results {
+		summary.Pods += len(result.Pods)
+	}
+	return summary
+}
+
+// total returns the number of matches counted in the summary
+func (s searchSummary) total() int {
+	total := s.Pods
+	if s.Services != nil {
+		total += *s.Services
+	}
+	if s.Ingresses != nil {
+		total += *s.Ingresses
+	}
+	if s.Workloads != nil {
+		total += *s.Workloads
+	}
+	if s.Endpoints != nil {
+		total += *s.Endpoints
+	}
+	if s.Resources != nil {
+		total += *s.Resources
+	}
+	return total
+}
+
+// writeTruncationNotice tells the user on stderr when a search stopped at --max-results
+func writeTruncationNotice(config K8sSearchConfig, matches int) {
+	if config.MaxResults <= 0 || matches < config.MaxResults {
+		return
+	}
+
+	if w := config.notices(); w != nil {
+		fmt.Fprintln(w, text.FgYellow.Sprintf("Results truncated at %d; narrow your query", config.MaxResults))
+	}
+}
+
+// writeNoAccessNotice warns when nothing matched after namespace discovery found no namespace
+// the search can access: the empty result is then likely due to RBAC, not to the resource not existing
+func writeNoAccessNotice(config K8sSearchConfig, matches int) {
+	if !config.noAccessibleNamespaces || matches > 0 {
+		return
+	}
+
+	if w := config.notices(); w != nil {
+		fmt.Fprintln(w, text.FgYellow.Sprintf("Warning: no accessible namespace was found, so this empty result is likely due to missing RBAC permissions rather than nothing matching (check your access with k8sx ns)"))
+	}
+}
+
+// summaryLine is the compact json object --summary-json prints after the result tables
+type summaryLine struct {
+	searchSummary
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+}
+
+// writesSummaryJSON reports whether the --summary-json line is printed after the result tables
+func (c K8sSearchConfig) writesSummaryJSON() bool {
+	return c.SummaryJSON && c.isTableOutput()
+}
+
+// writeSummaryJSON prints the summary counts, with the number of skipped contexts and namespaces and of
+// failed searches, as one line of json after the result tables when --summary-json is set
+func writeSummaryJSON(config K8sSearchConfig, summary searchSummary) {
+	if !config.writesSummaryJSON() {
+		return
+	}
+
+	line := summaryLine{searchSummary: summary}
+	if config.report != nil {
+		line.Skipped = len(config.report.skipped)
+		line.Errors = len(config.report.errors)
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(config.out(), string(data))
+}
+
+// printSummary prints the summary block shown after result tables
+func printSummary(w io.Writer, summary searchSummary) {
+	fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Summary ==="))
+	fmt.Fprintf(w, "Total contexts searched: %d\n", summary.Contexts)
+	// Workload, endpoint and resource searches find no pods
+	if summary.Workloads != nil {
+		fmt.Fprintf(w, "Total workloads found: %d\n", *summary.Workloads)
+		return
+	}
+	if summary.Endpoints != nil {
+		fmt.Fprintf(w, "Total endpoints found: %d\n", *summary.Endpoints)
+		return
+	}
+	if summary.Resources != nil {
+		fmt.Fprintf(w, "Total resources found: %d\n", *summary.Resources)
+		return
+	}
+	fmt.Fprintf(w, "Total pods found: %d\n", summary.Pods)
+	if summary.Services != nil {
+		fmt.Fprintf(w, "Total services found: %d\n", *summary.Services)
+	}
+	if summary.Ingresses != nil {
+		fmt.Fprintf(w, "Total ingresses found: %d\n", *summary.Ingresses)
+	}
+}
+
+// writeSummary writes only the summary counts in the configured output format
+func writeSummary(config K8sSearchConfig, summary searchSummary) error {
+	switch config.OutputFormat {
+	case OutputJSON, OutputYAML, OutputJSONL:
+		return writeStructured(config.out(), config.OutputFormat, summary)
+	case OutputCSV:
+		header := []string{"Contexts", "Pods"}
+		row := []string{fmt.Sprintf("%d", summary.Contexts), fmt.Sprintf("%d", summary.Pods)}
+		if summary.Services != nil {
+			header = append(header, "Services")
+			row = append(row, fmt.Sprintf("%d", *summary.Services))
+		}
+		if summary.Ingresses != nil {
+			header = append(header, "Ingresses")
+			row = append(row, fmt.Sprintf("%d", *summary.Ingresses))
+		}
+		if summary.Workloads != nil {
+			header = append(header, "Workloads")
+			row = append(row, fmt.Sprintf("%d", *summary.Workloads))
+		}
+		if summary.Endpoints != nil {
+			header = append(header, "Endpoints")
+			row = append(row, fmt.Sprintf("%d", *summary.Endpoints))
+		}
+		if summary.Resources != nil {
+			header = append(header, "Resources")
+			row = append(row, fmt.Sprintf("%d", *summary.Resources))
+		}
+		return writeCSV(config.out(), header, [][]string{row})
+	}
+
+	printSummary(config.out(), summary)
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// WithOutputFile runs search with its output written to config.OutputFile instead of config.Out and
+// prints a short confirmation once it is written. The output goes to a temporary file next to the
+// target that is renamed into place only when the search succeeds, so a failed or interrupted run
+// never leaves a partially written file. Without OutputFile search simply runs with config.
+// The output is gzip-compressed with Gzip or when OutputFile ends in .gz. Files and compressed output
+// are shared and read elsewhere, so they get no colors or box drawing characters even from a terminal.
+func WithOutputFile(config K8sSearchConfig, search func(config K8sSearchConfig) error) error {
+	if config.OutputFile != "" || config.Gzip {
+		setPlainOutput(true)
+	}
+	if config.OutputFile == "" {
+		return withGzip(config, search)
+	}
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(config.OutputFile), "."+filepath.Base(config.OutputFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	// CreateTemp makes the file readable by the owner only, unlike os.Create
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	// Removing fails harmlessly once the file was renamed into place
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// An interrupt would otherwise exit without running the deferred cleanup
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(interrupted)
+		close(interrupted)
+	}()
+	go func() {
+		if _, ok := <-interrupted; ok {
+			os.Remove(tmp.Name())
+			os.Exit(130)
+		}
+	}()
+
+	// Messages stay where they would have gone without the file
+	confirm := config.errOut()
+	config.Err = confirm
+	config.Out = tmp
+	if err := withGzip(config, search); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), config.OutputFile); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if config.Quiet {
+		return nil
+	}
+	fmt.Fprintln(confirm, text.FgGreen.Sprintf("Results written to %s", config.OutputFile))
+	return nil
+}
+
+// withGzip runs search with its output gzip-compressed when config.Gzip is set or the output file
+// ends in .gz, and with its messages left uncompressed where they would have gone
+func withGzip(config K8sSearchConfig, search func(config K8sSearchConfig) error) error {
+	if !config.Gzip && !strings.HasSuffix(config.OutputFile, ".gz") {
+		return search(config)
+	}
+
+	compressed := gzip.NewWriter(config.out())
+	config.gzipStdout = config.Out == nil
+	config.Err = config.errOut()
+	config.Out = compressed
+	err := search(config)
+
+	// Options are validated before anything is written, and closing would still write the gzip header
+	if errors.Is(err, k8s.ErrInvalidOptions) {
+		return err
+	}
+
+	// Closing ends the stream, so even the output of a failed search can be decompressed
+	if closeErr := compressed.Close(); closeErr != nil && err == nil {
+		return fmt.Errorf("failed to write compressed output: %w", closeErr)
+	}
+	return err
+}
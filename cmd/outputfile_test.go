@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithOutputFile tests that output is written to the file only when the search succeeds
+func TestWithOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.json")
+	var terminal bytes.Buffer
+
+	// Successful search: output lands in the file, the terminal gets a confirmation
+	config := K8sSearchConfig{OutputFormat: OutputJSON, OutputFile: path, Out: &terminal}
+	err := WithOutputFile(config, func(config K8sSearchConfig) error {
+		fmt.Fprintln(config.out(), `{"pods":[]}`)
+		return nil
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "{\"pods\":[]}\n", string(data))
+	require.Contains(t, terminal.String(), "Results written to "+path)
+	require.NotContains(t, terminal.String(), "pods")
+
+	// Failed search: the previous file is kept and no temporary file is left behind
+	err = WithOutputFile(config, func(config K8sSearchConfig) error {
+		fmt.Fprintln(config.out(), `{"partial":`)
+		return errors.New("search failed")
+	})
+	require.EqualError(t, err, "search failed")
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "{\"pods\":[]}\n", string(data))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Without an output file the search writes to Out
+	var out bytes.Buffer
+	err = WithOutputFile(K8sSearchConfig{Out: &out}, func(config K8sSearchConfig) error {
+		fmt.Fprint(config.out(), "table")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "table", out.String())
+
+	// Conflicting options are rejected before a file is created
+	err = WithOutputFile(K8sSearchConfig{OutputFormat: OutputCSV, OutputFile: filepath.Join(dir, "other.csv"), OutputDir: dir}, func(K8sSearchConfig) error {
+		t.Fatal("search must not run")
+		return nil
+	})
+	require.ErrorIs(t, err, k8s.ErrInvalidOptions)
+	_, err = os.Stat(filepath.Join(dir, "other.csv"))
+	require.True(t, os.IsNotExist(err))
+}
+
+// gunzip decompresses data written by withGzip
+func gunzip(t *testing.T, data []byte) string {
+	t.Helper()
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decompressed)
+}
+
+// TestWithOutputFileGzip tests compressing the output written to a .gz file or with --gzip
+func TestWithOutputFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json.gz")
+	var terminal bytes.Buffer
+
+	config := K8sSearchConfig{OutputFormat: OutputJSON, OutputFile: path, Out: &terminal}
+	require.NoError(t, WithOutputFile(config, func(config K8sSearchConfig) error {
+		return writeStructured(config.out(), OutputJSON, fixturePodResults())
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, gunzip(t, data), `"name": "nginx-7d9c-abcde"`)
+	assert.Contains(t, terminal.String(), "Results written to "+path)
+
+	// Output written to stdout with --gzip is compressed, messages stay uncompressed
+	var out, errOut bytes.Buffer
+	config = K8sSearchConfig{OutputFormat: OutputJSONL, Gzip: true, Out: &out, Err: &errOut}
+	require.NoError(t, WithOutputFile(config, func(config K8sSearchConfig) error {
+		require.NoError(t, writeStructured(config.out(), OutputJSONL, map[string]string{"name": "web-1"}))
+		fmt.Fprintln(config.errOut(), "Searching...")
+		return nil
+	}))
+	assert.Equal(t, "{\"name\":\"web-1\"}\n", gunzip(t, out.Bytes()))
+	assert.Equal(t, "Searching...\n", errOut.String())
+
+	require.ErrorContains(t, validateOutput(K8sSearchConfig{OutputFormat: OutputCSV, OutputDir: t.TempDir(), Gzip: true}), "--gzip cannot be combined")
+}
+
+// TestGzipToTerminal tests that compressed output is only written to a terminal through --output-file
+func TestGzipToTerminal(t *testing.T) {
+	terminal := true
+	defer func(isTerminal func() bool) { stdoutIsTerminal = isTerminal }(stdoutIsTerminal)
+	stdoutIsTerminal = func() bool { return terminal }
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	defer func(file *os.File) { os.Stdout = file }(os.Stdout)
+	os.Stdout = stdout
+
+	// The search rejects its options before writing anything, not even a gzip header
+	err = WithOutputFile(K8sSearchConfig{OutputFormat: OutputJSON, Gzip: true}, func(config K8sSearchConfig) error {
+		return SearchK8sByNameAllContexts(config, "web")
+	})
+	assert.ErrorIs(t, err, k8s.ErrInvalidOptions)
+	assert.ErrorContains(t, err, "--gzip writes binary data")
+	info, err := stdout.Stat()
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	// Compressed output to a file or to redirected stdout is fine
+	assert.NoError(t, validateOutput(K8sSearchConfig{OutputFormat: OutputJSON, Gzip: true, OutputFile: "results.json.gz"}))
+	terminal = false
+	assert.NoError(t, validateOutput(K8sSearchConfig{OutputFormat: OutputJSON, Gzip: true, gzipStdout: true}))
+}
+
+// TestGzipJSONLStreams tests that jsonl output compressed with --gzip can be decompressed namespace by
+// namespace while it is written
+func TestGzipJSONLStreams(t *testing.T) {
+	var out bytes.Buffer
+	config := K8sSearchConfig{OutputFormat: OutputJSONL, Gzip: true, Out: &out, Err: &bytes.Buffer{}}
+	require.NoError(t, WithOutputFile(config, func(config K8sSearchConfig) error {
+		require.NoError(t, writeNameResults(config.out(), config, fixturePodResults()))
+
+		// The stream is not closed yet, but each result was flushed
+		reader, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(reader)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, summarizePodResults(fixturePodResults()).Pods, bytes.Count(decompressed, []byte("\n")))
+		return nil
+	}))
+}
+
+// TestWithOutputFilePlain tests that tables written to a file have no colors or box drawing characters
+// even when the terminal the search runs in shows them
+func TestWithOutputFilePlain(t *testing.T) {
+	setPlainOutput(false)
+	defer setPlainOutput(true)
+
+	path := filepath.Join(t.TempDir(), "results.txt")
+	config := K8sSearchConfig{OutputFile: path, Out: &bytes.Buffer{}}
+	require.NoError(t, WithOutputFile(config, func(config K8sSearchConfig) error {
+		return testTableRenderer(config).RenderPodResults(config.out(), fixturePodResults())
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "nginx-7d9c-abcde")
+	assert.NotContains(t, string(data), "\x1b[")
+	assert.NotContains(t, string(data), "─")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// podCommandPlaceholder matches a placeholder of an --exec command template, e.g. {namespace}
+var podCommandPlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// shellSafe matches values that need no quoting in a shell command
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,-]+$`)
+
+// podCommandValues returns the value of each --exec placeholder for a matched pod
+func podCommandValues(contextName string, pod k8s.PodInfo) map[string]string {
+	return map[string]string{
+		"namespace": pod.Namespace,
+		"name":      pod.Name,
+		"context":   contextName,
+		"pod_ip":    pod.PodIP,
+	}
+}
+
+// validatePodCommand checks that an --exec command template only uses known placeholders
+func validatePodCommand(template string) error {
+	values := podCommandValues("", k8s.PodInfo{})
+	for _, match := range podCommandPlaceholder.FindAllStringSubmatch(template, -1) {
+		if _, ok := values[match[1]]; !ok {
+			return fmt.Errorf("unknown placeholder %s in --exec (supported: {namespace}, {name}, {context}, {pod_ip})", match[0])
+		}
+	}
+	return nil
+}
+
+// expandPodCommand substitutes the placeholders of an --exec command template for a matched pod,
+// quoting each value for the shell
+func expandPodCommand(template string, contextName string, pod k8s.PodInfo) string {
+	values := podCommandValues(contextName, pod)
+	return podCommandPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := values[strings.Trim(placeholder, "{}")]
+		if !ok {
+			return placeholder
+		}
+		return shellQuote(value)
+	})
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runPodCommands prints the --exec command for every matched pod and, with --confirm, runs them
+// one after the other through the shell, printing the output of each. A failing command does not
+// stop the others; the search fails once all have run.
+func runPodCommands(config K8sSearchConfig, results []k8s.PodResultWithContext) error {
+	if config.Exec == "" {
+		return nil
+	}
+
+	w := config.out()
+	if config.Confirm {
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Running commands ==="))
+	} else {
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Commands (not run, add --confirm to run them) ==="))
+	}
+
+	total, failed := 0, 0
+	for _, result := range results {
+		for _, pod := range result.Pods {
+			command := expandPodCommand(config.Exec, result.Context, pod)
+			total++
+			if !config.Confirm {
+				fmt.Fprintln(w, command)
+				continue
+			}
+			if !runPodCommand(w, command) {
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands failed", failed, total)
+	}
+	return nil
+}
+
+// runPodCommand runs command through the shell, printing it followed by its output, and reports whether it succeeded
+func runPodCommand(w io.Writer, command string) bool {
+	fmt.Fprintln(w, text.FgCyan.Sprintf("$ %s", command))
+	output, err := exec.Command("sh", "-c", command).CombinedOutput()
+	if len(output) > 0 {
+		fmt.Fprint(w, string(output))
+		if !strings.HasSuffix(string(output), "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(w, text.FgRed.Sprintf("Command failed: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExpandPodCommand tests placeholder substitution and shell quoting of --exec commands
+func TestExpandPodCommand(t *testing.T) {
+	pod := k8s.PodInfo{Name: "nginx-1", Namespace: "web", PodIP: "10.0.0.1"}
+
+	command := expandPodCommand("kubectl --context {context} -n {namespace} delete pod {name} # {pod_ip}", "prod", pod)
+	assert.Equal(t, "kubectl --context prod -n web delete pod nginx-1 # 10.0.0.1", command)
+
+	// Values with shell metacharacters are quoted as single words
+	command = expandPodCommand("echo {context}", "it's prod; rm -rf /", pod)
+	assert.Equal(t, `echo 'it'\''s prod; rm -rf /'`, command)
+	assert.Equal(t, "echo ''", expandPodCommand("echo {pod_ip}", "prod", k8s.PodInfo{}))
+
+	require.NoError(t, validatePodCommand("kubectl -n {namespace} logs {name}"))
+	require.NoError(t, validatePodCommand(""))
+	assert.Error(t, validatePodCommand("kubectl logs {pod}"))
+}
+
+// TestRunPodCommands tests that --exec commands are only printed without --confirm and run in order with it
+func TestRunPodCommands(t *testing.T) {
+	results := []k8s.PodResultWithContext{
+		{Context: "prod", Namespace: "web", Pods: []k8s.PodInfo{{Name: "nginx-1", Namespace: "web"}, {Name: "nginx-2", Namespace: "web"}}},
+	}
+
+	var buf bytes.Buffer
+	config := K8sSearchConfig{Out: &buf, Exec: "test {name} = nginx-1 && echo deleted {namespace}/{name}"}
+	require.NoError(t, runPodCommands(config, results))
+	assert.Contains(t, buf.String(), "add --confirm to run them")
+	assert.Contains(t, buf.String(), "test nginx-2 = nginx-1 && echo deleted web/nginx-2\n")
+	assert.NotContains(t, buf.String(), "$ ")
+
+	// The failing command for nginx-2 does not stop the others and fails the search
+	buf.Reset()
+	config.Confirm = true
+	err := runPodCommands(config, results)
+	assert.EqualError(t, err, "1 of 2 commands failed")
+	assert.Contains(t, buf.String(), "$ test nginx-1 = nginx-1 && echo deleted web/nginx-1\ndeleted web/nginx-1\n")
+	assert.Contains(t, buf.String(), "Command failed: exit status 1")
+
+	buf.Reset()
+	require.NoError(t, runPodCommands(K8sSearchConfig{Out: &buf}, results))
+	assert.Empty(t, buf.String())
+}
+
+// TestValidateExec tests the options --exec and --confirm cannot be combined with
+func TestValidateExec(t *testing.T) {
+	require.NoError(t, validateOutput(K8sSearchConfig{Exec: "echo {name}", Confirm: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Exec: "echo {name}", OutputFormat: OutputJSON}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Exec: "echo {name}", CountOnly: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Exec: "echo {uid}"}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Confirm: true}))
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	k8s "k8sx/pkg"
+
+	"golang.org/x/term"
+)
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// progressBar draws a single updating progress line for all-contexts searches
+type progressBar struct {
+	w io.Writer
+}
+
+// newProgressBar returns the progress bar for a search, or nil when progress should not be shown:
+// it is drawn on stderr only when stderr is a terminal and results are printed as tables to stdout without --quiet
+func newProgressBar(config K8sSearchConfig) *progressBar {
+	if config.NoProgress || !config.isVerbose() || config.Out != nil || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progressBar{w: os.Stderr}
+}
+
+// update redraws the progress line, clearing it once the search is done. A nil bar draws nothing.
+func (p *progressBar) update(progress k8s.SearchProgress) {
+	if p == nil {
+		return
+	}
+	if progress.Done {
+		p.clear()
+		return
+	}
+
+	filled := int(progress.Fraction() * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	fmt.Fprintf(p.w, "\r\x1b[K[%s] context %d/%d (%s), namespace %d/%d",
+		bar, progress.ContextIndex, progress.Contexts, progress.Context, progress.NamespaceIndex, progress.Namespaces)
+}
+
+// clear erases the progress line so other output starts on a clean line
+func (p *progressBar) clear() {
+	if p == nil {
+		return
+	}
+	fmt.Fprint(p.w, "\r\x1b[K")
+}
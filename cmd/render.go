@@ -0,0 +1,672 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// highRestartCount is the restart count from which containers are highlighted
+const highRestartCount = 5
+
+// now returns the current time ages are computed against, replaced in tests for stable output
+var now = time.Now
+
+// plainOutput is set when colors and box drawing characters are disabled
+var plainOutput bool
+
+// ConfigureColors disables colors and switches tables to plain ASCII when noColor is set,
+// NO_COLOR is set or stdout is not a terminal
+func ConfigureColors(noColor bool) {
+	setPlainOutput(noColor || os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// setPlainOutput disables colors and box drawing characters when plain is set, and enables them otherwise
+func setPlainOutput(plain bool) {
+	plainOutput = plain
+	if plain {
+		text.DisableColors()
+	} else {
+		text.EnableColors()
+	}
+}
+
+// tableStyle returns the style used for all rendered tables
+func tableStyle() table.Style {
+	if plainOutput {
+		return table.StyleDefault
+	}
+	return table.StyleLight
+}
+
+// Renderer writes search results to w. Setting K8sSearchConfig.Renderer replaces the default
+// table or structured output, e.g. to capture results when k8sx is embedded in another program.
+type Renderer interface {
+	RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error
+	RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error
+	RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error
+	RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error
+	RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error
+	RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error
+}
+
+// renderer returns the renderer for search results: config.Renderer when set,
+// otherwise the table or structured renderer matching the output format
+func (c K8sSearchConfig) renderer(ctx context.Context) Renderer {
+	if c.Renderer != nil {
+		return c.Renderer
+	}
+	if c.isTableOutput() {
+		return NewTableRenderer(ctx, c)
+	}
+	if c.OutputFormat == OutputGraph {
+		return NewGraphRenderer(ctx, c)
+	}
+	if c.OutputFormat == OutputTemplate {
+		return NewTemplateRenderer(c)
+	}
+	return structuredRenderer{config: c}
+}
+
+// TableRenderer renders search results as one table per context and namespace followed by a summary
+type TableRenderer struct {
+	config   K8sSearchConfig
+	owner    func(contextName string) ownerResolver
+	fronting func(contextName string) frontingResolver
+}
+
+// NewTableRenderer creates a table renderer that resolves owners and fronting services with the contexts of config
+func NewTableRenderer(ctx context.Context, config K8sSearchConfig) *TableRenderer {
+	resolvers := newContextResolvers(ctx, config.KubeconfigPath)
+	return &TableRenderer{
+		config:   config,
+		owner:    resolvers.owner,
+		fronting: resolvers.fronting,
+	}
+}
+
+// RenderIPResults writes pod and service tables for each context and namespace
+func (r *TableRenderer) RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error {
+	for _, result := range results {
+		// Display pods
+		if len(result.Pods) > 0 {
+			fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Pods in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+			fmt.Fprintln(w, renderPodTable(r.config, result.Pods, false, r.owner(result.Context), nil))
+		}
+
+		// Display services
+		if len(result.Services) > 0 {
+			fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Services in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+			fmt.Fprintln(w, renderServiceTable(r.config, result.Services, false))
+		}
+	}
+
+	if !r.config.Quiet {
+		printSummary(w, summarizeIPResults(results))
+	}
+	return nil
+}
+
+// RenderPodResults writes a pod table for each context and namespace, followed by the pods selected
+// by more than one service
+func (r *TableRenderer) RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error {
+	overlaps := []overlappingSelectors{}
+	for _, result := range results {
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Pods in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+		fronting := r.fronting(result.Context)
+		recordOverlaps := func(pod k8s.PodInfo) []string {
+			services := fronting(pod)
+			if len(services) > 1 {
+				overlaps = append(overlaps, overlappingSelectors{context: contextLabel(result.Context, result.Server), pod: pod, services: services})
+			}
+			return services
+		}
+		fmt.Fprintln(w, renderPodTable(r.config, result.Pods, false, r.owner(result.Context), recordOverlaps))
+	}
+	writeOverlappingSelectors(w, overlaps)
+
+	if !r.config.Quiet {
+		printSummary(w, summarizePodResults(results))
+	}
+	return nil
+}
+
+// RenderIngressResults writes ingress and ExternalName service tables for each context and namespace
+func (r *TableRenderer) RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error {
+	for _, result := range results {
+		if len(result.Ingresses) > 0 {
+			fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Ingresses in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+			fmt.Fprintln(w, renderIngressTable(result.Ingresses))
+		}
+
+		if len(result.Services) > 0 {
+			fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Services in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+			fmt.Fprintln(w, renderServiceTable(r.config, result.Services, false))
+		}
+	}
+
+	if !r.config.Quiet {
+		printSummary(w, summarizeIngressResults(results))
+	}
+	return nil
+}
+
+// RenderWorkloadResults writes a workload table for each context and namespace
+func (r *TableRenderer) RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error {
+	for _, result := range results {
+		// A search returns a single workload kind
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== %ss in Context: %s, Namespace: %s ===", result.Workloads[0].Kind, contextLabel(result.Context, result.Server), result.Namespace))
+		fmt.Fprintln(w, renderWorkloadTable(result.Workloads))
+	}
+
+	if !r.config.Quiet {
+		printSummary(w, summarizeWorkloadResults(results))
+	}
+	return nil
+}
+
+// RenderEndpointResults writes an endpoint table for each context and namespace
+func (r *TableRenderer) RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error {
+	for _, result := range results {
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Endpoints in Context: %s, Namespace: %s ===", contextLabel(result.Context, result.Server), result.Namespace))
+		fmt.Fprintln(w, renderEndpointTable(result.Endpoints))
+	}
+
+	if !r.config.Quiet {
+		printSummary(w, summarizeEndpointResults(results))
+	}
+	return nil
+}
+
+// RenderResourceResults writes a resource table for each context and namespace
+func (r *TableRenderer) RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error {
+	for _, result := range results {
+		// A search returns a single resource kind
+		fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== %ss in Context: %s, Namespace: %s ===", result.Resources[0].Kind, contextLabel(result.Context, result.Server), result.Namespace))
+		fmt.Fprintln(w, renderResourceTable(result.Resources))
+	}
+
+	if !r.config.Quiet {
+		printSummary(w, summarizeResourceResults(results))
+	}
+	return nil
+}
+
+// contextLabel names a context in table headers, followed by its cluster's server URL when known
+func contextLabel(contextName string, server string) string {
+	if server == "" {
+		return contextName
+	}
+	return fmt.Sprintf("%s (%s)", contextName, server)
+}
+
+// enrichmentTimeout bounds each lookup made while rendering results, e.g. of the Deployment of a ReplicaSet
+const enrichmentTimeout = 10 * time.Second
+
+// enrichmentContext returns the context of a lookup made while rendering results. It has its own deadline
+// instead of what is left of the search's, so owners are still resolved after a slow search.
+func enrichmentContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), enrichmentTimeout)
+}
+
+// ownerResolver returns the text displayed in the Owner Name column for a pod
+type ownerResolver func(pod k8s.PodInfo) string
+
+// clientOwnerResolver resolves ReplicaSet owners to their Deployment and Job owners to their CronJob
+// and its schedule using an existing client, looking up each owner only once
+func clientOwnerResolver(ctx context.Context, client *k8s.K8sClient) ownerResolver {
+	owners := map[string]string{}
+	return func(pod k8s.PodInfo) string {
+		key := pod.OwnerKind + "/" + pod.Namespace + "/" + pod.OwnerName
+		if owner, ok := owners[key]; ok {
+			return owner
+		}
+		owner := resolveOwner(ctx, client, pod)
+		owners[key] = owner
+		return owner
+	}
+}
+
+// resolveOwner returns the text displayed in the Owner Name column for a pod, looked up with client
+func resolveOwner(ctx context.Context, client *k8s.K8sClient, pod k8s.PodInfo) string {
+	if pod.OwnerKind != "ReplicaSet" && pod.OwnerKind != "Job" {
+		return pod.OwnerName
+	}
+
+	ctx, cancel := enrichmentContext(ctx)
+	defer cancel()
+	switch pod.OwnerKind {
+	case "ReplicaSet":
+		// Try to get deployment name
+		deploymentName, err := client.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName)
+		if err == nil {
+			return fmt.Sprintf("%s (Deployment: %s)", pod.OwnerName, deploymentName)
+		}
+	case "Job":
+		cronJobName, schedule, err := client.GetCronJobForJob(ctx, pod.Namespace, pod.OwnerName)
+		if err == nil {
+			return formatCronJobOwner(pod.OwnerName, cronJobName, schedule)
+		}
+	}
+	return pod.OwnerName
+}
+
+// formatCronJobOwner formats a Job owner spawned by a CronJob, with the schedule when it is known
+func formatCronJobOwner(jobName string, cronJobName string, schedule string) string {
+	if schedule == "" {
+		return fmt.Sprintf("%s (CronJob: %s)", jobName, cronJobName)
+	}
+	return fmt.Sprintf("%s (CronJob: %s, schedule: %s)", jobName, cronJobName, schedule)
+}
+
+// contextResolvers creates the client of each context and its owner and fronting resolvers on first use,
+// reusing them for the tables of every namespace of the context instead of creating a client for each
+type contextResolvers struct {
+	ctx            context.Context
+	kubeconfigPath string
+	clients        map[string]*k8s.K8sClient
+	owners         map[string]ownerResolver
+	frontings      map[string]frontingResolver
+}
+
+// newContextResolvers creates the resolvers of the contexts of kubeconfigPath
+func newContextResolvers(ctx context.Context, kubeconfigPath string) *contextResolvers {
+	return &contextResolvers{
+		ctx:            ctx,
+		kubeconfigPath: kubeconfigPath,
+		clients:        map[string]*k8s.K8sClient{},
+		owners:         map[string]ownerResolver{},
+		frontings:      map[string]frontingResolver{},
+	}
+}
+
+// client returns the client for contextName, or nil when it cannot be created; failures are kept too,
+// so they are not retried for every pod
+func (r *contextResolvers) client(contextName string) *k8s.K8sClient {
+	client, ok := r.clients[contextName]
+	if !ok {
+		client, _ = k8s.NewK8sClient(r.kubeconfigPath, contextName, []string{})
+		r.clients[contextName] = client
+	}
+	return client
+}
+
+// owner resolves ReplicaSet and Job owners like clientOwnerResolver, creating the client for contextName
+// on first use
+func (r *contextResolvers) owner(contextName string) ownerResolver {
+	if resolve, ok := r.owners[contextName]; ok {
+		return resolve
+	}
+
+	var resolve ownerResolver
+	r.owners[contextName] = func(pod k8s.PodInfo) string {
+		if pod.OwnerKind != "ReplicaSet" && pod.OwnerKind != "Job" {
+			return pod.OwnerName
+		}
+		if resolve == nil {
+			client := r.client(contextName)
+			if client == nil {
+				return pod.OwnerName
+			}
+			resolve = clientOwnerResolver(r.ctx, client)
+		}
+		return resolve(pod)
+	}
+	return r.owners[contextName]
+}
+
+// frontingResolver returns the names of the services selecting a pod
+type frontingResolver func(pod k8s.PodInfo) []string
+
+// clientFrontingResolver finds the services selecting a pod using an existing client,
+// listing the services of each namespace only once
+func clientFrontingResolver(ctx context.Context, client *k8s.K8sClient) frontingResolver {
+	servicesByNamespace := map[string][]k8s.ServiceInfo{}
+	return func(pod k8s.PodInfo) []string {
+		services, ok := servicesByNamespace[pod.Namespace]
+		if !ok {
+			// Without access to services the column stays empty
+			ctx, cancel := enrichmentContext(ctx)
+			services, _ = client.ListServices(ctx, pod.Namespace)
+			cancel()
+			servicesByNamespace[pod.Namespace] = services
+		}
+
+		names := []string{}
+		for _, svc := range k8s.ServicesSelectingPod(services, pod) {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+}
+
+// overlappingSelectors is a pod selected by more than one service of its namespace,
+// which then splits the traffic of each of them with the pods of the others
+type overlappingSelectors struct {
+	context  string
+	pod      k8s.PodInfo
+	services []string
+}
+
+// writeOverlappingSelectors warns about the pods selected by more than one service, listing the services
+func writeOverlappingSelectors(w io.Writer, overlaps []overlappingSelectors) {
+	if len(overlaps) == 0 {
+		return
+	}
+
+	overlapTable := table.Table{}
+	overlapTable.SetStyle(tableStyle())
+	overlapTable.AppendRow(table.Row{"Context", "Namespace", "Pod Name", "Services"})
+	for _, overlap := range overlaps {
+		overlapTable.AppendRow(table.Row{overlap.context, overlap.pod.Namespace, overlap.pod.Name, strings.Join(overlap.services, ", ")})
+	}
+
+	fmt.Fprintln(w, text.FgYellow.Sprintf("\n=== Overlapping Service Selectors: %d pod(s) selected by more than one service ===", len(overlaps)))
+	fmt.Fprintln(w, overlapTable.Render())
+}
+
+// fronting finds the services selecting a pod like clientFrontingResolver, creating the client for contextName
+// on first use
+func (r *contextResolvers) fronting(contextName string) frontingResolver {
+	if resolve, ok := r.frontings[contextName]; ok {
+		return resolve
+	}
+
+	var resolve frontingResolver
+	r.frontings[contextName] = func(pod k8s.PodInfo) []string {
+		if resolve == nil {
+			client := r.client(contextName)
+			if client == nil {
+				return nil
+			}
+			resolve = clientFrontingResolver(r.ctx, client)
+		}
+		return resolve(pod)
+	}
+	return r.frontings[contextName]
+}
+
+// renderPodTable renders pods as a table, with a leading namespace column when showNamespace is set,
+// a "Matched On" column for IP search results, a "Matched Containers" column with --match-containers,
+// a "Matched Ports" column for pods found by container port, a "Fronted By" column listing the services selecting each pod when fronting is set,
+// a "Managers" column with --show-manager and a "QoS Class" column with --output wide
+func renderPodTable(config K8sSearchConfig, pods []k8s.PodInfo, showNamespace bool, owner ownerResolver, fronting frontingResolver) string {
+	podTable := table.Table{}
+	podTable.SetStyle(tableStyle())
+
+	header := table.Row{"Pod Name", "Pod IP", "Host IP", "Node", "Owner Kind", "Owner Name", "Age"}
+	if showNamespace {
+		header = append(table.Row{"Namespace"}, header...)
+	}
+	wide := config.OutputFormat == OutputWide
+	if wide {
+		header = append(header, "QoS Class")
+	}
+	// Service account searches and filters show the service account, as do wide tables
+	showServiceAccount := wide || config.ServiceAccount != ""
+	if showServiceAccount {
+		header = append(header, "Service Account")
+	}
+	showMatched, showPorts := false, false
+	for _, pod := range pods {
+		showMatched = showMatched || pod.MatchedOn != ""
+		showPorts = showPorts || len(pod.MatchedPorts) > 0
+	}
+	// IP searches also show the DNS name resolving to the pod, saving a lookup while debugging networking
+	if showMatched {
+		header = append(header, "Matched On", "DNS Name")
+	}
+	if config.MatchContainers {
+		header = append(header, "Matched Containers")
+	}
+	if showPorts {
+		header = append(header, "Matched Ports")
+	}
+	if fronting != nil {
+		header = append(header, "Fronted By")
+	}
+	if config.ShowManager {
+		header = append(header, "Managers")
+	}
+	// Invalid columns are rejected by validateOutput before any search
+	columns, _ := parsePodColumns(config.Columns)
+	for _, column := range columns {
+		header = append(header, column.header())
+	}
+	if config.ShowContainers {
+		header = append(header, "Containers")
+	}
+	podTable.AppendRow(header)
+
+	for _, pod := range pods {
+		row := table.Row{
+			formatPodName(config, pod),
+			formatIPs(pod.PodIP, pod.PodIPs, ", "),
+			formatIPs(pod.HostIP, pod.HostIPs, ", "),
+			pod.NodeName,
+			pod.OwnerKind,
+			owner(pod),
+			formatAge(pod),
+		}
+		if showNamespace {
+			row = append(table.Row{pod.Namespace}, row...)
+		}
+		if wide {
+			row = append(row, pod.QOSClass)
+		}
+		if showServiceAccount {
+			row = append(row, pod.ServiceAccount)
+		}
+		if showMatched {
+			row = append(row, formatMatchedOn(pod.MatchedOn), k8s.PodFQDN(pod))
+		}
+		if config.MatchContainers {
+			row = append(row, strings.Join(pod.MatchedContainers, "\n"))
+		}
+		if showPorts {
+			row = append(row, strings.Join(pod.MatchedPorts, "\n"))
+		}
+		if fronting != nil {
+			row = append(row, strings.Join(fronting(pod), ", "))
+		}
+		if config.ShowManager {
+			row = append(row, strings.Join(pod.Managers, "\n"))
+		}
+		for _, column := range columns {
+			row = append(row, column.value(pod))
+		}
+		if config.ShowContainers {
+			row = append(row, renderContainerTable(pod.Containers))
+		}
+		podTable.AppendRow(row)
+	}
+	return podTable.Render()
+}
+
+// renderContainerTable renders the containers of a pod as a nested table
+func renderContainerTable(containers []k8s.ContainerInfo) string {
+	if len(containers) == 0 {
+		return ""
+	}
+
+	containerTable := table.Table{}
+	containerTable.SetStyle(tableStyle())
+	containerTable.AppendRow(table.Row{"Container", "Image", "Ready", "Restarts", "State"})
+
+	for _, container := range containers {
+		restarts := fmt.Sprintf("%d", container.RestartCount)
+		if container.RestartCount >= highRestartCount {
+			restarts = text.FgRed.Sprint(restarts)
+		}
+
+		ready := "false"
+		if container.Ready {
+			ready = "true"
+		}
+
+		containerTable.AppendRow(table.Row{
+			container.Name,
+			container.Image,
+			ready,
+			restarts,
+			container.State,
+		})
+	}
+	return containerTable.Render()
+}
+
+// renderServiceTable renders services as a table, with a leading namespace column when showNamespace is set,
+// a "Matched On" column for IP search results and session affinity, external traffic policy and health check
+// node port columns with --output wide
+func renderServiceTable(config K8sSearchConfig, services []k8s.ServiceInfo, showNamespace bool) string {
+	svcTable := table.Table{}
+	svcTable.SetStyle(tableStyle())
+
+	header := table.Row{"Service Name", "Type", "Cluster IP", "External IPs", "Ports", "Node Ports", "Selector", "Age"}
+	if showNamespace {
+		header = append(table.Row{"Namespace"}, header...)
+	}
+	wide := config.OutputFormat == OutputWide
+	if wide {
+		header = append(header, "Session Affinity", "External Traffic Policy", "Health Check Node Port")
+	}
+	showMatched := false
+	for _, svc := range services {
+		showMatched = showMatched || svc.MatchedOn != ""
+	}
+	if showMatched {
+		header = append(header, "Matched On", "DNS Name")
+	}
+	svcTable.AppendRow(header)
+
+	for _, svc := range services {
+		ports := []string{}
+		for _, port := range svc.Ports {
+			ports = append(ports, formatPort(port))
+		}
+
+		selector := []string{}
+		for k, v := range svc.Selector {
+			selector = append(selector, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(selector)
+
+		row := table.Row{
+			svc.Name,
+			svc.Type,
+			formatClusterIP(svc, "\n"),
+			strings.Join(svc.ExternalIPs, ", "),
+			strings.Join(ports, ", "),
+			strings.Join(formatNodePorts(svc.Ports), ", "),
+			strings.Join(selector, ", "),
+			formatAgeSince(svc.CreatedAt.Time),
+		}
+		if showNamespace {
+			row = append(table.Row{svc.Namespace}, row...)
+		}
+		if wide {
+			row = append(row, svc.SessionAffinity, svc.ExternalTrafficPolicy, formatHealthCheckNodePort(svc))
+		}
+		if showMatched {
+			row = append(row, formatMatchedOn(svc.MatchedOn), k8s.ServiceFQDN(svc))
+		}
+		svcTable.AppendRow(row)
+	}
+	return svcTable.Render()
+}
+
+// formatHealthCheckNodePort formats the health check node port of a service, empty when it has none
+func formatHealthCheckNodePort(svc k8s.ServiceInfo) string {
+	if svc.HealthCheckNodePort == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(svc.HealthCheckNodePort))
+}
+
+// formatMatchedOn formats the address an IP search matched, highlighting host IP matches:
+// the IP belongs to the node, so every pod on it would match as well
+func formatMatchedOn(matchedOn string) string {
+	if matchedOn == k8s.MatchedHostIP {
+		return text.FgYellow.Sprint(matchedOn)
+	}
+	return matchedOn
+}
+
+// formatClusterIP formats the address column of a service, explaining services without a cluster IP:
+// ExternalName services show the name they alias and headless services list their endpoint IPs,
+// not-ready ones marked as such
+func formatClusterIP(svc k8s.ServiceInfo, sep string) string {
+	switch {
+	case svc.ExternalName != "":
+		return "-> " + svc.ExternalName
+	case svc.IsHeadless():
+		lines := []string{"None (headless)"}
+		lines = append(lines, svc.EndpointIPs...)
+		for _, ip := range svc.NotReadyEndpointIPs {
+			lines = append(lines, text.FgYellow.Sprintf("%s (not ready)", ip))
+		}
+		return strings.Join(lines, sep)
+	}
+	return formatIPs(svc.ClusterIP, svc.ClusterIPs, sep)
+}
+
+// formatPort formats a service port as port:targetPort/protocol
+func formatPort(port k8s.PortInfo) string {
+	return fmt.Sprintf("%d:%s/%s", port.Port, port.TargetPort, port.Protocol)
+}
+
+// formatNodePorts formats the allocated node ports of a service's ports
+func formatNodePorts(ports []k8s.PortInfo) []string {
+	nodePorts := []string{}
+	for _, port := range ports {
+		if nodePort := k8s.FormatNodePort(port); nodePort != "" {
+			nodePorts = append(nodePorts, nodePort)
+		}
+	}
+	return nodePorts
+}
+
+// formatAge formats the age of a pod the way kubectl does (e.g. 5m, 3d)
+func formatAge(pod k8s.PodInfo) string {
+	return formatAgeSince(pod.CreatedAt.Time)
+}
+
+// formatPodName marks terminated pods after their name, whose IP may since belong to another pod,
+// noting when their deletion was requested unless the output is quiet
+func formatPodName(config K8sSearchConfig, pod k8s.PodInfo) string {
+	if !pod.Terminated {
+		return pod.Name
+	}
+	if pod.DeletedAt != nil && config.isVerbose() {
+		return pod.Name + text.FgRed.Sprintf(" (terminated, deleted at %s)", pod.DeletedAt.UTC().Format(time.RFC3339))
+	}
+	return pod.Name + text.FgRed.Sprint(" (terminated)")
+}
+
+// formatAgeSince formats the time elapsed since a creation time like kubectl does
+func formatAgeSince(created time.Time) string {
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now().Sub(created))
+}
+
+// formatIPs joins all addresses of a dual-stack resource, falling back to the primary address
+func formatIPs(primary string, all []string, sep string) string {
+	if len(all) == 0 {
+		return primary
+	}
+	return strings.Join(all, sep)
+}
@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// fixtureTime is the fixed current time used for rendering ages
+var fixtureTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	// Render plain ASCII tables with ages relative to a fixed time
+	plainOutput = true
+	text.DisableColors()
+	now = func() time.Time { return fixtureTime }
+
+	os.Exit(m.Run())
+}
+
+// assertGolden compares got with testdata/<name>, rewriting the file when -update is set
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run go test ./cmd -update")
+	assert.Equal(t, string(want), string(got))
+}
+
+// fixturePods returns pods covering multiple IPs, owners and container states
+func fixturePods() []k8s.PodInfo {
+	return []k8s.PodInfo{
+		{
+			UID:       "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b",
+			Name:      "nginx-7d9c-abcde",
+			Namespace: "default",
+			PodIP:     "10.0.0.1",
+			PodIPs:    []string{"10.0.0.1", "fd00::1"},
+			HostIP:    "192.168.1.1",
+			OwnerKind: "ReplicaSet",
+			OwnerName: "nginx-7d9c",
+			Labels:    map[string]string{"app": "nginx", "tier": "web"},
+			Containers: []k8s.ContainerInfo{
+				{Name: "nginx", Image: "nginx:1.25", Ready: true, RestartCount: 0, State: "Running"},
+				{Name: "sidecar", Image: "envoy:1.30", Ready: false, RestartCount: 12, State: "Waiting: CrashLoopBackOff"},
+			},
+			CreatedAt: metav1.NewTime(fixtureTime.Add(-3 * time.Hour)),
+			NodeName:  "node-1",
+			Phase:     "Running",
+			QOSClass:  "Burstable",
+
+			ServiceAccount: "nginx",
+		},
+		{
+			UID:       "1b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b",
+			Name:      "debug",
+			Namespace: "default",
+			PodIP:     "10.0.0.2",
+			HostIP:    "192.168.1.2",
+			CreatedAt: metav1.NewTime(fixtureTime.Add(-10 * time.Minute)),
+			Phase:     "Pending",
+			QOSClass:  "BestEffort",
+
+			ServiceAccount: "default",
+		},
+	}
+}
+
+// fixtureServices returns services covering numeric and named target ports
+func fixtureServices() []k8s.ServiceInfo {
+	return []k8s.ServiceInfo{
+		{
+			Name:        "nginx",
+			Namespace:   "default",
+			ClusterIP:   "10.96.0.1",
+			ClusterIPs:  []string{"10.96.0.1", "fd00:96::1"},
+			ExternalIPs: []string{"203.0.113.10"},
+			Type:        "LoadBalancer",
+			NodePorts:   []int32{31234},
+			Ports: []k8s.PortInfo{
+				{Name: "http", Port: 80, TargetPort: "http", NodePort: 31234, Protocol: "TCP"},
+				{Name: "https", Port: 443, TargetPort: "8443", Protocol: "TCP"},
+			},
+			Selector:              map[string]string{"tier": "web", "app": "nginx"},
+			CreatedAt:             metav1.NewTime(fixtureTime.Add(-48 * time.Hour)),
+			SessionAffinity:       "ClientIP",
+			ExternalTrafficPolicy: "Local",
+			HealthCheckNodePort:   32456,
+		},
+	}
+}
+
+// fixtureIPResults returns IP search results from two contexts, including a headless service
+func fixtureIPResults() []k8s.SearchResultWithContext {
+	pods := fixturePods()
+	pods[0].MatchedOn = k8s.MatchedPodIP
+	pods[1].MatchedOn = k8s.MatchedHostIP
+	services := fixtureServices()
+	services[0].MatchedOn = k8s.MatchedLoadBalancerIngress
+
+	return []k8s.SearchResultWithContext{
+		{Context: "prod", Namespace: "default", Pods: pods, Services: services},
+		{Context: "staging", Namespace: "default", Pods: []k8s.PodInfo{}, Services: append(fixtureServices(), k8s.ServiceInfo{
+			Name:                "nginx-headless",
+			Namespace:           "default",
+			ClusterIP:           "None",
+			Type:                "ClusterIP",
+			Ports:               []k8s.PortInfo{{Port: 80, TargetPort: "80", Protocol: "TCP"}},
+			Selector:            map[string]string{"app": "nginx"},
+			EndpointIPs:         []string{"10.0.0.1", "10.0.0.3"},
+			NotReadyEndpointIPs: []string{"10.0.0.4"},
+			CreatedAt:           metav1.NewTime(fixtureTime.Add(-30 * time.Minute)),
+		})},
+	}
+}
+
+// fixturePodResults returns name search results from one context
+func fixturePodResults() []k8s.PodResultWithContext {
+	return []k8s.PodResultWithContext{
+		{Context: "prod", Server: "https://prod.example.com:6443", Namespace: "default", Pods: fixturePods()},
+	}
+}
+
+// fixtureIngressResults returns hostname search results with a wildcard host and an ExternalName service
+func fixtureIngressResults() []k8s.IngressResultWithContext {
+	return []k8s.IngressResultWithContext{
+		{
+			Context:   "prod",
+			Namespace: "web",
+			Ingresses: []k8s.IngressInfo{{
+				Name:      "shop",
+				Namespace: "web",
+				Hosts:     []string{"*.example.com", "shop.example.org"},
+				Backends:  []string{"/ -> api:8080", "/static -> cdn:http"},
+				Addresses: []string{"203.0.113.20"},
+			}},
+			Services: []k8s.ServiceInfo{{
+				Name:         "shop-alias",
+				Namespace:    "web",
+				Type:         "ExternalName",
+				ExternalName: "shop.example.org",
+				CreatedAt:    metav1.NewTime(fixtureTime.Add(-72 * time.Hour)),
+			}},
+		},
+	}
+}
+
+// testTableRenderer returns a table renderer with static owner and fronting resolvers
+func testTableRenderer(config K8sSearchConfig) *TableRenderer {
+	return &TableRenderer{
+		config: config,
+		owner: func(contextName string) ownerResolver {
+			return func(pod k8s.PodInfo) string {
+				if pod.OwnerKind == "ReplicaSet" {
+					return pod.OwnerName + " (Deployment: nginx)"
+				}
+				return pod.OwnerName
+			}
+		},
+		fronting: func(contextName string) frontingResolver {
+			return func(pod k8s.PodInfo) []string {
+				names := []string{}
+				for _, svc := range k8s.ServicesSelectingPod(fixtureServices(), pod) {
+					names = append(names, svc.Name)
+				}
+				return names
+			}
+		},
+	}
+}
+
+// TestRenderIPResultsGolden tests IP search result rendering in every output format
+func TestRenderIPResultsGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"ip_results_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"ip_results_containers_table.golden", K8sSearchConfig{OutputFormat: OutputTable, ShowContainers: true}},
+		{"ip_results_wide.golden", K8sSearchConfig{OutputFormat: OutputWide}},
+		{"ip_results_json.golden", K8sSearchConfig{OutputFormat: OutputJSON}},
+		{"ip_results_yaml.golden", K8sSearchConfig{OutputFormat: OutputYAML}},
+		{"ip_results_jsonl.golden", K8sSearchConfig{OutputFormat: OutputJSONL}},
+		{"ip_results_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			renderer := Renderer(structuredRenderer{config: tt.config})
+			if tt.config.isTableOutput() {
+				renderer = testTableRenderer(tt.config)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.RenderIPResults(&buf, fixtureIPResults()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestRenderPodResultsGolden tests name search result rendering in table and csv output
+func TestRenderPodResultsGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"pod_results_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"pod_results_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+		{"pod_results_jsonl.golden", K8sSearchConfig{OutputFormat: OutputJSONL}},
+		{"pod_results_quiet_table.golden", K8sSearchConfig{OutputFormat: OutputTable, Quiet: true}},
+		{"pod_results_service_account_table.golden", K8sSearchConfig{OutputFormat: OutputTable, ServiceAccount: "nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			renderer := Renderer(structuredRenderer{config: tt.config})
+			if tt.config.isTableOutput() {
+				renderer = testTableRenderer(tt.config)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.RenderPodResults(&buf, fixturePodResults()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestCountGolden tests the counts --count prints instead of IP and name search results, as a table and as json
+func TestCountGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		format string
+		ip     bool
+	}{
+		{"ip_results_count_table.golden", OutputTable, true},
+		{"ip_results_count_json.golden", OutputJSON, true},
+		{"pod_results_count_table.golden", OutputTable, false},
+		{"pod_results_count_json.golden", OutputJSON, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			config := K8sSearchConfig{OutputFormat: tt.format, CountOnly: true, Out: &buf, Err: &bytes.Buffer{}}
+			if tt.ip {
+				require.NoError(t, displayIPResults(context.Background(), config, fixtureIPResults(), "not found"))
+			} else {
+				require.NoError(t, displayPodResults(context.Background(), config, fixturePodResults(), "not found"))
+			}
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestRenderMatchedContainersGolden tests the column listing the init and ephemeral containers a name search matched
+func TestRenderMatchedContainersGolden(t *testing.T) {
+	results := fixturePodResults()
+	results[0].Pods[1].MatchedContainers = []string{"init: debug-config", "ephemeral: debugger-x7k2"}
+
+	var buf bytes.Buffer
+	require.NoError(t, testTableRenderer(K8sSearchConfig{MatchContainers: true}).RenderPodResults(&buf, results))
+	assertGolden(t, "pod_results_matched_containers_table.golden", buf.Bytes())
+}
+
+// TestRenderManagersGolden tests the column listing the field managers of each pod with --show-manager
+func TestRenderManagersGolden(t *testing.T) {
+	results := fixturePodResults()
+	results[0].Pods[0].Managers = []string{"argocd-controller", "kube-controller-manager"}
+	results[0].Pods[1].Managers = []string{"kubectl-run"}
+
+	var buf bytes.Buffer
+	require.NoError(t, testTableRenderer(K8sSearchConfig{ShowManager: true}).RenderPodResults(&buf, results))
+	assertGolden(t, "pod_results_managers_table.golden", buf.Bytes())
+}
+
+// TestRenderOverlappingSelectorsGolden tests warning about pods selected by more than one service
+func TestRenderOverlappingSelectorsGolden(t *testing.T) {
+	renderer := testTableRenderer(K8sSearchConfig{})
+	services := append(fixtureServices(), k8s.ServiceInfo{Name: "nginx-canary", Namespace: "default", Selector: map[string]string{"tier": "web"}})
+	renderer.fronting = func(contextName string) frontingResolver {
+		return func(pod k8s.PodInfo) []string {
+			names := []string{}
+			for _, svc := range k8s.ServicesSelectingPod(services, pod) {
+				names = append(names, svc.Name)
+			}
+			return names
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderer.RenderPodResults(&buf, fixturePodResults()))
+	assertGolden(t, "pod_results_overlapping_selectors_table.golden", buf.Bytes())
+}
+
+// TestRenderMatchedPortsGolden tests the column listing the container ports a port search matched
+func TestRenderMatchedPortsGolden(t *testing.T) {
+	pods := fixturePods()
+	pods[0].MatchedPorts = []string{"nginx: 80/TCP (http)"}
+	results := []k8s.SearchResultWithContext{
+		{Context: "prod", Namespace: "default", Pods: pods[:1], Services: fixtureServices()},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, testTableRenderer(K8sSearchConfig{}).RenderIPResults(&buf, results))
+	assertGolden(t, "port_results_matched_ports_table.golden", buf.Bytes())
+}
+
+// TestRenderIngressResultsGolden tests ingress search result rendering in table and csv output
+func TestRenderIngressResultsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testTableRenderer(K8sSearchConfig{}).RenderIngressResults(&buf, fixtureIngressResults()))
+	assertGolden(t, "ingress_results_table.golden", buf.Bytes())
+
+	buf.Reset()
+	require.NoError(t, structuredRenderer{config: K8sSearchConfig{OutputFormat: OutputCSV}}.RenderIngressResults(&buf, fixtureIngressResults()))
+	assertGolden(t, "ingress_results_csv.golden", buf.Bytes())
+}
+
+// TestQuiet tests that --quiet leaves only the results while notices about skipped contexts are still shown
+func TestQuiet(t *testing.T) {
+	var out, errOut bytes.Buffer
+	config := K8sSearchConfig{Out: &out, Err: &errOut, Namespaces: []string{"default"}}
+
+	// Without --quiet the searched namespaces and empty results are announced
+	allContextsNamespaces(&config, "name", "nginx")
+	require.NoError(t, displayPodResults(context.Background(), config, []k8s.PodResultWithContext{}, "No pods found"))
+	assert.Contains(t, errOut.String(), "Searching in specified namespaces")
+	assert.Contains(t, errOut.String(), "No pods found")
+
+	errOut.Reset()
+	config.Quiet = true
+	assert.Equal(t, []string{"default"}, allContextsNamespaces(&config, "name", "nginx"))
+	require.NoError(t, displayPodResults(context.Background(), config, []k8s.PodResultWithContext{}, "No pods found"))
+	assert.Empty(t, errOut.String())
+	assert.Same(t, &errOut, config.notices())
+	assert.Empty(t, out.String())
+}
+
+// TestErrOut tests that messages are kept out of the results written to stdout
+func TestErrOut(t *testing.T) {
+	var out, errOut bytes.Buffer
+
+	assert.Equal(t, os.Stderr, K8sSearchConfig{}.errOut())
+	assert.Equal(t, os.Stderr, K8sSearchConfig{OutputFormat: OutputJSON}.notices())
+	assert.Same(t, &errOut, K8sSearchConfig{Out: &out, Err: &errOut}.errOut())
+
+	// A custom writer without Err receives messages too, but never notices breaking structured output
+	assert.Same(t, &out, K8sSearchConfig{Out: &out}.errOut())
+	assert.Same(t, &out, K8sSearchConfig{Out: &out}.notices())
+	assert.Nil(t, K8sSearchConfig{Out: &out, OutputFormat: OutputJSON}.notices())
+	assert.Same(t, &errOut, K8sSearchConfig{Out: &out, Err: &errOut, OutputFormat: OutputJSON}.notices())
+
+	// Truncation notices go with the messages, not the results
+	config := K8sSearchConfig{Out: &out, Err: &errOut, MaxResults: 2}
+	writeTruncationNotice(config, 2)
+	assert.Empty(t, out.String())
+	assert.Contains(t, errOut.String(), "Results truncated at 2")
+}
+
+// TestRendererOverride tests that a custom renderer receives the results and the configured writer
+func TestRendererOverride(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := &recordingRenderer{}
+	config := K8sSearchConfig{Out: &buf, Renderer: renderer}
+
+	require.NoError(t, displayPodResults(context.Background(), config, []k8s.PodResultWithContext{}, "not found"))
+	assert.Equal(t, 1, renderer.calls)
+	assert.Same(t, &buf, renderer.w)
+	assert.Empty(t, buf.String())
+}
+
+// recordingRenderer records the calls made to it
+type recordingRenderer struct {
+	calls int
+	w     io.Writer
+}
+
+func (r *recordingRenderer) RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+func (r *recordingRenderer) RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+func (r *recordingRenderer) RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+func (r *recordingRenderer) RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+func (r *recordingRenderer) RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+func (r *recordingRenderer) RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error {
+	r.calls++
+	r.w = w
+	return nil
+}
+
+// TestFormatCronJobOwner tests the Owner Name of pods whose Job was spawned by a CronJob
+func TestFormatCronJobOwner(t *testing.T) {
+	assert.Equal(t, "backup-28341 (CronJob: backup, schedule: 0 2 * * *)", formatCronJobOwner("backup-28341", "backup", "0 2 * * *"))
+	assert.Equal(t, "backup-28341 (CronJob: backup)", formatCronJobOwner("backup-28341", "backup", ""))
+}
+
+// TestContextResolvers tests that owners are resolved after the search's context expired,
+// with one client per context and one lookup per owner
+func TestContextResolvers(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"ReplicaSet","apiVersion":"apps/v1","metadata":{"name":"web-7d9c","namespace":"default",`+
+			`"ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"web","uid":"1"}]}}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	renderer := NewTableRenderer(ctx, K8sSearchConfig{KubeconfigPath: writeServerKubeconfig(t, server.URL)})
+
+	pod := k8s.PodInfo{Name: "web-7d9c-abcde", Namespace: "default", OwnerKind: "ReplicaSet", OwnerName: "web-7d9c"}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "web-7d9c (Deployment: web)", renderer.owner("prod")(pod))
+	}
+	assert.Equal(t, 1, requests)
+
+	// Pods without a ReplicaSet or Job owner need no lookup, and contexts without a client keep the owner name
+	assert.Equal(t, "debug", renderer.owner("prod")(k8s.PodInfo{OwnerKind: "Pod", OwnerName: "debug"}))
+	assert.Equal(t, "web-7d9c", renderer.owner("missing")(pod))
+	assert.Equal(t, 1, requests)
+}
+
+// TestFormatPodName tests marking terminated pods, with their deletion time unless the output is quiet
+func TestFormatPodName(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, "web-1", formatPodName(K8sSearchConfig{}, k8s.PodInfo{Name: "web-1", Phase: "Running"}))
+	assert.Equal(t, "job-1 (terminated)", formatPodName(K8sSearchConfig{}, k8s.PodInfo{Name: "job-1", Phase: "Succeeded", Terminated: true}))
+	deleting := k8s.PodInfo{Name: "web-0", Phase: "Running", Terminated: true, DeletedAt: &deletedAt}
+	assert.Equal(t, "web-0 (terminated, deleted at 2024-05-01T12:00:00Z)", formatPodName(K8sSearchConfig{}, deleting))
+	assert.Equal(t, "web-0 (terminated)", formatPodName(K8sSearchConfig{Quiet: true}, deleting))
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var resourceCSVHeader = []string{"Context", "Namespace", "Kind", "API Version", "Name", "Created"}
+
+// SearchK8sResourcesAllContexts searches objects of the config.Resource kind, e.g. custom resources like
+// cert-manager.io/v1/Certificate, by name across all contexts and all (or specified) namespaces
+func SearchK8sResourcesAllContexts(config K8sSearchConfig, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", k8s.ErrInvalidQuery)
+	}
+
+	gvk, err := k8s.ParseResourceKind(config.Resource)
+	if err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	namespaces := allContextsNamespaces(&config, strings.ToLower(gvk.Kind)+" name", name)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	results, err := k8s.SearchResourcesAllContexts(ctx, config.KubeconfigPath, gvk, name, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupResourceResults(results)
+	}
+
+	return displayResourceResults(ctx, config, results, fmt.Sprintf("No %ss found %s: %s across all contexts and namespaces", gvk.Kind, config.nameMatch(), name))
+}
+
+// displayResourceResults prints resource search results in the configured output format
+func displayResourceResults(ctx context.Context, config K8sSearchConfig, results []k8s.ResourceResultWithContext, notFound string) error {
+	defer writeTruncationNotice(config, summarizeResourceResults(results).total())
+	defer writeNoAccessNotice(config, summarizeResourceResults(results).total())
+	defer writeSummaryJSON(config, summarizeResourceResults(results))
+	defer config.history.setMatches(summarizeResourceResults(results))
+
+	if config.CountOnly {
+		return writeSummary(config, summarizeResourceResults(results))
+	}
+
+	// A custom renderer takes over all output, including empty results
+	if config.Renderer == nil && config.isTableOutput() && len(results) == 0 {
+		if !config.Quiet {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
+		}
+		return nil
+	}
+	return config.renderer(ctx).RenderResourceResults(config.out(), results)
+}
+
+// writeResourceResults writes resource search results in a non-table output format
+func writeResourceResults(w io.Writer, config K8sSearchConfig, results []k8s.ResourceResultWithContext) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, result := range results {
+			for i := range result.Resources {
+				record := jsonlRecord{Kind: result.Resources[i].Kind, Context: result.Context, Server: result.Server, Namespace: result.Namespace, Resource: &result.Resources[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		rows := [][]string{}
+		for _, result := range results {
+			for _, resource := range result.Resources {
+				rows = append(rows, []string{
+					result.Context,
+					result.Namespace,
+					resource.Kind,
+					resource.APIVersion,
+					resource.Name,
+					resource.CreatedAt.UTC().Format(time.RFC3339),
+				})
+			}
+		}
+
+		if config.OutputDir != "" {
+			return writeCSVFile(config.OutputDir, "resources.csv", resourceCSVHeader, rows)
+		}
+		return writeCSV(w, resourceCSVHeader, rows)
+	}
+	return writeResults(w, config, results, summarizeResourceResults(results))
+}
+
+// renderResourceTable renders resources as a table with their creation time
+func renderResourceTable(resources []k8s.ResourceInfo) string {
+	resourceTable := table.Table{}
+	resourceTable.SetStyle(tableStyle())
+	resourceTable.AppendRow(table.Row{"Name", "API Version", "Created", "Age"})
+
+	for _, resource := range resources {
+		resourceTable.AppendRow(table.Row{
+			resource.Name,
+			resource.APIVersion,
+			resource.CreatedAt.UTC().Format(time.RFC3339),
+			formatAgeSince(resource.CreatedAt.Time),
+		})
+	}
+	return resourceTable.Render()
+}
+
+// summarizeResourceResults counts resources in resource search results
+func summarizeResourceResults(results []k8s.ResourceResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results), Resources: new(int)}
+	for _, result := range results {
+		*summary.Resources += len(result.Resources)
+	}
+	return summary
+}
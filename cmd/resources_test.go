@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fixtureResourceResults returns cert-manager Certificates in two namespaces
+func fixtureResourceResults() []k8s.ResourceResultWithContext {
+	created := metav1.NewTime(fixtureTime.Add(-72 * time.Hour))
+	return []k8s.ResourceResultWithContext{
+		{Context: "prod", Namespace: "default", Resources: []k8s.ResourceInfo{
+			{Kind: "Certificate", APIVersion: "cert-manager.io/v1", Name: "web-tls", Namespace: "default", CreatedAt: created},
+		}},
+		{Context: "prod", Namespace: "payments", Resources: []k8s.ResourceInfo{
+			{Kind: "Certificate", APIVersion: "cert-manager.io/v1", Name: "web-tls-legacy", Namespace: "payments", CreatedAt: created},
+		}},
+	}
+}
+
+// TestRenderResourceResultsGolden tests resource search result rendering in table and structured output
+func TestRenderResourceResultsGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"resource_results_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"resource_results_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+		{"resource_results_jsonl.golden", K8sSearchConfig{OutputFormat: OutputJSONL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			renderer := Renderer(structuredRenderer{config: tt.config})
+			if tt.config.isTableOutput() {
+				renderer = testTableRenderer(tt.config)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.RenderResourceResults(&buf, fixtureResourceResults()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestResourceSummary tests that resource counts replace the pod count in the summary
+func TestResourceSummary(t *testing.T) {
+	summary := summarizeResourceResults(fixtureResourceResults())
+	assert.Equal(t, 2, summary.total())
+
+	var buf bytes.Buffer
+	printSummary(&buf, summary)
+	assert.Contains(t, buf.String(), "Total resources found: 2")
+	assert.NotContains(t, buf.String(), "pods")
+
+	assert.Error(t, validateOutput(K8sSearchConfig{Resource: "cert-manager.io/v1/Certificate", Interactive: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Resource: "cert-manager.io/v1/Certificate", OutputFormat: OutputGraph}))
+}
+
+// TestSearchResourcesInvalidResource tests that a malformed --resource is rejected before searching
+func TestSearchResourcesInvalidResource(t *testing.T) {
+	err := SearchK8sResourcesAllContexts(K8sSearchConfig{Resource: "Certificate"}, "web")
+	require.Error(t, err)
+	assert.Equal(t, ExitInvalidInput, ExitCode(err))
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// searchServer serves search requests over HTTP using defaults from the CLI flags
+type searchServer struct {
+	defaults K8sSearchConfig
+}
+
+// errorResponse is the JSON body returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Serve starts an HTTP server exposing the search functions.
+// Flags in config act as defaults that each request can override with query parameters.
+func Serve(config K8sSearchConfig, listen string) error {
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	// Requests run concurrently in the background, so no progress is drawn
+	config.NoProgress = true
+	server := &searchServer{defaults: config}
+
+	// Record search metrics for the /metrics endpoint
+	registry := prometheus.NewRegistry()
+	k8s.SetMetricsCollector(newPrometheusMetrics(registry))
+	defer k8s.SetMetricsCollector(nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", server.handleHealthz)
+	mux.HandleFunc("/search", server.handleSearch)
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintln(config.errOut(), text.FgCyan.Sprintf("Listening on %s", listen))
+	return httpServer.ListenAndServe()
+}
+
+// handleHealthz reports that the server is up
+func (s *searchServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleSearch handles GET /search?ip=...|name=...|uid=...[&context=...][&namespaces=a,b]
+func (s *searchServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	config := s.requestConfig(query.Get("context"), query.Get("namespaces"))
+
+	ip, name, uid := query.Get("ip"), query.Get("name"), query.Get("uid")
+	set := 0
+	for _, value := range []string{ip, name, uid} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "exactly one of ip, name or uid is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 120*time.Second)
+	defer cancel()
+
+	var (
+		results interface{}
+		err     error
+	)
+	switch {
+	case ip != "":
+		if err := validateIPQuery(config, ip); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		results, err = s.searchByIP(ctx, config, ip)
+	case name != "":
+		results, err = s.searchPods(config, func() ([]k8s.PodResultWithContext, error) {
+			return k8s.SearchByNameAllContexts(ctx, config.KubeconfigPath, name, config.Namespaces, config.contexts(), config.searchOptions())
+		})
+	default:
+		results, err = s.searchPods(config, func() ([]k8s.PodResultWithContext, error) {
+			return k8s.SearchByUIDAllContexts(ctx, config.KubeconfigPath, uid, config.Namespaces, config.contexts(), config.searchOptions())
+		})
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// requestConfig builds the search config for a request, applying query parameter overrides.
+// A fresh copy is returned so concurrent requests never share mutable state.
+func (s *searchServer) requestConfig(contextName string, namespaces string) K8sSearchConfig {
+	config := s.defaults
+	config.Namespaces = append([]string{}, s.defaults.Namespaces...)
+
+	if contextName != "" {
+		config.ContextName = contextName
+	}
+	if namespaces != "" {
+		config.Namespaces = []string{}
+		for _, ns := range strings.Split(namespaces, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				config.Namespaces = append(config.Namespaces, ns)
+			}
+		}
+	}
+	return config
+}
+
+// searchByIP runs an all-contexts IP search and applies deduplication
+func (s *searchServer) searchByIP(ctx context.Context, config K8sSearchConfig, ip string) ([]k8s.SearchResultWithContext, error) {
+	results, err := k8s.SearchByIPAllContexts(ctx, config.KubeconfigPath, ip, config.Namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.NoDedup {
+		results = k8s.DedupIPResults(results)
+	}
+	return results, nil
+}
+
+// searchPods runs an all-contexts pod search and applies deduplication
+func (s *searchServer) searchPods(config K8sSearchConfig, search func() ([]k8s.PodResultWithContext, error)) ([]k8s.PodResultWithContext, error) {
+	results, err := search()
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.NoDedup {
+		results = k8s.DedupPodResults(results)
+	}
+	return results, nil
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// serveTestServer starts the HTTP search server searching a fake API server, which has one pod named
+// web-<namespace> in each namespace
+func serveTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		namespace := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")[0]
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pods"):
+			json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-" + namespace, Namespace: namespace, UID: types.UID("uid-" + namespace)},
+				Status:     corev1.PodStatus{PodIP: "10.0.0.1", Phase: corev1.PodRunning},
+			}}})
+		case strings.HasSuffix(r.URL.Path, "/services"):
+			json.NewEncoder(w).Encode(corev1.ServiceList{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	server := &searchServer{defaults: K8sSearchConfig{KubeconfigPath: writeServerKubeconfig(t, api.URL), Namespaces: []string{"default"}, NoProgress: true}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", server.handleSearch)
+	search := httptest.NewServer(mux)
+	t.Cleanup(search.Close)
+	return search
+}
+
+// TestHandleSearchErrors tests the status codes of requests without exactly one query or not using GET
+func TestHandleSearchErrors(t *testing.T) {
+	server := serveTestServer(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		expected int
+	}{
+		{"no query", http.MethodGet, "", http.StatusBadRequest},
+		{"ip and name", http.MethodGet, "ip=10.0.0.1&name=web", http.StatusBadRequest},
+		{"all three", http.MethodGet, "ip=10.0.0.1&name=web&uid=uid-default", http.StatusBadRequest},
+		{"invalid ip", http.MethodGet, "ip=not-an-ip", http.StatusBadRequest},
+		{"post", http.MethodPost, "name=web", http.StatusMethodNotAllowed},
+		{"delete", http.MethodDelete, "name=web", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/search?"+tt.query, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expected, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			var body errorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NotEmpty(t, body.Error)
+		})
+	}
+}
+
+// TestHandleSearch tests the JSON results of searches by name, UID and IP
+func TestHandleSearch(t *testing.T) {
+	server := serveTestServer(t)
+
+	for _, query := range []string{"name=web", "uid=uid-default", "ip=10.0.0.1"} {
+		t.Run(query, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/search?" + query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var results []map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+			require.Len(t, results, 1)
+			assert.Equal(t, "prod", results[0]["context"])
+			assert.Equal(t, "default", results[0]["namespace"])
+			pods, ok := results[0]["pods"].([]interface{})
+			require.True(t, ok, "results have a pods array")
+			require.Len(t, pods, 1)
+			assert.Equal(t, "web-default", pods[0].(map[string]interface{})["name"])
+		})
+	}
+}
+
+// TestHandleSearchConcurrent tests that concurrent requests overriding the namespaces each search only their own
+func TestHandleSearchConcurrent(t *testing.T) {
+	server := serveTestServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		namespace := fmt.Sprintf("team-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/search?name=web&namespaces=" + namespace)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			var results []map[string]interface{}
+			if assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results)) && assert.Len(t, results, 1) {
+				assert.Equal(t, namespace, results[0]["namespace"])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestRequestConfig tests that query parameters override the defaults without changing them
+func TestRequestConfig(t *testing.T) {
+	server := &searchServer{defaults: K8sSearchConfig{ContextName: "dev", Namespaces: []string{"default"}}}
+
+	config := server.requestConfig("prod", " shop, ,payments ")
+	assert.Equal(t, "prod", config.ContextName)
+	assert.Equal(t, []string{"shop", "payments"}, config.Namespaces)
+
+	config = server.requestConfig("", "")
+	assert.Equal(t, "dev", config.ContextName)
+	assert.Equal(t, []string{"default"}, config.Namespaces)
+
+	config.Namespaces[0] = "changed"
+	assert.Equal(t, []string{"default"}, server.defaults.Namespaces, "requests must not share the default namespaces")
+}
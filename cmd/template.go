@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	k8s "k8sx/pkg"
+)
+
+// parseOutputTemplate parses the Go template given with --template, so a mistake in it fails before any search
+func parseOutputTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, fmt.Errorf("--output template requires --template")
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// TemplateRenderer renders search results with a user-supplied Go template, like kubectl -o go-template.
+// The template is applied to the results slice, one element per context and namespace, with the fields
+// of the Go types, e.g. {{range .}}{{range .Pods}}{{.Name}} {{.PodIP}}{{"\n"}}{{end}}{{end}}.
+type TemplateRenderer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateRenderer creates a template renderer for the template of config,
+// which validateOutput already parsed successfully
+func NewTemplateRenderer(config K8sSearchConfig) *TemplateRenderer {
+	tmpl, _ := parseOutputTemplate(config.Template)
+	return &TemplateRenderer{tmpl: tmpl}
+}
+
+// RenderIPResults applies the template to pod and service results
+func (r *TemplateRenderer) RenderIPResults(w io.Writer, results []k8s.SearchResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// RenderPodResults applies the template to pod results
+func (r *TemplateRenderer) RenderPodResults(w io.Writer, results []k8s.PodResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// RenderIngressResults applies the template to ingress results
+func (r *TemplateRenderer) RenderIngressResults(w io.Writer, results []k8s.IngressResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// RenderWorkloadResults applies the template to workload results
+func (r *TemplateRenderer) RenderWorkloadResults(w io.Writer, results []k8s.WorkloadResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// RenderEndpointResults applies the template to endpoint results
+func (r *TemplateRenderer) RenderEndpointResults(w io.Writer, results []k8s.EndpointResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// RenderResourceResults applies the template to resource results
+func (r *TemplateRenderer) RenderResourceResults(w io.Writer, results []k8s.ResourceResultWithContext) error {
+	return r.execute(w, results)
+}
+
+// execute applies the template to results, writing nothing when it fails part way,
+// e.g. on a field the results do not have
+func (r *TemplateRenderer) execute(w io.Writer, results interface{}) error {
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, results); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
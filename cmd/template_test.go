@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplateRenderer tests applying the --template Go template to search results
+func TestTemplateRenderer(t *testing.T) {
+	config := K8sSearchConfig{
+		OutputFormat: OutputTemplate,
+		Template:     `{{range .}}{{$ctx := .Context}}{{range .Pods}}{{$ctx}} {{.Namespace}} {{.Name}} {{.PodIP}} {{.Labels.app}}{{"\n"}}{{end}}{{end}}`,
+	}
+	require.NoError(t, validateOutput(config))
+
+	var buf bytes.Buffer
+	require.NoError(t, config.renderer(context.Background()).RenderPodResults(&buf, fixturePodResults()))
+	assert.Equal(t, "prod default nginx-7d9c-abcde 10.0.0.1 nginx\nprod default debug 10.0.0.2 <no value>\n", buf.String())
+
+	// IP results also have the services
+	config.Template = `{{range .}}{{range .Services}}{{.Name}} {{.ClusterIP}}{{"\n"}}{{end}}{{end}}`
+	buf.Reset()
+	require.NoError(t, config.renderer(context.Background()).RenderIPResults(&buf, fixtureIPResults()))
+	assert.Equal(t, "nginx 10.96.0.1\nnginx 10.96.0.1\nnginx-headless None\n", buf.String())
+
+	// Execution errors write nothing
+	config.Template = `{{range .}}{{.Context}} {{.Missing}}{{end}}`
+	buf.Reset()
+	err := config.renderer(context.Background()).RenderPodResults(&buf, fixturePodResults())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to execute template")
+	assert.Contains(t, err.Error(), "Missing")
+	assert.Empty(t, buf.String())
+}
+
+// TestValidateOutputTemplate tests that templates are checked before any search
+func TestValidateOutputTemplate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config K8sSearchConfig
+		errMsg string
+	}{
+		{"missing template", K8sSearchConfig{OutputFormat: OutputTemplate}, "--output template requires --template"},
+		{"invalid template", K8sSearchConfig{OutputFormat: OutputTemplate, Template: "{{range .}}"}, "invalid template"},
+		{"template without output", K8sSearchConfig{OutputFormat: OutputJSON, Template: "{{.}}"}, "--template requires --output template"},
+		{"count", K8sSearchConfig{OutputFormat: OutputTemplate, Template: "{{.}}", CountOnly: true}, "cannot be combined with --count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutput(tt.config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// timingReport collects the per-context timings of a search for --timings
+type timingReport struct {
+	config  K8sSearchConfig
+	timings []k8s.ContextTiming
+}
+
+// startTimings starts collecting per-context timings when --timings is set. It returns nil when
+// timings are off or already collected by an enclosing search, e.g. before a fallback to a name search,
+// so only the outermost search writes the report.
+func (c *K8sSearchConfig) startTimings() *timingReport {
+	if !c.Timings || c.timings != nil {
+		return nil
+	}
+	c.timings = &timingReport{config: *c}
+	return c.timings
+}
+
+// add records the timing of a context; a nil report ignores it
+func (r *timingReport) add(timing k8s.ContextTiming) {
+	if r == nil {
+		return
+	}
+	r.timings = append(r.timings, timing)
+}
+
+// write prints the timings as a table on stderr after the results
+func (r *timingReport) write() {
+	if r == nil || len(r.timings) == 0 {
+		return
+	}
+	if w := r.config.notices(); w != nil {
+		writeTimings(w, r.timings)
+	}
+}
+
+// writeTimings renders the per-context durations and API call counts followed by their totals
+func writeTimings(w io.Writer, timings []k8s.ContextTiming) {
+	// Slowest context first, keeping the search order for equal durations
+	sorted := append([]k8s.ContextTiming{}, timings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+
+	timingTable := table.Table{}
+	timingTable.SetStyle(tableStyle())
+	timingTable.AppendRow(table.Row{"Context", "Duration", "Namespaces", "API Calls"})
+
+	var total time.Duration
+	var calls int64
+	for _, timing := range sorted {
+		duration := formatDuration(timing.Duration)
+		if timing.Skipped {
+			duration += " (unreachable)"
+		}
+		if timing.TimedOut {
+			duration += " (timed out)"
+		}
+		timingTable.AppendRow(table.Row{timing.Context, duration, timing.Namespaces, timing.APICalls})
+		total += timing.Duration
+		calls += timing.APICalls
+	}
+	timingTable.AppendRow(table.Row{"Total", formatDuration(total), "", calls})
+
+	fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Timings ==="))
+	fmt.Fprintln(w, timingTable.Render())
+}
+
+// formatDuration rounds a duration for display
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteTimingsGolden tests the timings table, slowest context first with totals
+func TestWriteTimingsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	writeTimings(&buf, []k8s.ContextTiming{
+		{Context: "dev", Duration: 420 * time.Millisecond, Namespaces: 3, APICalls: 4},
+		{Context: "prod", Duration: 2345 * time.Millisecond, Namespaces: 12, APICalls: 15},
+		{Context: "staging", Duration: 5 * time.Second, APICalls: 1, Skipped: true},
+		{Context: "local", Duration: 250 * time.Microsecond, Namespaces: 1, APICalls: 1},
+		{Context: "eu", Duration: 30 * time.Second, Namespaces: 5, APICalls: 9, TimedOut: true},
+	})
+	assertGolden(t, "timings_table.golden", buf.Bytes())
+}
+
+// TestStartTimings tests that only the outermost search collects and writes the timings
+func TestStartTimings(t *testing.T) {
+	var buf bytes.Buffer
+	config := K8sSearchConfig{Out: &buf, Timings: true}
+
+	report := config.startTimings()
+	require.NotNil(t, report)
+	assert.Nil(t, config.startTimings(), "a fallback search must not start a second report")
+
+	config.searchOptions().OnContextSearched(k8s.ContextTiming{Context: "dev", APICalls: 2})
+	report.write()
+	assert.Contains(t, buf.String(), "=== Timings ===")
+	assert.Contains(t, buf.String(), "dev")
+
+	// Without --timings nothing is collected or written
+	buf.Reset()
+	config = K8sSearchConfig{Out: &buf}
+	report = config.startTimings()
+	assert.Nil(t, report)
+	config.searchOptions().OnContextSearched(k8s.ContextTiming{Context: "dev"})
+	report.write()
+	assert.Empty(t, buf.String())
+
+	// Structured output written to a custom writer gets no timings
+	config = K8sSearchConfig{Out: &buf, OutputFormat: OutputJSON, Timings: true}
+	report = config.startTimings()
+	report.add(k8s.ContextTiming{Context: "dev"})
+	report.write()
+	assert.Empty(t, buf.String())
+}
+
+// TestAllContextsPodSearchTimings tests that pod searches across contexts report the timings of each context
+func TestAllContextsPodSearchTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"List","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	config := K8sSearchConfig{KubeconfigPath: writeServerKubeconfig(t, server.URL), Out: &bytes.Buffer{}, Err: &buf, Timings: true}
+	require.NoError(t, SearchK8sByNodeAllContexts(config, "node-1"))
+	assert.Contains(t, buf.String(), "=== Timings ===")
+	assert.Contains(t, buf.String(), "prod")
+}
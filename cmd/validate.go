@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var contextHealthCSVHeader = []string{"Context", "Server", "Config", "Reachable", "Authenticated", "Authorized", "Error"}
+
+// ValidateK8sContexts checks that every (or the specified) context can be searched: the client config
+// builds, the cluster answers, the credentials are accepted and namespaces can be listed
+func ValidateK8sContexts(config K8sSearchConfig) error {
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+	if config.OutputFormat == OutputGraph || config.OutputFormat == OutputTemplate {
+		return fmt.Errorf("%w: %s output is not supported by validate", k8s.ErrInvalidOptions, config.OutputFormat)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// Without a precheck timeout an unreachable cluster would hold the whole check until ctx expires
+	opts := config.searchOptions()
+	if opts.PrecheckTimeout <= 0 {
+		opts.PrecheckTimeout = 30 * time.Second
+	}
+
+	results, err := k8s.ValidateContexts(ctx, config.KubeconfigPath, config.contexts(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to validate contexts: %w", err)
+	}
+	return writeContextHealth(config.out(), config, results)
+}
+
+// writeContextHealth writes context health checks as a table or in a structured output format
+func writeContextHealth(w io.Writer, config K8sSearchConfig, results []k8s.ContextHealth) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, health := range results {
+			if err := writeStructured(w, OutputJSONL, health); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputJSON, OutputYAML:
+		return writeStructured(w, config.OutputFormat, results)
+	case OutputCSV:
+		rows := make([][]string, 0, len(results))
+		for _, health := range results {
+			rows = append(rows, []string{
+				health.Context,
+				health.Server,
+				string(health.Config),
+				string(health.Reachable),
+				string(health.Authenticated),
+				string(health.Authorized),
+				health.Error,
+			})
+		}
+		return writeCSV(w, contextHealthCSVHeader, rows)
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(config.errOut(), text.FgYellow.Sprintf("No contexts found in kubeconfig"))
+		return nil
+	}
+
+	healthTable := table.Table{}
+	healthTable.SetStyle(tableStyle())
+	healthTable.AppendRow(table.Row{"Context", "Server", "Config", "Reachable", "Authn", "Authz", "Error"})
+
+	healthy := 0
+	for _, health := range results {
+		if health.Healthy() {
+			healthy++
+		}
+		healthTable.AppendRow(table.Row{
+			health.Context,
+			health.Server,
+			formatHealthStatus(health.Config),
+			formatHealthStatus(health.Reachable),
+			formatHealthStatus(health.Authenticated),
+			formatHealthStatus(health.Authorized),
+			health.Error,
+		})
+	}
+
+	fmt.Fprintln(w, text.FgGreen.Sprintf("\n=== Context health ==="))
+	fmt.Fprintln(w, healthTable.Render())
+	fmt.Fprintf(w, "Healthy contexts: %d of %d\n", healthy, len(results))
+	return nil
+}
+
+// formatHealthStatus colors a health check outcome, showing checks that were not run as "-"
+func formatHealthStatus(status k8s.HealthStatus) string {
+	switch status {
+	case k8s.HealthOK:
+		return text.FgGreen.Sprint(status)
+	case k8s.HealthFailed:
+		return text.FgRed.Sprint(status)
+	}
+	return "-"
+}
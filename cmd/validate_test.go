@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureContextHealth returns health checks of a healthy context and of contexts failing each check
+func fixtureContextHealth() []k8s.ContextHealth {
+	return []k8s.ContextHealth{
+		{Context: "broken", Config: k8s.HealthFailed, Reachable: k8s.HealthNotChecked, Authenticated: k8s.HealthNotChecked, Authorized: k8s.HealthNotChecked, Error: `context "broken" not found`},
+		{Context: "dev", Server: "https://dev.example.com:6443", Config: k8s.HealthOK, Reachable: k8s.HealthFailed, Authenticated: k8s.HealthNotChecked, Authorized: k8s.HealthNotChecked, Error: "dial tcp: lookup dev.example.com: no such host"},
+		{Context: "prod", Server: "https://prod.example.com:6443", Config: k8s.HealthOK, Reachable: k8s.HealthOK, Authenticated: k8s.HealthOK, Authorized: k8s.HealthOK},
+		{Context: "staging", Server: "https://staging.example.com:6443", Config: k8s.HealthOK, Reachable: k8s.HealthOK, Authenticated: k8s.HealthOK, Authorized: k8s.HealthFailed, Error: "namespaces is forbidden"},
+	}
+}
+
+// TestWriteContextHealthGolden tests the context health output in table, json and csv output
+func TestWriteContextHealthGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		format string
+	}{
+		{"validate_table.golden", OutputTable},
+		{"validate_json.golden", OutputJSON},
+		{"validate_csv.golden", OutputCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeContextHealth(&buf, K8sSearchConfig{OutputFormat: tt.format}, fixtureContextHealth()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
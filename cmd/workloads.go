@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+var workloadCSVHeader = []string{"Context", "Namespace", "Kind", "Name", "Desired", "Ready", "Up-To-Date", "Available", "Images"}
+
+// ErrServiceKindRequiresIP is returned for --kind service with a query that is not an IP address or CIDR range
+var ErrServiceKindRequiresIP = fmt.Errorf("%w: --kind service only applies to IP searches", k8s.ErrInvalidOptions)
+
+// IsServiceKind reports whether --kind names services, which narrows IP searches to services
+func IsServiceKind(kind string) bool {
+	return k8s.IsServiceKind(kind)
+}
+
+// SearchK8sWorkloadsAllContexts searches Deployments, StatefulSets or DaemonSets (config.Kind) by name
+// across all contexts and all (or specified) namespaces
+func SearchK8sWorkloadsAllContexts(config K8sSearchConfig, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", k8s.ErrInvalidQuery)
+	}
+
+	kind, err := k8s.ParseWorkloadKind(config.Kind)
+	if err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	if err := validateOutput(config); err != nil {
+		return fmt.Errorf("%w: %w", k8s.ErrInvalidOptions, err)
+	}
+
+	if err := config.searchOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid search options: %w", err)
+	}
+
+	timings := config.startTimings()
+	defer timings.write()
+	config.startReport()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	namespaces := allContextsNamespaces(&config, strings.ToLower(kind)+" name", name)
+
+	if config.DryRun {
+		return printSearchPlan(config, namespaces)
+	}
+
+	results, err := k8s.SearchWorkloadsAllContexts(ctx, config.KubeconfigPath, kind, name, namespaces, config.contexts(), config.searchOptions())
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	// Drop duplicates reported by several contexts pointing at the same cluster
+	if !config.NoDedup {
+		results = k8s.DedupWorkloadResults(results)
+	}
+
+	return displayWorkloadResults(ctx, config, results, fmt.Sprintf("No %ss found %s: %s across all contexts and namespaces", kind, config.nameMatch(), name))
+}
+
+// displayWorkloadResults prints workload search results in the configured output format
+func displayWorkloadResults(ctx context.Context, config K8sSearchConfig, results []k8s.WorkloadResultWithContext, notFound string) error {
+	defer writeTruncationNotice(config, summarizeWorkloadResults(results).total())
+	defer writeNoAccessNotice(config, summarizeWorkloadResults(results).total())
+	defer writeSummaryJSON(config, summarizeWorkloadResults(results))
+	defer config.history.setMatches(summarizeWorkloadResults(results))
+
+	if config.CountOnly {
+		return writeSummary(config, summarizeWorkloadResults(results))
+	}
+
+	// A custom renderer takes over all output, including empty results
+	if config.Renderer == nil && config.isTableOutput() && len(results) == 0 {
+		if !config.Quiet {
+			fmt.Fprintln(config.errOut(), text.FgYellow.Sprint(notFound))
+		}
+		return nil
+	}
+	return config.renderer(ctx).RenderWorkloadResults(config.out(), results)
+}
+
+// writeWorkloadResults writes workload search results in a non-table output format
+func writeWorkloadResults(w io.Writer, config K8sSearchConfig, results []k8s.WorkloadResultWithContext) error {
+	switch config.OutputFormat {
+	case OutputJSONL:
+		for _, result := range results {
+			for i := range result.Workloads {
+				record := jsonlRecord{Kind: result.Workloads[i].Kind, Context: result.Context, Server: result.Server, Namespace: result.Namespace, Workload: &result.Workloads[i]}
+				if err := writeStructured(w, OutputJSONL, record); err != nil {
+					return err
+				}
+			}
+			if err := flushResult(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		rows := [][]string{}
+		for _, result := range results {
+			for _, workload := range result.Workloads {
+				rows = append(rows, []string{
+					result.Context,
+					result.Namespace,
+					workload.Kind,
+					workload.Name,
+					fmt.Sprintf("%d", workload.Desired),
+					fmt.Sprintf("%d", workload.Ready),
+					fmt.Sprintf("%d", workload.UpToDate),
+					fmt.Sprintf("%d", workload.Available),
+					strings.Join(workload.Images, ","),
+				})
+			}
+		}
+
+		if config.OutputDir != "" {
+			return writeCSVFile(config.OutputDir, "workloads.csv", workloadCSVHeader, rows)
+		}
+		return writeCSV(w, workloadCSVHeader, rows)
+	}
+	return writeResults(w, config, results, summarizeWorkloadResults(results))
+}
+
+// renderWorkloadTable renders workloads as a table, highlighting those with fewer ready replicas than desired
+func renderWorkloadTable(workloads []k8s.WorkloadInfo) string {
+	workloadTable := table.Table{}
+	workloadTable.SetStyle(tableStyle())
+	workloadTable.AppendRow(table.Row{"Name", "Ready", "Up-To-Date", "Available", "Images", "Age"})
+
+	for _, workload := range workloads {
+		ready := fmt.Sprintf("%d/%d", workload.Ready, workload.Desired)
+		if workload.Ready < workload.Desired {
+			ready = text.FgYellow.Sprint(ready)
+		}
+		workloadTable.AppendRow(table.Row{
+			workload.Name,
+			ready,
+			workload.UpToDate,
+			workload.Available,
+			strings.Join(workload.Images, "\n"),
+			formatAgeSince(workload.CreatedAt.Time),
+		})
+	}
+	return workloadTable.Render()
+}
+
+// summarizeWorkloadResults counts workloads in workload search results
+func summarizeWorkloadResults(results []k8s.WorkloadResultWithContext) searchSummary {
+	summary := searchSummary{Contexts: len(results), Workloads: new(int)}
+	for _, result := range results {
+		*summary.Workloads += len(result.Workloads)
+	}
+	return summary
+}
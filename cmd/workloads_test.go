@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	k8s "k8sx/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fixtureWorkloadResults returns Deployments in two contexts, one of them not fully ready
+func fixtureWorkloadResults() []k8s.WorkloadResultWithContext {
+	created := metav1.NewTime(fixtureTime.Add(-72 * time.Hour))
+	return []k8s.WorkloadResultWithContext{
+		{Context: "dev", Namespace: "default", Workloads: []k8s.WorkloadInfo{
+			{Kind: k8s.WorkloadDeployment, Name: "web", Namespace: "default", Desired: 3, Ready: 2, UpToDate: 3, Available: 2, Images: []string{"nginx:1.27", "envoy:1.30"}, CreatedAt: created},
+		}},
+		{Context: "prod", Namespace: "web", Workloads: []k8s.WorkloadInfo{
+			{Kind: k8s.WorkloadDeployment, Name: "web", Namespace: "web", Desired: 5, Ready: 5, UpToDate: 5, Available: 5, Images: []string{"nginx:1.27"}, CreatedAt: created},
+			{Kind: k8s.WorkloadDeployment, Name: "web-canary", Namespace: "web", Desired: 1, Ready: 1, UpToDate: 1, Available: 1, Images: []string{"nginx:1.28"}},
+		}},
+	}
+}
+
+// TestRenderWorkloadResultsGolden tests workload search result rendering in table and structured output
+func TestRenderWorkloadResultsGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		config K8sSearchConfig
+	}{
+		{"workload_results_table.golden", K8sSearchConfig{OutputFormat: OutputTable}},
+		{"workload_results_csv.golden", K8sSearchConfig{OutputFormat: OutputCSV}},
+		{"workload_results_jsonl.golden", K8sSearchConfig{OutputFormat: OutputJSONL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			renderer := Renderer(structuredRenderer{config: tt.config})
+			if tt.config.isTableOutput() {
+				renderer = testTableRenderer(tt.config)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.RenderWorkloadResults(&buf, fixtureWorkloadResults()))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+// TestWorkloadSummary tests that workload counts replace the pod count in the summary
+func TestWorkloadSummary(t *testing.T) {
+	summary := summarizeWorkloadResults(fixtureWorkloadResults())
+	assert.Equal(t, 3, summary.total())
+
+	var buf bytes.Buffer
+	printSummary(&buf, summary)
+	assert.Contains(t, buf.String(), "Total workloads found: 3")
+	assert.NotContains(t, buf.String(), "pods")
+
+	assert.Error(t, validateOutput(K8sSearchConfig{Kind: "deployment", Interactive: true}))
+	assert.Error(t, validateOutput(K8sSearchConfig{Kind: "deployment", Aggregate: true}))
+}
+
+// TestSearchWorkloadsInvalidKind tests that an unsupported --kind is rejected before searching
+func TestSearchWorkloadsInvalidKind(t *testing.T) {
+	err := SearchK8sWorkloadsAllContexts(K8sSearchConfig{Kind: "job"}, "web")
+	require.Error(t, err)
+	assert.Equal(t, ExitInvalidInput, ExitCode(err))
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	cmdk8s "k8sx/cmd"
 
@@ -11,9 +11,72 @@ import (
 )
 
 var (
-	kubeconfigPath string
-	namespaces     []string
-	contextName    string
+	configPath      string
+	kubeconfigPath  string
+	namespacesFile  string
+	namespaces      []string
+	allNamespaces   bool
+	contextName     string
+	contextGroup    string
+	groupContexts   []string
+	outputFormat    string
+	outputDir       string
+	outputFile      string
+	noDedup         bool
+	showContainers  bool
+	uidSearch       bool
+	listenAddr      string
+	countOnly       bool
+	interactive     bool
+	noColor         bool
+	noProgress      bool
+	maxResults      int
+	aggregate       bool
+	hostIPOnly      bool
+	timings         bool
+	workloadKind    string
+	exactName       bool
+	contextTimeout  time.Duration
+	execTimeout     time.Duration
+	quiet           bool
+	execCommand     string
+	matchContainers bool
+	confirmExec     bool
+	columns         []string
+	contextConc     int
+	namespaceConc   int
+	qps             float32
+	burst           int
+	retries         int
+	since           time.Duration
+	fieldSelector   string
+	annotations     []string
+	ownerKinds      []string
+	dryRun          bool
+	precheckTimeout time.Duration
+	podNamespace    string
+	eventLimit      int
+	containerPorts  bool
+	nsContexts      bool
+	nodeName        string
+	bothSearch      bool
+	summaryJSON     bool
+	detectDups      bool
+	runningOnly     bool
+	recordHistory   bool
+	historySize     int
+	historyLimit    int
+	onlyWithAccess  bool
+	serviceAccount  string
+	outputTemplate  string
+	grpcListenAddr  string
+	currentOnly     bool
+	firstOnly       bool
+	gzipOutput      bool
+	resourceKind    string
+	ipContains      bool
+	sortKey         string
+	showManager     bool
 )
 
 var rootCmd = &cobra.Command{
@@ -22,28 +85,41 @@ var rootCmd = &cobra.Command{
 	Long: `A tool to search Kubernetes resources by IP or name.
 Supports searching pods, services, and their relationships.
 
-If you provide a query without a subcommand, it will automatically search:
-- By IP if the query is a valid IP address
-- By name if the query is not an IP`,
-	Args: cobra.MaximumNArgs(1),
+If you provide a query without a subcommand, it will automatically search
+(checked in this order):
+- By pod UID if the query is a UUID (or --uid is set)
+- By IP if the query is a valid IP address or CIDR range
+- By service if the query is a service DNS name (<service>.<namespace>.svc.cluster.local)
+- By ingress host if the query is any other hostname (e.g. shop.example.com)
+- By name otherwise
+
+Service and hostname lookups fall back to a name search when nothing matches.`,
+	Args: queryArgs(cobra.MaximumNArgs(1)),
+	// Errors are printed once by main, which also maps them to exit codes
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags parsed fine, so later errors are not usage errors
+		cmd.SilenceUsage = true
+		if err := applyFlagDefaults(cmd); err != nil {
+			return err
+		}
+		cmdk8s.ConfigureColors(noColor)
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if nodeName != "" {
+			return runNodeSearch()
+		}
+		if serviceAccount != "" && len(args) == 0 {
+			return runServiceAccountSearch()
+		}
+
 		// If no args, show help
 		if len(args) == 0 {
 			return cmd.Help()
 		}
 
-		query := args[0]
-
-		// Auto-detect if it's an IP or name
-		if cmdk8s.ValidateIP(query) {
-			// It's an IP address
-			fmt.Println("Detected IP address, searching by IP...")
-			return cmdk8s.SearchK8sByIPAllContexts(kubeconfigPath, query, namespaces)
-		} else {
-			// It's a name
-			fmt.Println("Detected name pattern, searching by name...")
-			return cmdk8s.SearchK8sByNameAllContexts(kubeconfigPath, query, namespaces)
-		}
+		return runSearch(args[0])
 	},
 }
 
@@ -52,7 +128,7 @@ var listContextsCmd = &cobra.Command{
 	Short: "List all contexts from kubeconfig",
 	Long:  "List all available contexts from the specified kubeconfig file",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return cmdk8s.ListK8sContexts(kubeconfigPath)
+		return cmdk8s.ListK8sContexts(searchConfig())
 	},
 }
 
@@ -62,83 +138,543 @@ var listNamespacesCmd = &cobra.Command{
 	Long: `List all namespaces from the current (or specified) context.
 Shows which namespaces you have permission to list pods in.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return cmdk8s.ListK8sNamespaces(kubeconfigPath, contextName)
+		return cmdk8s.ListK8sNamespaces(searchConfig(), onlyWithAccess)
 	},
 }
 
 var searchCmd = &cobra.Command{
 	Use:   "s [query]",
-	Short: "Search for Kubernetes resources (auto-detects IP or name)",
-	Long: `Search for Kubernetes resources by IP address or name across ALL contexts and ALL namespaces.
+	Short: "Search for Kubernetes resources (auto-detects pod UID, IP, service DNS name, hostname or name)",
+	Long: `Search for Kubernetes resources by pod UID, IP address or name across ALL contexts and ALL namespaces.
 
-The search automatically detects whether your query is an IP address or a name:
-- If it's a valid IP (IPv4/IPv6): searches for pods and services by IP
+The search automatically detects what your query is, checked in this order:
+- If it's a UUID (or --uid is set): searches for the pod with that UID
+- If it's a valid IP (IPv4/IPv6) or CIDR range: searches for pods and services by IP
+- If it's a service DNS name (<service>.<namespace>.svc[.cluster.local]): looks up that service
+- If it's any other hostname: searches for ingresses routing that host
 - Otherwise: searches for pods by name (partial match)
 
+Service and hostname lookups fall back to a name search when nothing matches.
+With --node-name instead of a query it lists the pods scheduled on that node, and with
+--service-account the pods running as that service account.
+
 This is a comprehensive search that will:
-- Search in every context from kubeconfig
+- Search in every context from kubeconfig (or only the context given with --context)
 - Search in every namespace in each context (or only specified namespaces with --namespaces flag)
 - Return all matching pods and services
 
 Note: This may take a while as it searches everywhere.`,
-	Args: cobra.ExactArgs(1),
+	Args: queryArgs(cobra.ExactArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
-
-		// Auto-detect if it's an IP or name
-		if cmdk8s.ValidateIP(query) {
-			// It's an IP address
-			fmt.Println("Detected IP address, searching by IP...")
-			return cmdk8s.SearchK8sByIPAllContexts(kubeconfigPath, query, namespaces)
-		} else {
-			// It's a name
-			fmt.Println("Detected name pattern, searching by name...")
-			return cmdk8s.SearchK8sByNameAllContexts(kubeconfigPath, query, namespaces)
+		if nodeName != "" {
+			return runNodeSearch()
 		}
+		if serviceAccount != "" && len(args) == 0 {
+			return runServiceAccountSearch()
+		}
+		return runSearch(args[0])
 	},
 }
 
-func init() {
-	// Get default kubeconfig path from environment or default location
-	defaultKubeconfig := os.Getenv("KUBECONFIG")
-	if defaultKubeconfig == "" {
-		homeDir, err := os.UserHomeDir()
+var portCmd = &cobra.Command{
+	Use:   "port [port]",
+	Short: "Search for services exposing a port",
+	Long: `Search for services exposing a port across all contexts and namespaces.
+
+A numeric port matches the service port, the target port or the node port.
+A named port (e.g. "https") matches the service port name or a named target port.
+
+With --container-ports pods whose containers declare the port are found too,
+whether or not a service selects them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.WithOutputFile(searchConfig(), func(config cmdk8s.K8sSearchConfig) error {
+			return cmdk8s.WithJSONErrors(config, func(config cmdk8s.K8sSearchConfig) error {
+				return cmdk8s.SearchK8sByPortAllContexts(config, args[0])
+			})
+		})
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that every context in kubeconfig can be searched",
+	Long: `Check every context (or those selected with --context/--group) before a search:
+whether its client config can be built from the kubeconfig, whether its cluster
+answers a discovery ping, whether the credentials are accepted and whether they
+can list namespaces.
+
+Contexts failing a check are the ones searches skip; the error column says why.
+Each context is given --precheck-timeout to answer.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.ValidateK8sContexts(searchConfig())
+	},
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Show a condensed description of a resource",
+}
+
+var describePodCmd = &cobra.Command{
+	Use:   "pod [name]",
+	Short: "Show a condensed description of a pod",
+	Long: `Show labels, annotations, owner chain, node, IPs, phase, container statuses
+and the most recent events of a single pod.
+
+The pod is looked up in the current (or --context) context and in the namespace
+given with -n, falling back to the context's default namespace.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.DescribeK8sPod(searchConfig(), podNamespace, args[0], eventLimit)
+	},
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start an HTTP server exposing the search API",
+	Long: `Start an HTTP server exposing the search functions as a JSON API.
+
+Endpoints:
+- GET /search?ip=<ip>     searches pods and services by IP or CIDR range
+- GET /search?name=<name> searches pods by name (partial match)
+- GET /search?uid=<uid>   searches a pod by UID
+- GET /healthz            health check
+- GET /metrics            Prometheus metrics (search latency, namespaces scanned, matches, permission skips)
+
+The --kubeconfig, --context and --namespaces flags are used as defaults for every
+request; the context and namespaces query parameters override them per request.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.Serve(searchConfig(), listenAddr)
+	},
+}
+
+var grpcServeCmd = &cobra.Command{
+	Use:   "grpc-serve",
+	Short: "Start a gRPC server exposing the search API",
+	Long: `Start a gRPC server exposing the search functions as the k8sx.v1.SearchService
+defined in api/searchpb/search.proto.
+
+Search takes an ip (IP or CIDR range) or a name with optional filters, and streams the
+matches of each namespace as soon as it is searched. The server also serves the standard
+gRPC health service and server reflection, e.g. for grpcurl:
+
+  grpcurl -plaintext -d '{"ip": "10.0.0.1"}' localhost:9090 k8sx.v1.SearchService/Search
+
+The --kubeconfig, --context and --namespaces flags are used as defaults for every
+request; the filters of a request override them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.GRPCServe(searchConfig(), grpcListenAddr)
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recent searches",
+	Long: `List the most recent searches with the contexts they searched and the number
+of matches they found, most recent first.
+
+Searches are only recorded with --history (or history: true in the config file),
+in history.jsonl under the user cache directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmdk8s.ListSearchHistory(searchConfig(), cmdk8s.DefaultHistoryPath(), historyLimit)
+	},
+}
+
+// flagEnvVars maps the flags that can be preset through environment variables to those variables
+var flagEnvVars = map[string]string{
+	"kubeconfig": "KUBECONFIG",
+	"namespaces": "K8S_SEARCH_NAMESPACES",
+	"context":    "K8S_SEARCH_CONTEXT",
+}
+
+// applyFlagDefaults fills the persistent flags not given on the command line,
+// with the precedence: flag > environment variable > config file > built-in default
+func applyFlagDefaults(cmd *cobra.Command) error {
+	path, explicit := configPath, configPath != ""
+	if !explicit {
+		path = cmdk8s.DefaultConfigPath()
+	}
+
+	file, err := cmdk8s.LoadConfigFile(path, explicit)
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Flags["config"]; ok {
+		return fmt.Errorf("the config file cannot set config")
+	}
+	if err := cmdk8s.ApplyFlagDefaults(cmd.Root().PersistentFlags(), flagEnvVars, file.Flags); err != nil {
+		return err
+	}
+
+	// --namespaces-from-file adds to the namespaces given inline or preset
+	if namespacesFile != "" {
+		fileNamespaces, err := cmdk8s.LoadNamespacesFile(namespacesFile)
 		if err != nil {
-			// Fallback to /root/.kube/config on Unix-like systems
-			// This is a reasonable default for containerized environments
-			defaultKubeconfig = "/root/.kube/config"
-		} else {
-			defaultKubeconfig = homeDir + "/.kube/config"
+			return err
 		}
+		namespaces = cmdk8s.MergeNamespaces(namespaces, fileNamespaces)
 	}
 
-	// Get default namespaces from environment or use empty (auto-discover)
-	var defaultNamespaces []string
-	if envNamespaces := os.Getenv("K8S_SEARCH_NAMESPACES"); envNamespaces != "" {
-		defaultNamespaces = strings.Split(envNamespaces, ",")
-		// Trim whitespace from each namespace
-		for i := range defaultNamespaces {
-			defaultNamespaces[i] = strings.TrimSpace(defaultNamespaces[i])
+	// --group expands to the contexts listed under groups in the config file
+	if contextGroup != "" {
+		if groupContexts, err = file.GroupContexts(contextGroup); err != nil {
+			return err
 		}
 	}
 
-	// Get default context from environment
-	defaultContext := os.Getenv("K8S_SEARCH_CONTEXT")
+	// --current narrows all-contexts searches to the current context like a group of one
+	if currentOnly {
+		current, err := cmdk8s.CurrentContext(kubeconfigPath)
+		if err != nil {
+			return err
+		}
+		groupContexts = []string{current}
+	}
+	return nil
+}
+
+// searchConfig builds the search configuration from the persistent flags
+func searchConfig() cmdk8s.K8sSearchConfig {
+	// --all-namespaces overrides namespaces preset through K8S_SEARCH_NAMESPACES or the config file
+	searchNamespaces := namespaces
+	if allNamespaces {
+		searchNamespaces = nil
+	}
+
+	// --group and --current override a context preset through K8S_SEARCH_CONTEXT or the config file
+	searchContext := contextName
+	if contextGroup != "" || currentOnly {
+		searchContext = ""
+	}
+
+	historyFile := ""
+	if recordHistory {
+		historyFile = cmdk8s.DefaultHistoryPath()
+	}
+
+	return cmdk8s.K8sSearchConfig{
+		KubeconfigPath:       kubeconfigPath,
+		Namespaces:           searchNamespaces,
+		AllNamespaces:        allNamespaces,
+		ContextName:          searchContext,
+		Contexts:             groupContexts,
+		OutputFormat:         outputFormat,
+		OutputDir:            outputDir,
+		OutputFile:           outputFile,
+		Gzip:                 gzipOutput,
+		NoDedup:              noDedup,
+		ShowContainers:       showContainers,
+		CountOnly:            countOnly,
+		Interactive:          interactive,
+		Retries:              retries,
+		Since:                since,
+		RunningOnly:          runningOnly,
+		ServiceAccount:       serviceAccount,
+		Template:             outputTemplate,
+		First:                firstOnly,
+		HistoryFile:          historyFile,
+		HistorySize:          historySize,
+		FieldSelector:        fieldSelector,
+		Annotations:          annotations,
+		OwnerKinds:           ownerKinds,
+		DryRun:               dryRun,
+		PrecheckTimeout:      precheckTimeout,
+		ContextTimeout:       contextTimeout,
+		ExecTimeout:          execTimeout,
+		Quiet:                quiet,
+		Exec:                 execCommand,
+		MatchContainers:      matchContainers,
+		ContainerPorts:       containerPorts,
+		ContextFromNamespace: nsContexts,
+		Both:                 bothSearch,
+		SummaryJSON:          summaryJSON,
+		DetectDuplicates:     detectDups,
+		Confirm:              confirmExec,
+		NoProgress:           noProgress,
+		MaxResults:           maxResults,
+		Aggregate:            aggregate,
+		HostIPOnly:           hostIPOnly,
+		Timings:              timings,
+		Kind:                 workloadKind,
+		Resource:             resourceKind,
+		IPContains:           ipContains,
+		Sort:                 sortKey,
+		ShowManager:          showManager,
+		ExactName:            exactName,
+		Columns:              columns,
+		ContextConcurrency:   contextConc,
+		NamespaceConcurrency: namespaceConc,
+		QPS:                  qps,
+		Burst:                burst,
+	}
+}
+
+// runSearch searches for query, writing the results to --output-file when it is set,
+// json output describing the error when the search fails and the search to the history with --history
+func runSearch(query string) error {
+	return cmdk8s.WithHistory(searchConfig(), query, func(config cmdk8s.K8sSearchConfig) error {
+		return cmdk8s.WithOutputFile(config, func(config cmdk8s.K8sSearchConfig) error {
+			return cmdk8s.WithJSONErrors(config, func(config cmdk8s.K8sSearchConfig) error {
+				return searchQuery(config, query)
+			})
+		})
+	})
+}
+
+// queryArgs validates the query argument with validate, unless --node-name or --service-account
+// takes the place of the query
+func queryArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if serviceAccount != "" && len(args) == 0 {
+			return nil
+		}
+		if nodeName == "" {
+			return validate(cmd, args)
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("--node-name cannot be combined with a query")
+		}
+		return nil
+	}
+}
+
+// runServiceAccountSearch lists the pods running as the --service-account service account
+func runServiceAccountSearch() error {
+	return cmdk8s.WithHistory(searchConfig(), "--service-account "+serviceAccount, func(config cmdk8s.K8sSearchConfig) error {
+		return cmdk8s.WithOutputFile(config, func(config cmdk8s.K8sSearchConfig) error {
+			return cmdk8s.WithJSONErrors(config, func(config cmdk8s.K8sSearchConfig) error {
+				return cmdk8s.SearchK8sByServiceAccountAllContexts(config, serviceAccount)
+			})
+		})
+	})
+}
+
+// runNodeSearch lists the pods scheduled on the --node-name node
+func runNodeSearch() error {
+	return cmdk8s.WithHistory(searchConfig(), "--node-name "+nodeName, func(config cmdk8s.K8sSearchConfig) error {
+		return cmdk8s.WithOutputFile(config, func(config cmdk8s.K8sSearchConfig) error {
+			return cmdk8s.WithJSONErrors(config, func(config cmdk8s.K8sSearchConfig) error {
+				return cmdk8s.SearchK8sByNodeAllContexts(config, nodeName)
+			})
+		})
+	})
+}
+
+// searchQuery auto-detects whether the query is a pod UID, an IP, a service DNS name, a hostname or a name
+// and runs the matching search
+func searchQuery(config cmdk8s.K8sSearchConfig, query string) error {
+	// --quiet drops the detected query kind along with the other informational messages
+	verbose := (outputFormat == "" || outputFormat == cmdk8s.OutputTable || outputFormat == cmdk8s.OutputWide) && !quiet
+
+	// --resource searches objects of any namespaced kind, e.g. custom resources, by name
+	if resourceKind != "" {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Searching resources by name...")
+		}
+		return cmdk8s.SearchK8sResourcesAllContexts(config, query)
+	}
+
+	// --kind endpoint matches IPs against the endpoint addresses of services instead of pods and services
+	if cmdk8s.IsEndpointKind(workloadKind) {
+		if !ipContains && !cmdk8s.ValidateIP(query) && !cmdk8s.ValidateCIDR(query) {
+			return cmdk8s.ErrEndpointKindRequiresIP
+		}
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Searching endpoints by IP...")
+		}
+		return cmdk8s.SearchK8sEndpointsAllContexts(config, query)
+	}
+
+	// --kind searches workloads by name instead of pods, except --kind service narrowing IP searches to services
+	if workloadKind != "" && !cmdk8s.IsServiceKind(workloadKind) {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Searching workloads by name...")
+		}
+		return cmdk8s.SearchK8sWorkloadsAllContexts(config, query)
+	}
+	if cmdk8s.IsServiceKind(workloadKind) && !ipContains && !cmdk8s.ValidateIP(query) && !cmdk8s.ValidateCIDR(query) {
+		return cmdk8s.ErrServiceKindRequiresIP
+	}
+	if detectDups && !cmdk8s.ValidateIP(query) && !cmdk8s.ValidateCIDR(query) {
+		return cmdk8s.ErrDetectDuplicatesRequiresIP
+	}
+
+	// --both takes the place of the detection heuristic, so name matches are never missed
+	if config.Both {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Searching by IP and name...")
+		}
+		return cmdk8s.SearchK8sByIPOrNameAllContexts(config, query)
+	}
+
+	// Pod UIDs are unique per cluster, so they are always searched across contexts
+	if uidSearch || cmdk8s.ValidateUID(query) {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Detected pod UID, searching by UID...")
+		}
+		return cmdk8s.SearchK8sByUIDAllContexts(config, query)
+	}
+
+	// Auto-detect if it's an IP or name. --host-ip and --ip-contains always search by IP, so other queries fail validation.
+	if cmdk8s.ValidateIP(query) || cmdk8s.ValidateCIDR(query) || hostIPOnly || ipContains {
+		// It's an IP address or range
+		if verbose && ipContains {
+			fmt.Fprintln(os.Stderr, "Searching IPs containing the query...")
+		} else if verbose {
+			fmt.Fprintln(os.Stderr, "Detected IP address, searching by IP...")
+		}
+		// An explicit context means a fast single-context search instead of fanning out
+		if config.ContextName != "" {
+			return cmdk8s.SearchK8sByIP(config, query)
+		}
+		return cmdk8s.SearchK8sByIPAllContexts(config, query)
+	}
+
+	// In-cluster service DNS names are looked up directly in their namespace
+	if cmdk8s.IsServiceDNSName(query) {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Detected service DNS name, searching by service...")
+		}
+		return cmdk8s.SearchK8sByServiceDNSAllContexts(config, query)
+	}
+
+	// Other hostnames are matched against ingress hosts
+	if cmdk8s.IsHostname(query) {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Detected hostname, searching ingress hosts...")
+		}
+		return cmdk8s.SearchK8sByHostAllContexts(config, query)
+	}
+
+	// It's a name
+	if verbose {
+		fmt.Fprintln(os.Stderr, "Detected name pattern, searching by name...")
+	}
+	if config.ContextName != "" {
+		return cmdk8s.SearchK8sByName(config, query)
+	}
+	return cmdk8s.SearchK8sByNameAllContexts(config, query)
+}
+
+func init() {
+	// Default kubeconfig path; KUBECONFIG and the config file take precedence over it
+	defaultKubeconfig := "/root/.kube/config"
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		defaultKubeconfig = homeDir + "/.kube/config"
+	}
 
 	// Persistent flags for all commands
-	rootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", defaultKubeconfig, "Path to kubeconfig file (env: KUBECONFIG)")
-	rootCmd.PersistentFlags().StringSliceVar(&namespaces, "namespaces", defaultNamespaces, "Namespaces to search (comma-separated, empty = auto-discover accessible namespaces) (env: K8S_SEARCH_NAMESPACES)")
-	rootCmd.PersistentFlags().StringVar(&contextName, "context", defaultContext, "Context to use (empty = current context) (env: K8S_SEARCH_CONTEXT)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to the config file setting defaults for these flags (default ~/.k8sx.yaml)")
+	rootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", defaultKubeconfig, "Path to kubeconfig file, or several comma-separated files whose contexts are merged, e.g. ~/.kube/config,./oneoff.yaml (env: KUBECONFIG)")
+	rootCmd.PersistentFlags().StringSliceVar(&namespaces, "namespaces", nil, "Namespaces to search (comma-separated); when empty, accessible namespaces are auto-discovered unless --all-namespaces is set (env: K8S_SEARCH_NAMESPACES)")
+	rootCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Search every namespace of each context without probing access first (namespaces you cannot read are skipped)")
+	rootCmd.PersistentFlags().StringVar(&namespacesFile, "namespaces-from-file", "", "File listing namespaces to search, one per line (# starts a comment); merged with --namespaces")
+	rootCmd.MarkFlagsMutuallyExclusive("namespaces", "all-namespaces")
+	rootCmd.MarkFlagsMutuallyExclusive("namespaces-from-file", "all-namespaces")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Context to use; searches only this context when set (empty = current context for ns, all contexts for search) (env: K8S_SEARCH_CONTEXT)")
+	rootCmd.PersistentFlags().BoolVar(&nsContexts, "context-from-namespace", false, "Search only the contexts that have one of the --namespaces, probing each context once per run (e.g. --namespaces payments-prod finds the clusters running payments)")
+	rootCmd.PersistentFlags().StringVar(&contextGroup, "group", "", "Search only the contexts of this named group from the groups section of the config file")
+	rootCmd.PersistentFlags().BoolVar(&currentOnly, "current", false, "Search only the current context of the kubeconfig, like --context with its name")
+	rootCmd.MarkFlagsMutuallyExclusive("context", "group", "current")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", cmdk8s.OutputTable, "Output format: table, wide (extra pod and service columns), json, yaml, csv, jsonl, graph (Graphviz DOT of IP search results), template (see --template)")
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go template applied to the results slice with --output template, e.g. '{{range .}}{{range .Pods}}{{.Name}} {{.PodIP}}{{\"\\n\"}}{{end}}{{end}}'")
+	rootCmd.PersistentFlags().BoolVar(&gzipOutput, "gzip", false, "Compress the output with gzip, e.g. --output json --gzip > results.json.gz (implied by an --output-file ending in .gz)")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "File to write the results to in the --output format instead of stdout; written only once the search succeeded")
+	rootCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "Directory to write pods.csv and services.csv into (only with --output csv)")
+	rootCmd.PersistentFlags().BoolVar(&noDedup, "no-dedup", false, "Keep duplicate pods/services reported by contexts pointing at the same cluster")
+	rootCmd.PersistentFlags().BoolVar(&summaryJSON, "summary-json", false, "After the result tables, print the counts of contexts, pods, services, skipped contexts/namespaces and errors as one compact json line")
+	rootCmd.PersistentFlags().BoolVar(&countOnly, "count", false, "Print only the summary counts instead of the result tables")
+	rootCmd.PersistentFlags().BoolVarP(&interactive, "interactive", "i", false, "Pick a result from an interactive filterable list and print its full details")
+	rootCmd.PersistentFlags().BoolVar(&aggregate, "aggregate", false, "Print the number of matching pods per context, namespace and top owner (e.g. Deployment) instead of one row per pod")
+	rootCmd.PersistentFlags().BoolVar(&hostIPOnly, "host-ip", false, "Match IP and CIDR queries against the host IPs of pods only, e.g. to find the pods on nodes in 10.1.2.0/24 (services are not searched)")
+	rootCmd.PersistentFlags().BoolVar(&ipContains, "ip-contains", false, "Match pod, host and service IPs containing the query instead of an exact IP or CIDR range, e.g. .137 from a truncated log line")
+	rootCmd.PersistentFlags().StringVar(&sortKey, "sort", "", "Order the pods and services of each table by podip, name, namespace, age (youngest first) or restarts; prefix with - for descending, e.g. -restarts")
+	rootCmd.PersistentFlags().StringVar(&workloadKind, "kind", "", "Search workloads of this kind by name instead of pods: deployment, statefulset or daemonset (pod filters do not apply); service narrows IP searches to services; endpoint matches IPs against service endpoint addresses")
+	rootCmd.PersistentFlags().StringVar(&resourceKind, "resource", "", "Search objects of this namespaced group/version/kind by name instead of pods, e.g. cert-manager.io/v1/Certificate or networking.istio.io/v1/VirtualService (v1/ConfigMap for the core group)")
+	rootCmd.MarkFlagsMutuallyExclusive("kind", "resource")
+	rootCmd.PersistentFlags().BoolVar(&exactName, "exact", false, "Match pod and workload names exactly instead of names containing the query, e.g. to search a pasted pod name without substring matches")
+	rootCmd.PersistentFlags().BoolVar(&matchContainers, "match-containers", false, "Also match name searches against init and ephemeral container names, showing which containers matched (e.g. pods with a debug container attached)")
+	rootCmd.PersistentFlags().StringSliceVar(&columns, "columns", nil, "Add pod table columns showing labels or annotations, e.g. label:app,anno:build/commit (missing keys are blank)")
+	rootCmd.PersistentFlags().BoolVar(&showContainers, "show-containers", false, "Show container images, readiness, restart counts and states for each pod")
+	rootCmd.PersistentFlags().BoolVar(&showManager, "show-manager", false, "Show the field managers that wrote each pod (from managedFields, e.g. argocd-controller, helm or kubectl-client-side-apply) to tell who deployed it")
+	rootCmd.PersistentFlags().DurationVar(&since, "since", 0, "Only show pods and services created within this duration (e.g. 30m, 2h)")
+	rootCmd.PersistentFlags().BoolVar(&runningOnly, "running-only", false, "Only show pods in the Running phase, ignoring pending, completed, failed and evicted pods that may hold stale IPs")
+	rootCmd.PersistentFlags().StringVar(&fieldSelector, "field-selector", "", "Server-side field selector for pod lists (e.g. status.phase=Running,spec.nodeName=node-1)")
+	rootCmd.PersistentFlags().StringArrayVar(&annotations, "annotation", nil, "Only show pods with this annotation, as key (present) or key=value (value may be a glob); repeatable")
+	rootCmd.PersistentFlags().StringArrayVar(&ownerKinds, "owner-kind", nil, "Only show pods whose top owner kind matches (e.g. Deployment, DaemonSet, StatefulSet, Job, none for standalone pods); repeatable")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the contexts and namespaces that would be searched without searching them")
+	rootCmd.PersistentFlags().DurationVar(&precheckTimeout, "precheck-timeout", 5*time.Second, "Timeout for the connectivity check done before searching each context; unreachable contexts are skipped (0 = no check)")
+	rootCmd.PersistentFlags().DurationVar(&contextTimeout, "per-context-timeout", 0, "Time limit for searching each context; a context running out of time is skipped with the matches found so far, while the whole search is still capped at 2m (0 = no limit)")
+	rootCmd.PersistentFlags().DurationVar(&execTimeout, "exec-timeout", 30*time.Second, "Time limit for the exec credential plugin of each context (e.g. aws eks get-token); a context whose plugin fails or runs out of time is skipped (0 = no limit)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 2, "Number of retries with exponential backoff for transient API errors (permission errors are never retried)")
+	rootCmd.PersistentFlags().IntVar(&maxResults, "max-results", 0, "Stop searching once this many pods/services/ingresses are found and report the results as truncated (0 = no limit)")
+	rootCmd.PersistentFlags().BoolVar(&firstOnly, "first", false, "Stop searching at the first match, cancelling the namespaces and contexts still being searched (for full pod names or UIDs)")
+	rootCmd.MarkFlagsMutuallyExclusive("first", "max-results")
+	rootCmd.PersistentFlags().IntVar(&contextConc, "context-concurrency", 1, "Number of contexts searched in parallel by all-contexts searches")
+	rootCmd.PersistentFlags().IntVar(&namespaceConc, "namespace-concurrency", 1, "Number of namespaces searched in parallel within each context")
+	rootCmd.PersistentFlags().Float32Var(&qps, "qps", 0, "Maximum queries per second sent to each cluster by the client-side rate limiter (0 = client-go default of 5)")
+	rootCmd.PersistentFlags().IntVar(&burst, "burst", 0, "Maximum burst of queries above --qps sent to each cluster (0 = client-go default of 10)")
+	rootCmd.PersistentFlags().BoolVar(&timings, "timings", false, "Print the wall-clock duration and API call count of each searched context after the results (on stderr for json/yaml/csv/jsonl)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only the results: no detected query kind, namespace discovery, not-found messages, summaries or progress bar (skipped contexts are still reported on stderr)")
+	rootCmd.PersistentFlags().StringVar(&execCommand, "exec", "", "Command to run for every matched pod, with {namespace}, {name}, {context} and {pod_ip} substituted; commands are only printed unless --confirm is set")
+	rootCmd.PersistentFlags().BoolVar(&confirmExec, "confirm", false, "Run the --exec commands one after the other instead of only printing them")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Do not show the progress bar drawn on stderr during all-contexts searches")
+	rootCmd.PersistentFlags().BoolVar(&recordHistory, "history", false, "Record each search with the contexts searched and the number of matches in the history listed by k8sx history (set history: true in the config file to always record)")
+	rootCmd.PersistentFlags().IntVar(&historySize, "history-size", cmdk8s.DefaultHistorySize, "Number of searches kept in the history, older ones are dropped")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colors and use plain ASCII tables (automatic when stdout is not a terminal or NO_COLOR is set)")
+
+	// Shell completion of context and namespace names, honoring KUBECONFIG and the config file like searches do
+	rootCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		_ = applyFlagDefaults(cmd)
+		return cmdk8s.CompleteContexts(kubeconfigPath, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.RegisterFlagCompletionFunc("namespaces", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		_ = applyFlagDefaults(cmd)
+		return cmdk8s.CompleteNamespaces(kubeconfigPath, contextName, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
 
 	// Add subcommands
+	// Search flags shared by the root and s commands
+	for _, cmd := range []*cobra.Command{rootCmd, searchCmd} {
+		cmd.Flags().BoolVar(&uidSearch, "uid", false, "Treat the query as a pod UID")
+		cmd.Flags().BoolVar(&detectDups, "detect-duplicates", false, "For IP and CIDR queries, report the IPs found assigned in more than one cluster (e.g. overlapping pod CIDRs) after the results")
+		cmd.Flags().BoolVar(&bothSearch, "both", false, "Search the query by IP (when it is an IP or CIDR range) and by pod name in one pass instead of auto-detecting its kind, listing pods found both ways once")
+		cmd.Flags().StringVar(&nodeName, "node-name", "", "List the pods scheduled on this node (e.g. ip-10-1-2-3.ec2.internal from kubectl get nodes) instead of searching a query")
+		cmd.Flags().StringVar(&serviceAccount, "service-account", "", "List the pods running as this service account, or with a query keep only the pods running as it")
+	}
+
 	rootCmd.AddCommand(listContextsCmd)
+	listNamespacesCmd.Flags().BoolVar(&onlyWithAccess, "only-with-access", false, "List only the namespaces you can list pods in; denied ones are still counted in the summary")
 	rootCmd.AddCommand(listNamespacesCmd)
 	rootCmd.AddCommand(searchCmd)
+	portCmd.Flags().BoolVar(&containerPorts, "container-ports", false, "Also search pods whose containers (including init containers) declare the port, showing the matching container ports")
+	rootCmd.AddCommand(portCmd)
+	rootCmd.AddCommand(validateCmd)
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Number of most recent searches to list (0 = all)")
+	rootCmd.AddCommand(historyCmd)
+
+	describePodCmd.Flags().StringVarP(&podNamespace, "namespace", "n", "", "Namespace of the pod (empty = the context's default namespace)")
+	describePodCmd.Flags().IntVar(&eventLimit, "events", 10, "Number of most recent events to show (0 = all)")
+	describeCmd.AddCommand(describePodCmd)
+	rootCmd.AddCommand(describeCmd)
+
+	serveCmd.Flags().StringVar(&listenAddr, "listen", ":8080", "Address for the HTTP server to listen on")
+	rootCmd.AddCommand(serveCmd)
+
+	grpcServeCmd.Flags().StringVar(&grpcListenAddr, "listen", ":9090", "Address for the gRPC server to listen on")
+	rootCmd.AddCommand(grpcServeCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		// Flag errors fail before PersistentPreRunE has configured colors
+		cmdk8s.ConfigureColors(noColor)
+		cmdk8s.PrintError(os.Stderr, err)
+		os.Exit(cmdk8s.ExitCode(err))
 	}
 }
@@ -0,0 +1,63 @@
+package pkg
+
+// Searches of every namespace of a context run once across all namespaces, with namespace NamespaceAll,
+// when the credentials can list pods cluster-wide: one paged list per resource instead of one per
+// namespace. Each page is matched as it arrives, as forEachPod does for the pods of a namespace, so only
+// the matches are kept rather than all pods of the context. The matches are then split by namespace.
+
+// splitIPResult splits the result of a search across namespaces into one result per namespace,
+// in the order the pods and services come. Results of one namespace are returned as they are.
+func splitIPResult(result SearchResultWithContext) []SearchResultWithContext {
+	if result.Namespace != "" {
+		return []SearchResultWithContext{result}
+	}
+
+	results := []SearchResultWithContext{}
+	index := map[string]int{}
+	resultFor := func(namespace string) *SearchResultWithContext {
+		i, ok := index[namespace]
+		if !ok {
+			i = len(results)
+			index[namespace] = i
+			results = append(results, SearchResultWithContext{
+				Context:   result.Context,
+				Server:    result.Server,
+				Namespace: namespace,
+				Pods:      []PodInfo{},
+				Services:  []ServiceInfo{},
+			})
+		}
+		return &results[i]
+	}
+
+	for _, pod := range result.Pods {
+		split := resultFor(pod.Namespace)
+		split.Pods = append(split.Pods, pod)
+	}
+	for _, svc := range result.Services {
+		split := resultFor(svc.Namespace)
+		split.Services = append(split.Services, svc)
+	}
+	return results
+}
+
+// splitPodResult splits the result of a pod search across namespaces into one result per namespace,
+// see splitIPResult
+func splitPodResult(result PodResultWithContext) []PodResultWithContext {
+	if result.Namespace != "" {
+		return []PodResultWithContext{result}
+	}
+
+	results := []PodResultWithContext{}
+	index := map[string]int{}
+	for _, pod := range result.Pods {
+		i, ok := index[pod.Namespace]
+		if !ok {
+			i = len(results)
+			index[pod.Namespace] = i
+			results = append(results, PodResultWithContext{Context: result.Context, Server: result.Server, Namespace: pod.Namespace})
+		}
+		results[i].Pods = append(results[i].Pods, pod)
+	}
+	return results
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// clusterListServer serves a pod in each of three namespaces, the cluster-wide list in two pages, and a
+// service in web. Without clusterWide listing pods across namespaces is forbidden. It records the paths requested.
+func clusterListServer(t *testing.T, clusterWide bool) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	paths := []string{}
+	pod := func(namespace string, ip string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, UID: types.UID("uid-" + namespace)}, Status: corev1.PodStatus{PodIP: ip}}
+	}
+	pods := map[string]corev1.Pod{"default": pod("default", "10.0.0.1"), "payments": pod("payments", "10.0.0.2"), "web": pod("web", "10.0.0.3")}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		query := r.URL.Query()
+		namespace := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")[0]
+		switch {
+		case r.URL.Path == "/api/v1/pods" && !clusterWide:
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonForbidden, Code: http.StatusForbidden})
+		case r.URL.Path == "/api/v1/pods" && query.Get("limit") == "1":
+			json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{pods["default"]}})
+		case r.URL.Path == "/api/v1/pods" && query.Get("continue") == "":
+			json.NewEncoder(w).Encode(corev1.PodList{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Pod{pods["default"], pods["payments"]}})
+		case r.URL.Path == "/api/v1/pods":
+			json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{pods["web"]}})
+		case r.URL.Path == "/api/v1/services" || r.URL.Path == "/api/v1/namespaces/web/services":
+			json.NewEncoder(w).Encode(corev1.ServiceList{Items: []corev1.Service{{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "web"},
+				Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.30"},
+			}}})
+		case r.URL.Path == "/api/v1/namespaces":
+			list := corev1.NamespaceList{}
+			for _, name := range []string{"default", "payments", "web"} {
+				list.Items = append(list.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			}
+			json.NewEncoder(w).Encode(list)
+		case strings.HasSuffix(r.URL.Path, "/pods"):
+			list := corev1.PodList{}
+			if p, ok := pods[namespace]; ok {
+				list.Items = append(list.Items, p)
+			}
+			json.NewEncoder(w).Encode(list)
+		default:
+			json.NewEncoder(w).Encode(corev1.ServiceList{})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, paths...)
+	}
+}
+
+// resultNamespaces returns the namespaces of IP search results with the number of pods and services of each
+func resultNamespaces(results []SearchResultWithContext) map[string][2]int {
+	namespaces := map[string][2]int{}
+	for _, result := range results {
+		namespaces[result.Namespace] = [2]int{len(result.Pods), len(result.Services)}
+	}
+	return namespaces
+}
+
+// TestSearchAcrossNamespaces tests that searches of every namespace page through the pods of all namespaces
+// at once when the credentials allow it, splitting the matches by namespace
+func TestSearchAcrossNamespaces(t *testing.T) {
+	server, paths := clusterListServer(t, true)
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	results, err := SearchByIPAllContexts(context.Background(), kubeconfigPath, "10.0.0.0/8", nil, []string{"dev"}, SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]int{"default": {1, 0}, "payments": {1, 0}, "web": {1, 1}}, resultNamespaces(results))
+	for _, result := range results {
+		assert.Equal(t, result.Namespace, result.Pods[0].Namespace)
+	}
+
+	// The access check, both pages of pods and the services, without listing namespaces or a request per namespace
+	assert.Equal(t, []string{"/api/v1/pods", "/api/v1/pods", "/api/v1/pods", "/api/v1/services"}, paths())
+
+	podResults, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "app", nil, []string{"dev"}, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, podResults, 3)
+	assert.Equal(t, "web", podResults[2].Namespace)
+}
+
+// TestSearchAcrossNamespacesForbidden tests searching namespace by namespace without the right to list pods
+// in all namespaces
+func TestSearchAcrossNamespacesForbidden(t *testing.T) {
+	server, paths := clusterListServer(t, false)
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	results, err := SearchByIPAllContexts(context.Background(), kubeconfigPath, "10.0.0.0/8", nil, []string{"dev"}, SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]int{"default": {1, 0}, "payments": {1, 0}, "web": {1, 1}}, resultNamespaces(results))
+
+	requested := paths()
+	assert.Equal(t, "/api/v1/pods", requested[0], "the access check comes first")
+	assert.Contains(t, requested, "/api/v1/namespaces")
+	assert.Contains(t, requested, "/api/v1/namespaces/payments/pods")
+	assert.NotContains(t, requested, "/api/v1/services")
+}
+
+// TestSplitIPResult tests splitting the matches of a search across namespaces by namespace
+func TestSplitIPResult(t *testing.T) {
+	result := SearchResultWithContext{
+		Context: "dev",
+		Server:  "https://dev",
+		Pods:    []PodInfo{{Name: "a", Namespace: "shop"}, {Name: "b", Namespace: "web"}, {Name: "c", Namespace: "shop"}},
+		Services: []ServiceInfo{
+			{Name: "api", Namespace: "payments"},
+		},
+	}
+
+	split := splitIPResult(result)
+	require.Len(t, split, 3)
+	assert.Equal(t, "shop", split[0].Namespace)
+	assert.Equal(t, []PodInfo{{Name: "a", Namespace: "shop"}, {Name: "c", Namespace: "shop"}}, split[0].Pods)
+	assert.Equal(t, "web", split[1].Namespace)
+	assert.Equal(t, "payments", split[2].Namespace)
+	assert.Empty(t, split[2].Pods)
+	assert.Equal(t, "https://dev", split[2].Server)
+
+	// Results of one namespace are kept as they are
+	result.Namespace = "shop"
+	assert.Equal(t, []SearchResultWithContext{result}, splitIPResult(result))
+
+	pods := splitPodResult(PodResultWithContext{Context: "dev", Pods: result.Pods})
+	require.Len(t, pods, 2)
+	assert.Len(t, pods[0].Pods, 2)
+}
@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+)
+
+// collector gathers the results of the namespace searches of an all-contexts search, which run concurrently,
+// and caps the matches they report. It is safe for concurrent use.
+type collector[T any] struct {
+	// mu guards results, appended from concurrent namespace searches
+	mu sync.Mutex
+	// results holds the results added so far; read it once the search has ended
+	results []T
+	// limit caps the matches; filling it cancels the search, so the remaining namespaces and contexts are skipped
+	limit *resultLimit
+	// onResult is called with each result as it is added (may be nil)
+	onResult func(T)
+}
+
+// newCollector returns a collector accepting maxResults matches (0 = no cap), calling onResult (may be nil)
+// with each result. Searches must run with the returned context, which filling the cap cancels;
+// the caller releases it with cancel once the search has ended.
+func newCollector[T any](ctx context.Context, maxResults int, onResult func(T)) (*collector[T], context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &collector[T]{results: []T{}, limit: newResultLimit(maxResults, cancel), onResult: onResult}, ctx, cancel
+}
+
+// add records the result of a namespace search. It does not check the cap: callers first trim the
+// matches to those limit accepts, e.g. with limit.take.
+func (c *collector[T]) add(result T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = append(c.results, result)
+	if c.onResult != nil {
+		c.onResult(result)
+	}
+}
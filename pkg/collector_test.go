@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCollector tests adding results from concurrent searches and cancelling the search once the cap is filled
+func TestCollector(t *testing.T) {
+	reported := 0
+	collected, ctx, cancel := newCollector[int](context.Background(), 5, func(int) { reported++ })
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n := collected.limit.take(1); n > 0 {
+				collected.add(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, collected.results, 5)
+	assert.Equal(t, 5, reported)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// Without a cap the search runs to its end
+	unlimited, ctx, cancel := newCollector[string](context.Background(), 0, nil)
+	defer cancel()
+	assert.NotNil(t, unlimited.results, "results encode as an empty list")
+	unlimited.add("a")
+	assert.Equal(t, []string{"a"}, unlimited.results)
+	assert.NoError(t, ctx.Err())
+}
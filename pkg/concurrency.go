@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+)
+
+// concurrencyLimit bounds the number of calls runLimited runs at once. The bound is halved
+// when the API server throttles requests and grows back by one after every call finished
+// without throttling, up to the configured maximum. It is safe for concurrent use.
+type concurrencyLimit struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	max     int
+	limit   int
+	active  int
+	// throttles counts the throttled requests, so calls can tell whether they were throttled
+	throttles int
+}
+
+// newConcurrencyLimit returns a limit of max calls at once (max < 1 = 1)
+func newConcurrencyLimit(max int) *concurrencyLimit {
+	if max < 1 {
+		max = 1
+	}
+	l := &concurrencyLimit{max: max, limit: max}
+	l.changed = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire waits for a free slot and returns the throttle count to pass to release
+func (l *concurrencyLimit) acquire() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.active >= l.limit {
+		l.changed.Wait()
+	}
+	l.active++
+	return l.throttles
+}
+
+// release frees a slot, growing the limit back when no request was throttled since acquire
+func (l *concurrencyLimit) release(throttles int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	if l.throttles == throttles && l.limit < l.max {
+		l.limit++
+	}
+	l.changed.Broadcast()
+}
+
+// throttle halves the limit after the API server throttled a request; a nil limit ignores it
+func (l *concurrencyLimit) throttle() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.throttles++
+	if l.limit > 1 {
+		l.limit /= 2
+	}
+}
+
+// current returns the number of calls currently allowed at once
+func (l *concurrencyLimit) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// runLimited calls fn for every index below n, running at most limit calls at once.
+// No new call starts once ctx is done; with a limit of one, fn is called in index order.
+func runLimited(ctx context.Context, n int, limit *concurrencyLimit, fn func(i int)) {
+	if limit.max == 1 {
+		for i := 0; i < n && ctx.Err() == nil; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		throttles := limit.acquire()
+		if ctx.Err() != nil {
+			limit.release(throttles)
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer limit.release(throttles)
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
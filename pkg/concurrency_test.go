@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConcurrencyLimit tests that throttling halves the limit and unthrottled calls grow it back
+func TestConcurrencyLimit(t *testing.T) {
+	limit := newConcurrencyLimit(8)
+	assert.Equal(t, 8, limit.current())
+
+	throttles := limit.acquire()
+	limit.throttle()
+	assert.Equal(t, 4, limit.current())
+	limit.throttle()
+	limit.throttle()
+	limit.throttle()
+	assert.Equal(t, 1, limit.current())
+
+	// A throttled call doesn't grow the limit back
+	limit.release(throttles)
+	assert.Equal(t, 1, limit.current())
+
+	throttles = limit.acquire()
+	limit.release(throttles)
+	assert.Equal(t, 2, limit.current())
+
+	// A nil limit ignores throttling
+	var none *concurrencyLimit
+	none.throttle()
+
+	assert.Equal(t, 1, newConcurrencyLimit(0).current())
+}
+
+// TestRunLimited tests that runLimited calls fn once per index without exceeding the limit
+func TestRunLimited(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	seen := map[int]bool{}
+	runLimited(context.Background(), 20, newConcurrencyLimit(3), func(i int) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		seen[i] = true
+		mu.Unlock()
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	})
+	assert.Len(t, seen, 20)
+	assert.LessOrEqual(t, maxActive, 3)
+
+	// Sequential runs keep the index order and stop once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	order := []int{}
+	runLimited(ctx, 5, newConcurrencyLimit(1), func(i int) {
+		order = append(order, i)
+		if i == 2 {
+			cancel()
+		}
+	})
+	assert.Equal(t, []int{0, 1, 2}, order)
+}
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceProbeResults caches whether a context has a namespace for the rest of the process, so fallback
+// searches and the requests of the HTTP server do not probe the same contexts again.
+// Keys are namespaceProbeKey values.
+var namespaceProbeResults sync.Map
+
+// namespaceProbeKey identifies a namespace of a context in a kubeconfig
+type namespaceProbeKey struct {
+	kubeconfigPath string
+	context        string
+	namespace      string
+}
+
+// ContextsWithNamespaces returns the contexts, in the order given, that have at least one of namespaces,
+// probing ContextConcurrency contexts at a time. A context is kept when it cannot be told whether it has
+// them, e.g. because getting namespaces is forbidden or its cluster is unreachable, so the search itself
+// still reports it. Definite answers are cached for the rest of the process.
+func ContextsWithNamespaces(ctx context.Context, kubeconfigPath string, contexts []string, namespaces []string, opts SearchOptions) []string {
+	keep := make([]bool, len(contexts))
+	runLimited(ctx, len(contexts), newConcurrencyLimit(opts.ContextConcurrency), func(i int) {
+		keep[i] = contextMayHaveNamespaces(ctx, kubeconfigPath, contexts[i], namespaces, opts)
+	})
+
+	found := []string{}
+	for i, contextName := range contexts {
+		if keep[i] {
+			found = append(found, contextName)
+		}
+	}
+	return found
+}
+
+// contextMayHaveNamespaces reports whether a context has one of namespaces or cannot be probed
+func contextMayHaveNamespaces(ctx context.Context, kubeconfigPath string, contextName string, namespaces []string, opts SearchOptions) bool {
+	var client *K8sClient
+	for _, namespace := range namespaces {
+		key := namespaceProbeKey{kubeconfigPath: kubeconfigPath, context: contextName, namespace: namespace}
+		if found, ok := namespaceProbeResults.Load(key); ok {
+			if found.(bool) {
+				return true
+			}
+			continue
+		}
+
+		if client == nil {
+			var err error
+			if client, err = NewK8sClientWithOptions(kubeconfigPath, contextName, []string{}, opts); err != nil {
+				return true
+			}
+		}
+		found, known := client.hasNamespace(ctx, namespace, opts)
+		if !known {
+			return true
+		}
+		namespaceProbeResults.Store(key, found)
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNamespace reports whether the cluster has namespace, and whether that could be told at all,
+// bounding the request by PrecheckTimeout (0 = no timeout besides ctx)
+func (c *K8sClient) hasNamespace(ctx context.Context, namespace string, opts SearchOptions) (found bool, known bool) {
+	if opts.PrecheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.PrecheckTimeout)
+		defer cancel()
+	}
+
+	c.countAPICall()
+	_, err := c.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return true, true
+	case apierrors.IsNotFound(err):
+		return false, true
+	}
+	return false, false
+}
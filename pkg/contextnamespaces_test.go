@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContextsWithNamespaces tests restricting searches to the contexts that have the namespaces
+func TestContextsWithNamespaces(t *testing.T) {
+	var probes atomic.Int32
+	newServer := func(namespaces ...string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			name, isGet := strings.CutPrefix(r.URL.Path, "/api/v1/namespaces/")
+			if !isGet {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			probes.Add(1)
+			for _, namespace := range namespaces {
+				if namespace == name {
+					_, _ = w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"` + name + `"}}`))
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	payments := newServer("payments-prod", "default")
+	other := newServer("default")
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+	}))
+	defer forbidden.Close()
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfigContent := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: ` + payments.URL + `
+  name: payments
+- cluster:
+    server: ` + other.URL + `
+  name: other
+- cluster:
+    server: ` + forbidden.URL + `
+  name: restricted
+contexts:
+- context:
+    cluster: payments
+    user: test-user
+  name: payments
+- context:
+    cluster: other
+    user: test-user
+  name: other
+- context:
+    cluster: restricted
+    user: test-user
+  name: restricted
+current-context: payments
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644))
+
+	ctx := context.Background()
+	contexts := []string{"other", "payments", "restricted"}
+
+	// A context that cannot tell whether it has the namespace is kept for the search to report
+	found := ContextsWithNamespaces(ctx, kubeconfigPath, contexts, []string{"payments-prod"}, SearchOptions{ContextConcurrency: 3})
+	assert.Equal(t, []string{"payments", "restricted"}, found)
+	assert.Equal(t, int32(2), probes.Load())
+
+	// Probe results are cached for the process
+	found = ContextsWithNamespaces(ctx, kubeconfigPath, contexts[:2], []string{"payments-prod"}, SearchOptions{})
+	assert.Equal(t, []string{"payments"}, found)
+	assert.Equal(t, int32(2), probes.Load())
+
+	// Any of the namespaces keeps a context
+	found = ContextsWithNamespaces(ctx, kubeconfigPath, contexts[:2], []string{"payments-prod", "default"}, SearchOptions{})
+	assert.Equal(t, []string{"other", "payments"}, found)
+
+	// Dry runs plan only the contexts that have the namespace
+	plans, err := PlanSearch(ctx, kubeconfigPath, []string{"payments-prod"}, contexts[:2], SearchOptions{ContextFromNamespace: true})
+	require.NoError(t, err)
+	assert.Equal(t, []ContextPlan{{Context: "payments", Namespaces: []string{"payments-prod"}}}, plans)
+}
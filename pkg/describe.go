@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// EventInfo represents an event recorded for an object
+type EventInfo struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// GetPod gets a single pod by namespace and name
+func (c *K8sClient) GetPod(ctx context.Context, namespace, name string) (PodInfo, error) {
+	var pod *corev1.Pod
+	err := c.withRetry(ctx, func() error {
+		var err error
+		pod, err = c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return PodInfo{}, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+	}
+	return newPodInfo(pod), nil
+}
+
+// GetPodEvents returns the most recent events of a pod (at most limit, 0 = all), oldest first
+func (c *K8sClient) GetPodEvents(ctx context.Context, namespace, name string, limit int) ([]EventInfo, error) {
+	selector := fields.Set{
+		"involvedObject.kind":      "Pod",
+		"involvedObject.name":      name,
+		"involvedObject.namespace": namespace,
+	}.AsSelector().String()
+
+	var eventList *corev1.EventList
+	err := c.withRetry(ctx, func() error {
+		var err error
+		eventList, err = c.Clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events for pod %s/%s: %w", namespace, name, err)
+	}
+
+	events := make([]EventInfo, 0, len(eventList.Items))
+	for _, event := range eventList.Items {
+		events = append(events, EventInfo{
+			Type:          event.Type,
+			Reason:        event.Reason,
+			Message:       event.Message,
+			Count:         event.Count,
+			LastTimestamp: eventLastTimestamp(&event),
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(events[j].LastTimestamp)
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
+
+// eventLastTimestamp returns when an event was last seen, falling back to the fields set by newer event recorders
+func eventLastTimestamp(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestGetPod tests getting a single pod with its node, phase and QoS class
+func TestGetPod(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1", QOSClass: corev1.PodQOSBurstable},
+	})
+	client := &K8sClient{Clientset: fakeClient}
+
+	pod, err := client.GetPod(context.Background(), "default", "nginx-1")
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", pod.NodeName)
+	assert.Equal(t, "Running", pod.Phase)
+	assert.Equal(t, "Burstable", pod.QOSClass)
+	assert.Equal(t, "10.0.0.1", pod.PodIP)
+
+	_, err = client.GetPod(context.Background(), "default", "missing")
+	assert.Error(t, err)
+}
+
+// TestGetPodEvents tests that events are sorted by last timestamp and limited to the most recent ones
+func TestGetPodEvents(t *testing.T) {
+	now := time.Now()
+	event := func(name string, reason string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "nginx-1", Namespace: "default"},
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+
+	fakeClient := fake.NewSimpleClientset(
+		event("e1", "BackOff", now.Add(-1*time.Minute)),
+		event("e2", "Scheduled", now.Add(-3*time.Hour)),
+		event("e3", "Pulled", now.Add(-2*time.Hour)),
+	)
+	client := &K8sClient{Clientset: fakeClient}
+
+	events, err := client.GetPodEvents(context.Background(), "default", "nginx-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Scheduled", events[0].Reason)
+	assert.Equal(t, "BackOff", events[2].Reason)
+
+	events, err = client.GetPodEvents(context.Background(), "default", "nginx-1", 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Pulled", events[0].Reason)
+	assert.Equal(t, "BackOff", events[1].Reason)
+}
@@ -0,0 +1,327 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// IngressInfo represents ingress information
+type IngressInfo struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Hosts     []string `json:"hosts"`
+	Backends  []string `json:"backends,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// IngressResultWithContext represents hostname search results with context information:
+// ingresses routing the host and ExternalName services aliasing it
+type IngressResultWithContext struct {
+	Context   string        `json:"context"`
+	Server    string        `json:"server,omitempty"`
+	Namespace string        `json:"namespace"`
+	Ingresses []IngressInfo `json:"ingresses"`
+	Services  []ServiceInfo `json:"services,omitempty"`
+}
+
+// DefaultClusterDomain is the DNS domain of most clusters, used to build in-cluster DNS names
+const DefaultClusterDomain = "cluster.local"
+
+// ServiceFQDN returns the in-cluster DNS name of a service (<service>.<namespace>.svc.cluster.local)
+func ServiceFQDN(svc ServiceInfo) string {
+	return fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, DefaultClusterDomain)
+}
+
+// PodFQDN returns the in-cluster DNS name of a pod's IP (<ip-dashed>.<namespace>.pod.cluster.local),
+// or "" for pods without an IP. IPv6 colons are dashed like IPv4 dots.
+func PodFQDN(pod PodInfo) string {
+	if pod.PodIP == "" {
+		return ""
+	}
+	dashed := strings.NewReplacer(".", "-", ":", "-").Replace(pod.PodIP)
+	return fmt.Sprintf("%s.%s.pod.%s", dashed, pod.Namespace, DefaultClusterDomain)
+}
+
+// ParseServiceDNSName parses an in-cluster service DNS name (<service>.<namespace>.svc[.<cluster domain>])
+func ParseServiceDNSName(query string) (service string, namespace string, ok bool) {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(query), "."), ".")
+	if len(labels) < 3 || labels[2] != "svc" {
+		return "", "", false
+	}
+	if len(validation.IsDNS1123Label(labels[0])) > 0 || len(validation.IsDNS1123Label(labels[1])) > 0 {
+		return "", "", false
+	}
+	return labels[0], labels[1], true
+}
+
+// IsHostname checks if a query looks like a fully qualified hostname (e.g. an ingress host)
+func IsHostname(query string) bool {
+	if !strings.Contains(query, ".") || ValidateIP(query) {
+		return false
+	}
+	return len(validation.IsDNS1123Subdomain(strings.ToLower(query))) == 0
+}
+
+// GetService gets a single service by namespace and name
+func (c *K8sClient) GetService(ctx context.Context, namespace, name string) (ServiceInfo, error) {
+	var svc ServiceInfo
+	err := c.withRetry(ctx, func() error {
+		found, err := c.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			svc = newServiceInfo(found)
+		}
+		return err
+	})
+	if err != nil {
+		return ServiceInfo{}, fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+
+	services := []ServiceInfo{svc}
+	c.resolveHeadlessEndpoints(ctx, services)
+	return services[0], nil
+}
+
+// SearchExternalNameServices searches for ExternalName services aliasing a host
+func (c *K8sClient) SearchExternalNameServices(ctx context.Context, host string) ([]ServiceInfo, error) {
+	services := []ServiceInfo{}
+	host = normalizeHost(host)
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		svcList, err := c.listServices(ctx, namespace)
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
+		}
+
+		for _, svc := range svcList.Items {
+			if svc.Spec.Type == corev1.ServiceTypeExternalName && normalizeHost(svc.Spec.ExternalName) == host && c.matchesServiceFilters(&svc) {
+				services = append(services, newServiceInfo(&svc))
+			}
+		}
+	}
+
+	sortServices(services)
+	return services, nil
+}
+
+// normalizeHost lowercases a host and strips the trailing dot of a fully qualified name
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// SearchIngressesByHost searches for ingresses routing a host, including wildcard hosts like *.example.com
+func (c *K8sClient) SearchIngressesByHost(ctx context.Context, host string) ([]IngressInfo, error) {
+	ingresses := []IngressInfo{}
+	host = strings.ToLower(host)
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		var ingressList *networkingv1.IngressList
+		err := c.withRetry(ctx, func() error {
+			var err error
+			ingressList, err = c.Clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list ingresses in namespace %s: %w", namespace, err)
+		}
+
+		for _, ingress := range ingressList.Items {
+			info := newIngressInfo(&ingress)
+			for _, ingressHost := range info.Hosts {
+				if hostMatches(ingressHost, host) {
+					ingresses = append(ingresses, info)
+					break
+				}
+			}
+		}
+	}
+
+	return ingresses, nil
+}
+
+// newIngressInfo converts an ingress into IngressInfo
+func newIngressInfo(ingress *networkingv1.Ingress) IngressInfo {
+	info := IngressInfo{
+		Name:      ingress.Name,
+		Namespace: ingress.Namespace,
+		Hosts:     []string{},
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" {
+			info.Hosts = append(info.Hosts, rule.Host)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			port := path.Backend.Service.Port.Name
+			if port == "" {
+				port = fmt.Sprintf("%d", path.Backend.Service.Port.Number)
+			}
+			info.Backends = append(info.Backends, fmt.Sprintf("%s%s -> %s:%s", rule.Host, path.Path, path.Backend.Service.Name, port))
+		}
+	}
+
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			info.Addresses = append(info.Addresses, lb.IP)
+		}
+		if lb.Hostname != "" {
+			info.Addresses = append(info.Addresses, lb.Hostname)
+		}
+	}
+	return info
+}
+
+// hostMatches checks a host against an ingress host, where a leading "*." matches exactly one label
+func hostMatches(ingressHost string, host string) bool {
+	ingressHost = strings.ToLower(ingressHost)
+	if suffix, ok := strings.CutPrefix(ingressHost, "*."); ok {
+		prefix, rest, found := strings.Cut(host, ".")
+		return found && prefix != "" && rest == suffix
+	}
+	return ingressHost == host
+}
+
+// SearchByServiceDNSAllContexts looks up the service named by an in-cluster DNS name in all (or specified) contexts
+func SearchByServiceDNSAllContexts(ctx context.Context, kubeconfigPath string, service string, namespace string, contexts []string, opts SearchOptions) ([]SearchResultWithContext, error) {
+	collected, ctx, cancel := newCollector[SearchResultWithContext](ctx, 0, nil)
+	defer cancel()
+
+	err := forEachNamespace(ctx, kubeconfigPath, []string{namespace}, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		svc, err := client.GetService(ctx, namespace, service)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		getMetrics().AddMatches(contextName, 0, 1)
+
+		collected.add(SearchResultWithContext{
+			Context:   contextName,
+			Server:    client.Server(),
+			Namespace: namespace,
+			Pods:      []PodInfo{},
+			Services:  []ServiceInfo{svc},
+		})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	SortIPResults(results)
+	return results, nil
+}
+
+// SearchByHostAllContexts searches for ingresses routing a host and ExternalName services aliasing it
+// across all (or specified) contexts and all (or specified) namespaces
+func SearchByHostAllContexts(ctx context.Context, kubeconfigPath string, host string, namespaces []string, contexts []string, opts SearchOptions) ([]IngressResultWithContext, error) {
+	collected, ctx, cancel := newCollector[IngressResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+
+	err := forEachNamespace(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		ingresses, err := client.SearchIngressesByHost(ctx, host)
+		if err != nil {
+			return false, err
+		}
+		services, err := client.SearchExternalNameServices(ctx, host)
+		if err != nil {
+			return false, err
+		}
+		ingresses = ingresses[:collected.limit.take(len(ingresses))]
+		services = services[:collected.limit.take(len(services))]
+
+		// Only add results if found something
+		if len(ingresses) > 0 || len(services) > 0 {
+			collected.add(IngressResultWithContext{
+				Context:   contextName,
+				Server:    client.Server(),
+				Namespace: namespace,
+				Ingresses: ingresses,
+				Services:  services,
+			})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	return results, nil
+}
+
+// DedupIngressResults removes ingresses and services already reported by another context pointing at the same cluster
+func DedupIngressResults(results []IngressResultWithContext) []IngressResultWithContext {
+	seen := map[string]bool{}
+	deduped := []IngressResultWithContext{}
+
+	for _, result := range results {
+		// Results of a context without a known server cannot be told apart from other clusters, so they are all kept
+		if result.Server == "" {
+			deduped = append(deduped, result)
+			continue
+		}
+		server := result.Server
+
+		ingresses := []IngressInfo{}
+		for _, ingress := range result.Ingresses {
+			key := fmt.Sprintf("ingress/%s/%s/%s", server, ingress.Namespace, ingress.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ingresses = append(ingresses, ingress)
+		}
+
+		services := []ServiceInfo{}
+		for _, svc := range result.Services {
+			key := fmt.Sprintf("svc/%s/%s/%s", server, svc.Namespace, svc.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			services = append(services, svc)
+		}
+
+		if len(ingresses) > 0 || len(services) > 0 {
+			result.Ingresses = ingresses
+			result.Services = services
+			deduped = append(deduped, result)
+		}
+	}
+
+	return deduped
+}
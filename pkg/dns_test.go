@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseServiceDNSName tests parsing in-cluster service DNS names
+func TestParseServiceDNSName(t *testing.T) {
+	tests := []struct {
+		query     string
+		service   string
+		namespace string
+		ok        bool
+	}{
+		{"nginx.default.svc.cluster.local", "nginx", "default", true},
+		{"nginx.default.svc.cluster.local.", "nginx", "default", true},
+		{"api.web.svc", "api", "web", true},
+		{"api.web.svc.corp.internal", "api", "web", true},
+		{"shop.example.com", "", "", false},
+		{"nginx.default", "", "", false},
+		{"nginx", "", "", false},
+	}
+
+	for _, tt := range tests {
+		service, namespace, ok := ParseServiceDNSName(tt.query)
+		assert.Equal(t, tt.ok, ok, tt.query)
+		assert.Equal(t, tt.service, service, tt.query)
+		assert.Equal(t, tt.namespace, namespace, tt.query)
+	}
+}
+
+// TestIsHostname tests detecting hostnames
+func TestIsHostname(t *testing.T) {
+	assert.True(t, IsHostname("shop.example.com"))
+	assert.True(t, IsHostname("Shop.Example.com"))
+	assert.False(t, IsHostname("nginx"))
+	assert.False(t, IsHostname("10.0.0.1"))
+	assert.False(t, IsHostname("bad_host.example.com"))
+}
+
+// TestSearchIngressesByHost tests matching exact and wildcard ingress hosts
+func TestSearchIngressesByHost(t *testing.T) {
+	ingress := func(name string, hosts ...string) *networkingv1.Ingress {
+		rules := []networkingv1.IngressRule{}
+		for _, host := range hosts {
+			rules = append(rules, networkingv1.IngressRule{Host: host})
+		}
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "web"},
+			Spec:       networkingv1.IngressSpec{Rules: rules},
+		}
+	}
+
+	fakeClient := fake.NewSimpleClientset(
+		ingress("shop", "shop.example.com"),
+		ingress("wildcard", "*.example.com"),
+		ingress("other", "other.example.org"),
+	)
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"web"},
+	}
+
+	ctx := context.Background()
+
+	ingresses, err := client.SearchIngressesByHost(ctx, "shop.example.com")
+	require.NoError(t, err)
+	require.Len(t, ingresses, 2)
+	assert.Equal(t, "shop", ingresses[0].Name)
+	assert.Equal(t, "wildcard", ingresses[1].Name)
+
+	// A wildcard only matches a single label
+	ingresses, err = client.SearchIngressesByHost(ctx, "a.b.example.com")
+	require.NoError(t, err)
+	assert.Len(t, ingresses, 0)
+}
+
+// TestSearchExternalNameServices tests matching ExternalName services by the host they alias
+func TestSearchExternalNameServices(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "DB.example.com."}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "10.96.0.10"}},
+	)
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	services, err := client.SearchExternalNameServices(context.Background(), "db.example.com")
+	assert.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "db", services[0].Name)
+	assert.Equal(t, "DB.example.com.", services[0].ExternalName)
+
+	services, err = client.SearchExternalNameServices(context.Background(), "api.example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, services)
+}
+
+// TestGetHeadlessService tests that headless services report their endpoint IPs
+func TestGetHeadlessService(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.7"}, {IP: "10.0.0.5"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.9"}},
+			}},
+		},
+	)
+	client := &K8sClient{Clientset: fakeClient}
+
+	svc, err := client.GetService(context.Background(), "default", "db")
+	assert.NoError(t, err)
+	assert.True(t, svc.IsHeadless())
+	assert.Equal(t, []string{"10.0.0.5", "10.0.0.7"}, svc.EndpointIPs)
+	assert.Equal(t, []string{"10.0.0.9"}, svc.NotReadyEndpointIPs)
+}
+
+// TestFQDN tests building the in-cluster DNS names of services and pods
+func TestFQDN(t *testing.T) {
+	assert.Equal(t, "nginx.default.svc.cluster.local", ServiceFQDN(ServiceInfo{Name: "nginx", Namespace: "default"}))
+
+	assert.Equal(t, "10-0-0-1.web.pod.cluster.local", PodFQDN(PodInfo{Namespace: "web", PodIP: "10.0.0.1"}))
+	assert.Equal(t, "fd00--1.web.pod.cluster.local", PodFQDN(PodInfo{Namespace: "web", PodIP: "fd00::1"}))
+	assert.Empty(t, PodFQDN(PodInfo{Namespace: "web"}))
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"sort"
+)
+
+// IPHolder is a pod or service an IP search found holding an address
+type IPHolder struct {
+	Context   string `json:"context"`
+	Server    string `json:"server,omitempty"`
+	Namespace string `json:"namespace"`
+	// Kind is "Pod" or "Service"
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// DuplicateIP is an address held in more than one cluster, e.g. because their pod CIDRs overlap
+type DuplicateIP struct {
+	IP      string     `json:"ip"`
+	Holders []IPHolder `json:"holders"`
+}
+
+// FindDuplicateIPs returns the addresses matching query (an IP or CIDR range) that the results show
+// assigned in more than one cluster, sorted by IP. Pod and host IPs of pods and cluster and external IPs
+// of services are compared; contexts are told apart by their API server URL, so contexts pointing at
+// the same cluster are not reported as duplicates of each other.
+func FindDuplicateIPs(results []SearchResultWithContext, query string) []DuplicateIP {
+	match := newIPMatcher(query)
+	holders := map[string][]IPHolder{}
+	add := func(addresses []string, holder IPHolder) {
+		for _, address := range addresses {
+			if address != "" && match(address) {
+				ip := NormalizeIP(address)
+				holders[ip] = append(holders[ip], holder)
+			}
+		}
+	}
+
+	for _, result := range results {
+		for _, pod := range result.Pods {
+			holder := IPHolder{Context: result.Context, Server: result.Server, Namespace: result.Namespace, Kind: "Pod", Name: pod.Name}
+			add(addressesOrPrimary(pod.PodIPs, pod.PodIP), holder)
+			add(addressesOrPrimary(pod.HostIPs, pod.HostIP), holder)
+		}
+		for _, svc := range result.Services {
+			holder := IPHolder{Context: result.Context, Server: result.Server, Namespace: result.Namespace, Kind: "Service", Name: svc.Name}
+			add(addressesOrPrimary(svc.ClusterIPs, svc.ClusterIP), holder)
+			add(svc.ExternalIPs, holder)
+		}
+	}
+
+	duplicates := []DuplicateIP{}
+	for ip, held := range holders {
+		clusters := map[string]bool{}
+		for _, holder := range held {
+			clusters[holderCluster(holder)] = true
+		}
+		if len(clusters) > 1 {
+			duplicates = append(duplicates, DuplicateIP{IP: ip, Holders: held})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].IP < duplicates[j].IP
+	})
+	return duplicates
+}
+
+// holderCluster identifies the cluster of a holder: its API server URL, else its context
+func holderCluster(holder IPHolder) string {
+	if holder.Server != "" {
+		return holder.Server
+	}
+	return holder.Context
+}
+
+// addressesOrPrimary returns the addresses of a dual-stack resource, falling back to the primary address
+func addressesOrPrimary(addresses []string, primary string) []string {
+	if len(addresses) == 0 {
+		return []string{primary}
+	}
+	return addresses
+}
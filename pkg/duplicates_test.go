@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFindDuplicateIPs tests finding the IPs held in more than one cluster
+func TestFindDuplicateIPs(t *testing.T) {
+	results := []SearchResultWithContext{
+		{Context: "eu", Server: "https://eu:6443", Namespace: "web", Pods: []PodInfo{
+			{Name: "web-1", PodIP: "10.0.0.5", PodIPs: []string{"10.0.0.5", "fd00::5"}, HostIP: "192.168.1.1"},
+		}},
+		{Context: "eu-admin", Server: "https://eu:6443", Namespace: "web", Pods: []PodInfo{
+			{Name: "web-1", PodIP: "10.0.0.5"},
+		}},
+		{Context: "us", Server: "https://us:6443", Namespace: "api", Pods: []PodInfo{
+			{Name: "api-1", PodIP: "10.0.0.5", HostIP: "192.168.1.1"},
+		}, Services: []ServiceInfo{
+			{Name: "api", ClusterIP: "10.0.0.9"},
+		}},
+	}
+
+	duplicates := FindDuplicateIPs(results, "10.0.0.5")
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "10.0.0.5", duplicates[0].IP)
+	assert.Equal(t, []IPHolder{
+		{Context: "eu", Server: "https://eu:6443", Namespace: "web", Kind: "Pod", Name: "web-1"},
+		{Context: "eu-admin", Server: "https://eu:6443", Namespace: "web", Kind: "Pod", Name: "web-1"},
+		{Context: "us", Server: "https://us:6443", Namespace: "api", Kind: "Pod", Name: "api-1"},
+	}, duplicates[0].Holders)
+
+	// CIDR queries compare every address in the range, host IPs included
+	duplicates = FindDuplicateIPs(results, "192.168.0.0/16")
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "192.168.1.1", duplicates[0].IP)
+
+	// Contexts pointing at the same cluster are no duplicates of each other
+	assert.Empty(t, FindDuplicateIPs(results[:2], "10.0.0.5"))
+	assert.Empty(t, FindDuplicateIPs(results, "10.0.0.9"))
+}
@@ -0,0 +1,440 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// endpointSlicePageSize is the number of EndpointSlices requested per list call
+var endpointSlicePageSize int64 = 500
+
+// ServiceEndpoints are the addresses backing a service, split by readiness
+type ServiceEndpoints struct {
+	Ready    []string `json:"ready"`
+	NotReady []string `json:"notReady"`
+}
+
+// GetServiceEndpoints returns the ready and not-ready addresses backing a service.
+// They are read from the service's EndpointSlices, falling back to the core Endpoints object
+// on clusters without the discovery.k8s.io/v1 API, without permission to read slices,
+// or when the service has no slices. A service without either has no addresses.
+func (c *K8sClient) GetServiceEndpoints(ctx context.Context, namespace, name string) (ServiceEndpoints, error) {
+	endpoints, slices, err := c.endpointSliceAddresses(ctx, namespace, name)
+	if err == nil && slices > 0 {
+		return endpoints, nil
+	}
+	if err != nil && !apierrors.IsNotFound(err) && !isPermissionError(err) {
+		return ServiceEndpoints{}, err
+	}
+
+	endpoints, err = c.endpointsAddresses(ctx, namespace, name)
+	if apierrors.IsNotFound(err) {
+		return ServiceEndpoints{Ready: []string{}, NotReady: []string{}}, nil
+	}
+	return endpoints, err
+}
+
+// endpointSliceAddresses aggregates the addresses of all EndpointSlices of a service, page by page,
+// and returns how many slices were read. An endpoint without a ready condition is counted as ready,
+// as the API recommends.
+func (c *K8sClient) endpointSliceAddresses(ctx context.Context, namespace, name string) (ServiceEndpoints, int, error) {
+	options := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, name),
+		Limit:         endpointSlicePageSize,
+	}
+
+	ready, notReady := map[string]bool{}, map[string]bool{}
+	slices := 0
+	for {
+		var sliceList *discoveryv1.EndpointSliceList
+		err := c.withRetry(ctx, func() error {
+			var err error
+			sliceList, err = c.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, options)
+			return err
+		})
+		if err != nil {
+			return ServiceEndpoints{}, 0, fmt.Errorf("failed to list endpoint slices of service %s/%s: %w", namespace, name, err)
+		}
+
+		slices += len(sliceList.Items)
+		for _, slice := range sliceList.Items {
+			for _, endpoint := range slice.Endpoints {
+				isReady := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				for _, address := range endpoint.Addresses {
+					if isReady {
+						ready[address] = true
+					} else {
+						notReady[address] = true
+					}
+				}
+			}
+		}
+
+		if sliceList.Continue == "" {
+			break
+		}
+		options.Continue = sliceList.Continue
+	}
+
+	// An address briefly listed in two slices counts as ready if either says so
+	for address := range ready {
+		delete(notReady, address)
+	}
+	return ServiceEndpoints{Ready: sortedKeys(ready), NotReady: sortedKeys(notReady)}, slices, nil
+}
+
+// endpointsAddresses returns the addresses of a service's core Endpoints object
+func (c *K8sClient) endpointsAddresses(ctx context.Context, namespace, name string) (ServiceEndpoints, error) {
+	var endpoints *corev1.Endpoints
+	err := c.withRetry(ctx, func() error {
+		var err error
+		endpoints, err = c.Clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return ServiceEndpoints{}, fmt.Errorf("failed to get endpoints %s/%s: %w", namespace, name, err)
+	}
+
+	ready, notReady := map[string]bool{}, map[string]bool{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ready[address.IP] = true
+		}
+		for _, address := range subset.NotReadyAddresses {
+			notReady[address.IP] = true
+		}
+	}
+	return ServiceEndpoints{Ready: sortedKeys(ready), NotReady: sortedKeys(notReady)}, nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EndpointPod is a pod backing a service, with the readiness of its endpoint
+type EndpointPod struct {
+	Pod   PodInfo `json:"pod"`
+	Ready bool    `json:"ready"`
+}
+
+// GetServiceBackends returns the pods behind the endpoints of a service, sorted by name.
+// Endpoint addresses not belonging to a pod of the namespace (e.g. manually managed endpoints) are left out.
+func (c *K8sClient) GetServiceBackends(ctx context.Context, namespace, name string) ([]EndpointPod, error) {
+	endpoints, err := c.GetServiceEndpoints(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	backends := []EndpointPod{}
+	if len(endpoints.Ready) == 0 && len(endpoints.NotReady) == 0 {
+		return backends, nil
+	}
+
+	ready := map[string]bool{}
+	for _, address := range endpoints.Ready {
+		ready[address] = true
+	}
+	notReady := map[string]bool{}
+	for _, address := range endpoints.NotReady {
+		notReady[address] = true
+	}
+
+	err = c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+		for _, ip := range getPodIPs(pod) {
+			if ready[ip] || notReady[ip] {
+				backends = append(backends, EndpointPod{Pod: newPodInfo(pod), Ready: ready[ip]})
+				break
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+
+	sort.SliceStable(backends, func(i, j int) bool {
+		return backends[i].Pod.Name < backends[j].Pod.Name
+	})
+	return backends, nil
+}
+
+// IsEndpointKind reports whether kind names endpoints ("endpoint", "endpoints", "ep", "endpointslice"
+// or "endpointslices"), which makes IP searches match endpoint addresses instead of pods and services
+func IsEndpointKind(kind string) bool {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "endpoint", "endpoints", "ep", "endpointslice", "endpointslices":
+		return true
+	}
+	return false
+}
+
+// EndpointInfo is an endpoint address matching an IP search, with the service it belongs to and its target
+type EndpointInfo struct {
+	Service   string `json:"service"`
+	Namespace string `json:"namespace"`
+	Address   string `json:"address"`
+	Ready     bool   `json:"ready"`
+	// Ports are the ports of the endpoint, e.g. "8080/TCP (http)"
+	Ports    []string `json:"ports,omitempty"`
+	NodeName string   `json:"nodeName,omitempty"`
+	// TargetKind and TargetName are the object behind the address, usually a Pod
+	TargetKind string `json:"targetKind,omitempty"`
+	TargetName string `json:"targetName,omitempty"`
+	// Source is the EndpointSlice listing the address, or the Endpoints object on clusters without slices
+	Source string `json:"source"`
+}
+
+// EndpointResultWithContext represents endpoint search results with context information
+type EndpointResultWithContext struct {
+	Context   string         `json:"context"`
+	Server    string         `json:"server,omitempty"`
+	Namespace string         `json:"namespace"`
+	Endpoints []EndpointInfo `json:"endpoints"`
+}
+
+// SearchEndpointsByIP searches the endpoint addresses of services for an IP address or CIDR range.
+// Addresses are read from EndpointSlices, falling back to core Endpoints objects on clusters without
+// the discovery.k8s.io/v1 API or without permission to read slices.
+func (c *K8sClient) SearchEndpointsByIP(ctx context.Context, ip string) ([]EndpointInfo, error) {
+	endpoints := []EndpointInfo{}
+	match := c.Options.ipMatcher(ip)
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		matches, err := c.endpointSliceMatches(ctx, namespace, match)
+		if err != nil && (apierrors.IsNotFound(err) || isPermissionError(err)) {
+			matches, err = c.endpointsMatches(ctx, namespace, match)
+		}
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list endpoints in namespace %s: %w", namespace, err)
+		}
+		endpoints = append(endpoints, matches...)
+	}
+
+	sortEndpoints(endpoints)
+	endpoints = endpoints[:newResultLimit(c.Options.MaxResults, nil).take(len(endpoints))]
+	return endpoints, nil
+}
+
+// endpointSliceMatches returns the endpoints of the EndpointSlices of a namespace whose address matches, page by page.
+// An address listed in several slices of a service, e.g. while it moves between them, is reported once.
+func (c *K8sClient) endpointSliceMatches(ctx context.Context, namespace string, match ipMatcher) ([]EndpointInfo, error) {
+	options := metav1.ListOptions{Limit: endpointSlicePageSize}
+
+	endpoints := []EndpointInfo{}
+	seen := map[string]bool{}
+	for {
+		var sliceList *discoveryv1.EndpointSliceList
+		err := c.withRetry(ctx, func() error {
+			var err error
+			sliceList, err = c.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, options)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, slice := range sliceList.Items {
+			service := slice.Labels[discoveryv1.LabelServiceName]
+			ports := []string{}
+			for _, port := range slice.Ports {
+				ports = append(ports, formatEndpointPort(port.Name, port.Port, port.Protocol))
+			}
+
+			for _, endpoint := range slice.Endpoints {
+				for _, address := range endpoint.Addresses {
+					if !match(address) || seen[service+"/"+address] {
+						continue
+					}
+					seen[service+"/"+address] = true
+
+					info := EndpointInfo{
+						Service:   service,
+						Namespace: slice.Namespace,
+						Address:   address,
+						Ready:     endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready,
+						Ports:     ports,
+						Source:    slice.Name,
+					}
+					if endpoint.NodeName != nil {
+						info.NodeName = *endpoint.NodeName
+					}
+					if endpoint.TargetRef != nil {
+						info.TargetKind, info.TargetName = endpoint.TargetRef.Kind, endpoint.TargetRef.Name
+					}
+					endpoints = append(endpoints, info)
+				}
+			}
+		}
+
+		if sliceList.Continue == "" {
+			break
+		}
+		options.Continue = sliceList.Continue
+	}
+	return endpoints, nil
+}
+
+// endpointsMatches returns the addresses of the core Endpoints objects of a namespace that match
+func (c *K8sClient) endpointsMatches(ctx context.Context, namespace string, match ipMatcher) ([]EndpointInfo, error) {
+	var endpointsList *corev1.EndpointsList
+	err := c.withRetry(ctx, func() error {
+		var err error
+		endpointsList, err = c.Clientset.CoreV1().Endpoints(namespace).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []EndpointInfo{}
+	for _, object := range endpointsList.Items {
+		for _, subset := range object.Subsets {
+			ports := []string{}
+			for _, port := range subset.Ports {
+				ports = append(ports, formatEndpointPort(&port.Name, &port.Port, &port.Protocol))
+			}
+
+			addresses := func(addresses []corev1.EndpointAddress, ready bool) {
+				for _, address := range addresses {
+					if !match(address.IP) {
+						continue
+					}
+					info := EndpointInfo{
+						Service:   object.Name,
+						Namespace: object.Namespace,
+						Address:   address.IP,
+						Ready:     ready,
+						Ports:     ports,
+						Source:    object.Name,
+					}
+					if address.NodeName != nil {
+						info.NodeName = *address.NodeName
+					}
+					if address.TargetRef != nil {
+						info.TargetKind, info.TargetName = address.TargetRef.Kind, address.TargetRef.Name
+					}
+					endpoints = append(endpoints, info)
+				}
+			}
+			addresses(subset.Addresses, true)
+			addresses(subset.NotReadyAddresses, false)
+		}
+	}
+	return endpoints, nil
+}
+
+// formatEndpointPort formats an endpoint port like "8080/TCP (http)", leaving out the fields that are not set
+func formatEndpointPort(name *string, port *int32, protocol *corev1.Protocol) string {
+	text := "*"
+	if port != nil {
+		text = fmt.Sprintf("%d", *port)
+	}
+	if protocol != nil && *protocol != "" {
+		text += "/" + string(*protocol)
+	}
+	if name != nil && *name != "" {
+		text += fmt.Sprintf(" (%s)", *name)
+	}
+	return text
+}
+
+// sortEndpoints sorts endpoints by namespace, service then address
+func sortEndpoints(endpoints []EndpointInfo) {
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Namespace != endpoints[j].Namespace {
+			return endpoints[i].Namespace < endpoints[j].Namespace
+		}
+		if endpoints[i].Service != endpoints[j].Service {
+			return endpoints[i].Service < endpoints[j].Service
+		}
+		return endpoints[i].Address < endpoints[j].Address
+	})
+}
+
+// SearchEndpointsAllContexts searches the endpoint addresses of services for an IP address or CIDR range
+// across all (or specified) contexts and all (or specified) namespaces
+func SearchEndpointsAllContexts(ctx context.Context, kubeconfigPath string, ip string, namespaces []string, contexts []string, opts SearchOptions) ([]EndpointResultWithContext, error) {
+	collected, ctx, cancel := newCollector[EndpointResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+
+	err := forEachNamespace(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		endpoints, err := client.SearchEndpointsByIP(ctx, ip)
+		if err != nil {
+			return false, err
+		}
+		endpoints = endpoints[:collected.limit.take(len(endpoints))]
+
+		// Only add results if found something
+		if len(endpoints) > 0 {
+			collected.add(EndpointResultWithContext{
+				Context:   contextName,
+				Server:    client.Server(),
+				Namespace: namespace,
+				Endpoints: endpoints,
+			})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	return results, nil
+}
+
+// DedupEndpointResults removes endpoints already reported by another context pointing at the same cluster
+func DedupEndpointResults(results []EndpointResultWithContext) []EndpointResultWithContext {
+	seen := map[string]bool{}
+	deduped := []EndpointResultWithContext{}
+
+	for _, result := range results {
+		// Results of a context without a known server cannot be told apart from other clusters, so they are all kept
+		if result.Server == "" {
+			deduped = append(deduped, result)
+			continue
+		}
+		server := result.Server
+
+		endpoints := []EndpointInfo{}
+		for _, endpoint := range result.Endpoints {
+			key := fmt.Sprintf("%s/%s/%s/%s", server, endpoint.Namespace, endpoint.Service, endpoint.Address)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			endpoints = append(endpoints, endpoint)
+		}
+
+		if len(endpoints) > 0 {
+			result.Endpoints = endpoints
+			deduped = append(deduped, result)
+		}
+	}
+
+	return deduped
+}
@@ -0,0 +1,242 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// endpointSlice builds an EndpointSlice of service with one endpoint per address and readiness
+func endpointSlice(name, service string, endpoints map[string]*bool) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	for address, ready := range endpoints {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{address},
+			Conditions: discoveryv1.EndpointConditions{Ready: ready},
+		})
+	}
+	return slice
+}
+
+// TestGetServiceEndpoints tests aggregating ready and not-ready addresses over a service's slices
+func TestGetServiceEndpoints(t *testing.T) {
+	ready, notReady := true, false
+	fakeClient := fake.NewSimpleClientset(
+		endpointSlice("db-abc", "db", map[string]*bool{"10.0.0.7": &ready, "10.0.0.9": &notReady}),
+		// Endpoints without a ready condition count as ready
+		endpointSlice("db-def", "db", map[string]*bool{"10.0.0.5": nil}),
+		endpointSlice("web-abc", "web", map[string]*bool{"10.0.0.8": &ready}),
+	)
+	client := &K8sClient{Clientset: fakeClient}
+
+	endpoints, err := client.GetServiceEndpoints(context.Background(), "default", "db")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.5", "10.0.0.7"}, endpoints.Ready)
+	assert.Equal(t, []string{"10.0.0.9"}, endpoints.NotReady)
+
+	// A service without slices or endpoints has no addresses
+	endpoints, err = client.GetServiceEndpoints(context.Background(), "default", "cache")
+	require.NoError(t, err)
+	assert.Empty(t, endpoints.Ready)
+	assert.Empty(t, endpoints.NotReady)
+}
+
+// TestGetServiceBackends tests resolving the endpoint addresses of a service to its pods
+func TestGetServiceBackends(t *testing.T) {
+	ready, notReady := true, false
+	fakeClient := fake.NewSimpleClientset(
+		endpointSlice("db-abc", "db", map[string]*bool{"10.0.0.7": &ready, "10.0.0.9": &notReady, "192.168.0.1": &ready}),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.7"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.9"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.8"}},
+	)
+	client := &K8sClient{Clientset: fakeClient}
+
+	// The address outside the cluster's pods is left out
+	backends, err := client.GetServiceBackends(context.Background(), "default", "db")
+	require.NoError(t, err)
+	require.Len(t, backends, 2)
+	assert.Equal(t, "db-0", backends[0].Pod.Name)
+	assert.False(t, backends[0].Ready)
+	assert.Equal(t, "db-1", backends[1].Pod.Name)
+	assert.True(t, backends[1].Ready)
+
+	backends, err = client.GetServiceBackends(context.Background(), "default", "cache")
+	require.NoError(t, err)
+	assert.Empty(t, backends)
+}
+
+// TestGetServiceEndpointsPaginated tests following continue tokens over endpoint slice pages
+func TestGetServiceEndpointsPaginated(t *testing.T) {
+	ready, notReady := true, false
+	pages := map[string]*discoveryv1.EndpointSliceList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []discoveryv1.EndpointSlice{*endpointSlice("db-1", "db", map[string]*bool{"10.0.0.1": &ready})},
+		},
+		"page-2": {
+			// An address moving between slices is ready if any slice says so
+			Items: []discoveryv1.EndpointSlice{*endpointSlice("db-2", "db", map[string]*bool{"10.0.0.1": &notReady, "10.0.0.2": &notReady})},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset()
+	requested := []string{}
+	fakeClient.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		options := action.(k8stesting.ListActionImpl).ListOptions
+		assert.Equal(t, endpointSlicePageSize, options.Limit)
+		assert.Equal(t, "kubernetes.io/service-name=db", options.LabelSelector)
+		requested = append(requested, options.Continue)
+		return true, pages[options.Continue], nil
+	})
+	client := &K8sClient{Clientset: fakeClient}
+
+	endpoints, err := client.GetServiceEndpoints(context.Background(), "default", "db")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "page-2"}, requested)
+	assert.Equal(t, []string{"10.0.0.1"}, endpoints.Ready)
+	assert.Equal(t, []string{"10.0.0.2"}, endpoints.NotReady)
+}
+
+// TestGetServiceEndpointsFallback tests falling back to core Endpoints when slices cannot be listed
+func TestGetServiceEndpointsFallback(t *testing.T) {
+	sliceErrors := []error{
+		apierrors.NewNotFound(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, ""),
+		apierrors.NewForbidden(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "", nil),
+	}
+
+	for _, sliceErr := range sliceErrors {
+		fakeClient := fake.NewSimpleClientset(&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.7"}, {IP: "10.0.0.5"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.9"}},
+			}},
+		})
+		fakeClient.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, sliceErr
+		})
+		client := &K8sClient{Clientset: fakeClient}
+
+		endpoints, err := client.GetServiceEndpoints(context.Background(), "default", "db")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.5", "10.0.0.7"}, endpoints.Ready)
+		assert.Equal(t, []string{"10.0.0.9"}, endpoints.NotReady)
+	}
+
+	// Other errors are returned
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewBadRequest("bad selector")
+	})
+	client := &K8sClient{Clientset: fakeClient}
+
+	_, err := client.GetServiceEndpoints(context.Background(), "default", "db")
+	assert.Error(t, err)
+}
+
+// TestSearchEndpointsByIP tests finding the service and target pod of an endpoint address
+func TestSearchEndpointsByIP(t *testing.T) {
+	ready, notReady := true, false
+	db := endpointSlice("db-abc", "db", map[string]*bool{"10.0.0.7": &ready, "10.0.1.9": &notReady})
+	port, protocol, portName := int32(5432), corev1.ProtocolTCP, "postgres"
+	db.Ports = []discoveryv1.EndpointPort{{Name: &portName, Port: &port, Protocol: &protocol}}
+	nodeName := "node-1"
+	for i := range db.Endpoints {
+		if db.Endpoints[i].Addresses[0] == "10.0.0.7" {
+			db.Endpoints[i].NodeName = &nodeName
+			db.Endpoints[i].TargetRef = &corev1.ObjectReference{Kind: "Pod", Name: "db-0", Namespace: "default"}
+		}
+	}
+	fakeClient := fake.NewSimpleClientset(
+		db,
+		// The address moving to another slice of the same service is reported once
+		endpointSlice("db-def", "db", map[string]*bool{"10.0.0.7": &ready}),
+		endpointSlice("web-abc", "web", map[string]*bool{"10.0.0.8": &ready}),
+	)
+	client := &K8sClient{Clientset: fakeClient, Namespaces: []string{"default"}}
+
+	endpoints, err := client.SearchEndpointsByIP(context.Background(), "10.0.0.7")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, EndpointInfo{
+		Service:    "db",
+		Namespace:  "default",
+		Address:    "10.0.0.7",
+		Ready:      true,
+		Ports:      []string{"5432/TCP (postgres)"},
+		NodeName:   "node-1",
+		TargetKind: "Pod",
+		TargetName: "db-0",
+		Source:     "db-abc",
+	}, endpoints[0])
+
+	// CIDR ranges match every address in them, not-ready ones included
+	endpoints, err = client.SearchEndpointsByIP(context.Background(), "10.0.0.0/16")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+	assert.Equal(t, "10.0.0.7", endpoints[0].Address)
+	assert.Equal(t, "10.0.1.9", endpoints[1].Address)
+	assert.False(t, endpoints[1].Ready)
+	assert.Equal(t, "web", endpoints[2].Service)
+}
+
+// TestSearchEndpointsByIPFallback tests matching core Endpoints objects when slices cannot be listed
+func TestSearchEndpointsByIPFallback(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.7", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "db-0"}}},
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.9"}},
+			Ports:             []corev1.EndpointPort{{Name: "postgres", Port: 5432, Protocol: corev1.ProtocolTCP}},
+		}},
+	})
+	fakeClient.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "")
+	})
+	client := &K8sClient{Clientset: fakeClient, Namespaces: []string{"default"}}
+
+	endpoints, err := client.SearchEndpointsByIP(context.Background(), "10.0.0.9")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "db", endpoints[0].Service)
+	assert.False(t, endpoints[0].Ready)
+	assert.Equal(t, []string{"5432/TCP (postgres)"}, endpoints[0].Ports)
+
+	endpoints, err = client.SearchEndpointsByIP(context.Background(), "10.0.0.7")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "db-0", endpoints[0].TargetName)
+}
+
+// TestDedupEndpointResults tests dropping endpoints reported by several contexts of the same cluster
+func TestDedupEndpointResults(t *testing.T) {
+	db := EndpointInfo{Service: "db", Namespace: "default", Address: "10.0.0.7"}
+	dbHeadless := EndpointInfo{Service: "db-headless", Namespace: "default", Address: "10.0.0.7"}
+
+	results := DedupEndpointResults([]EndpointResultWithContext{
+		{Context: "admin", Server: "https://cluster", Namespace: "default", Endpoints: []EndpointInfo{db}},
+		{Context: "viewer", Server: "https://cluster", Namespace: "default", Endpoints: []EndpointInfo{db, dbHeadless}},
+	})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, []EndpointInfo{db}, results[0].Endpoints)
+	assert.Equal(t, []EndpointInfo{dbHeadless}, results[1].Endpoints)
+}
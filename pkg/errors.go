@@ -0,0 +1,42 @@
+package pkg
+
+import "errors"
+
+// Sentinel errors returned (wrapped) by searches, so callers can tell failures apart with errors.Is
+var (
+	// ErrInvalidIP is returned for queries that are not valid IPv4 or IPv6 addresses
+	ErrInvalidIP = errors.New("invalid IP address")
+	// ErrInvalidQuery is returned for empty or malformed search queries
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrInvalidOptions is returned for invalid search or output options
+	ErrInvalidOptions = errors.New("invalid options")
+	// ErrKubeconfig is returned when the kubeconfig cannot be loaded or a context cannot be used
+	ErrKubeconfig = errors.New("kubeconfig error")
+	// ErrNoAccess is returned when the API server rejects a request as forbidden or unauthorized
+	ErrNoAccess = errors.New("no access")
+	// ErrUnreachable is returned when a cluster does not answer the connectivity check
+	ErrUnreachable = errors.New("cluster unreachable")
+)
+
+// kindError tags an error with one of the sentinel errors while keeping its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes both the sentinel and the original error to errors.Is and errors.As
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind tags err with the sentinel kind; nil errors and errors already of that kind are returned unchanged
+func withKind(kind error, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
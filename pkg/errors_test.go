@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestWithKind tests tagging errors with sentinel errors without changing their message
+func TestWithKind(t *testing.T) {
+	cause := errors.New("boom")
+	err := withKind(ErrKubeconfig, fmt.Errorf("failed to load kubeconfig: %w", cause))
+
+	assert.EqualError(t, err, "failed to load kubeconfig: boom")
+	assert.ErrorIs(t, err, ErrKubeconfig)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrNoAccess)
+
+	assert.NoError(t, withKind(ErrKubeconfig, nil))
+	assert.Same(t, err, withKind(ErrKubeconfig, err))
+}
+
+// TestTypedErrors tests that kubeconfig, option and permission failures return their sentinel errors
+func TestTypedErrors(t *testing.T) {
+	_, err := LoadKubeConfig(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, ErrKubeconfig)
+
+	_, err = NewK8sClient(filepath.Join(t.TempDir(), "missing"), "", nil)
+	assert.ErrorIs(t, err, ErrKubeconfig)
+
+	err = SearchOptions{FieldSelector: "metadata.uid=1"}.Validate()
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	// Permission errors are tagged, but still recognized as API errors
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "nginx", nil)
+	})
+	client := &K8sClient{Clientset: fakeClient}
+
+	_, err = client.GetPod(context.Background(), "default", "nginx")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoAccess)
+	assert.True(t, apierrors.IsForbidden(err))
+
+	_, err = fakeClient.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	assert.NotErrorIs(t, err, ErrNoAccess)
+}
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// execStdinUnavailableMessage tells exec credential plugins that want to prompt why they cannot
+const execStdinUnavailableMessage = "k8sx runs credential plugins non-interactively; log in with the plugin first"
+
+// errExecCredentialsFetched stops the request used to run an exec credential plugin before it leaves the host
+var errExecCredentialsFetched = errors.New("exec credentials fetched")
+
+// prepareExecCredentials makes the exec credential plugin of restConfig, if any, run non-interactively
+// and runs it once within timeout (0 = no timeout, leaving the plugin to run on the first request).
+// client-go runs plugins without a deadline, so a plugin that hangs (e.g. waiting for a login in a
+// browser) would otherwise hold the search of its context forever. The credentials are cached by
+// client-go, so the clients built from restConfig reuse them.
+func prepareExecCredentials(restConfig *rest.Config, timeout time.Duration) error {
+	if restConfig.ExecProvider == nil {
+		return nil
+	}
+
+	// Searches run unattended over many contexts, so plugins must fail instead of prompting
+	execConfig := *restConfig.ExecProvider
+	execConfig.StdinUnavailable = true
+	execConfig.StdinUnavailableMessage = execStdinUnavailableMessage
+	restConfig.ExecProvider = &execConfig
+
+	if timeout <= 0 {
+		return nil
+	}
+
+	// The plugin runs when the exec round tripper wraps a request; dialing fails right after, so nothing is sent
+	fetchConfig := rest.CopyConfig(restConfig)
+	fetchConfig.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, errExecCredentialsFetched
+	}
+	httpClient, err := rest.HTTPClientFor(fetchConfig)
+	if err != nil {
+		return fmt.Errorf("failed to set up exec credential plugin: %w", err)
+	}
+	request, err := http.NewRequest(http.MethodGet, fetchConfig.Host, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set up exec credential plugin: %w", err)
+	}
+
+	// The plugin cannot be cancelled, so one that hangs is left running in the background
+	done := make(chan error, 1)
+	go func() {
+		_, err := httpClient.Do(request)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && !errors.Is(err, errExecCredentialsFetched) {
+			return fmt.Errorf("exec credential plugin %q failed: %w", execConfig.Command, err)
+		}
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("exec credential plugin %q did not return credentials within %s", execConfig.Command, timeout)
+	}
+}
@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// writeExecPluginKubeconfig writes a kubeconfig whose contexts authenticate with the given fake exec plugin
+// scripts. client-go only authenticates to https servers.
+func writeExecPluginKubeconfig(t *testing.T, server string, plugins map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	var contexts, users strings.Builder
+	for name, script := range plugins {
+		pluginPath := filepath.Join(dir, name+"-plugin")
+		require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+		contexts.WriteString(`- context:
+    cluster: test-cluster
+    user: ` + name + `
+  name: ` + name + `
+`)
+		users.WriteString(`- name: ` + name + `
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: ` + pluginPath + `
+      interactiveMode: IfAvailable
+`)
+	}
+
+	kubeconfigPath := filepath.Join(dir, "kubeconfig")
+	kubeconfigContent := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: ` + server + `
+    insecure-skip-tls-verify: true
+  name: test-cluster
+contexts:
+` + contexts.String() + `users:
+` + users.String()
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644))
+	return kubeconfigPath
+}
+
+// TestSearchExecCredentialPlugins tests that exec credential plugins authenticate their context and that
+// a failing or hanging plugin skips its context instead of failing or holding the search
+func TestSearchExecCredentialPlugins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer plugin-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"nginx-1","namespace":"default"}}]}`))
+	}))
+	defer server.Close()
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	kubeconfigPath := writeExecPluginKubeconfig(t, server.URL, map[string]string{
+		"ok": `echo call >> ` + calls + `
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"plugin-token"}}'`,
+		"failing": `echo "token expired, run login" >&2
+exit 1`,
+		"hanging": `exec sleep 2`,
+	})
+
+	skipped := map[string]string{}
+	opts := SearchOptions{
+		ExecTimeout: 500 * time.Millisecond,
+		OnContextSkipped: func(contextName string, err error) {
+			skipped[contextName] = err.Error()
+		},
+	}
+	started := time.Now()
+	results, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "nginx", []string{"default"}, nil, opts)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(started), 2*time.Second)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, "ok", results[0].Context)
+	require.Len(t, results[0].Pods, 1)
+
+	require.Len(t, skipped, 2)
+	assert.Contains(t, skipped["failing"], "failed")
+	assert.Contains(t, skipped["hanging"], "did not return credentials within 500ms")
+
+	// The credentials fetched when the client was created are reused by its requests
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "call"))
+
+	assert.ErrorIs(t, SearchOptions{ExecTimeout: -time.Second}.Validate(), ErrInvalidOptions)
+}
+
+// TestPrepareExecCredentials tests that exec plugins run non-interactively, so one that needs a prompt fails fast
+func TestPrepareExecCredentials(t *testing.T) {
+	restConfig := &rest.Config{
+		Host: "https://127.0.0.1:6443",
+		ExecProvider: &clientcmdapi.ExecConfig{
+			APIVersion:      "client.authentication.k8s.io/v1",
+			Command:         "login-plugin",
+			InteractiveMode: clientcmdapi.AlwaysExecInteractiveMode,
+		},
+	}
+	original := restConfig.ExecProvider
+
+	err := prepareExecCredentials(restConfig, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exec plugin cannot support interactive mode")
+	assert.True(t, restConfig.ExecProvider.StdinUnavailable)
+	assert.False(t, original.StdinUnavailable)
+
+	// Without a timeout the plugin is left to run on the first request
+	restConfig = &rest.Config{Host: "https://127.0.0.1:6443", ExecProvider: original}
+	require.NoError(t, prepareExecCredentials(restConfig, 0))
+	assert.True(t, restConfig.ExecProvider.StdinUnavailable)
+
+	// Configs without a plugin are left alone
+	require.NoError(t, prepareExecCredentials(&rest.Config{Host: "https://127.0.0.1:6443", BearerToken: "token"}, time.Second))
+
+	kubeconfigPath := writeExecPluginKubeconfig(t, "https://127.0.0.1:6443", map[string]string{"prod": "exit 1"})
+	_, err = NewK8sClientWithOptions(kubeconfigPath, "prod", nil, SearchOptions{ExecTimeout: time.Second})
+	assert.ErrorIs(t, err, ErrNoAccess)
+}
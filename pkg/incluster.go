@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"os"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// InClusterContext is the name of the single context used when running inside a pod without a kubeconfig
+const InClusterContext = "in-cluster"
+
+// serviceAccountNamespaceFile holds the namespace of the pod's service account
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// inClusterConfig returns the pod's service account config when the kubeconfig file does not exist.
+// It returns nil when a kubeconfig exists or k8sx is not running inside a pod.
+func inClusterConfig(kubeconfigPath string) *rest.Config {
+	if _, err := os.Stat(kubeconfigPath); !os.IsNotExist(err) {
+		return nil
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil
+	}
+	return restConfig
+}
+
+// inClusterKubeConfig builds a kubeconfig with the single in-cluster context,
+// so context selection and deduplication work the same as with a kubeconfig file
+func inClusterKubeConfig(restConfig *rest.Config) *api.Config {
+	namespace := "default"
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil && strings.TrimSpace(string(data)) != "" {
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	config := api.NewConfig()
+	config.Clusters[InClusterContext] = &api.Cluster{Server: restConfig.Host}
+	config.AuthInfos[InClusterContext] = &api.AuthInfo{}
+	config.Contexts[InClusterContext] = &api.Context{
+		Cluster:   InClusterContext,
+		AuthInfo:  InClusterContext,
+		Namespace: namespace,
+	}
+	config.CurrentContext = InClusterContext
+	return config
+}
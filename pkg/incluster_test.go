@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+// TestInClusterKubeConfig tests the synthetic kubeconfig used when running inside a pod
+func TestInClusterKubeConfig(t *testing.T) {
+	config := inClusterKubeConfig(&rest.Config{Host: "https://10.96.0.1:443"})
+
+	assert.Equal(t, []string{InClusterContext}, GetContexts(config))
+	assert.Equal(t, InClusterContext, config.CurrentContext)
+	assert.Equal(t, "https://10.96.0.1:443", clusterServer(config, InClusterContext))
+
+	contexts, err := SelectContexts(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{InClusterContext}, contexts)
+}
+
+// TestInClusterConfigWithKubeconfig tests that an existing kubeconfig always takes precedence
+func TestInClusterConfigWithKubeconfig(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0644))
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	assert.Nil(t, inClusterConfig(kubeconfigPath))
+}
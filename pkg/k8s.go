@@ -2,30 +2,367 @@ package pkg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // K8sClient represents a Kubernetes client with context
 type K8sClient struct {
-	Clientset  kubernetes.Interface
-	Config     *api.Config
-	Namespaces []string
+	Clientset   kubernetes.Interface
+	Config      *api.Config
+	ContextName string
+	Namespaces  []string
+	Options     SearchOptions
+	// Dynamic lists resources of kinds not known at build time, like custom resources (nil = not available)
+	Dynamic dynamic.Interface
+	// apiCalls counts the requests sent to the API server, shared with the namespace clients
+	// derived from this client (nil = not counted, e.g. for clients not created by NewK8sClient)
+	apiCalls *int64
+	// namespaceLimit bounds the namespaces searched at once, lowered when requests are throttled (nil = none)
+	namespaceLimit *concurrencyLimit
 }
 
-// LoadKubeConfig loads kubeconfig from the specified path
+// APICalls returns the number of requests the client has sent to the API server, retries included
+func (c *K8sClient) APICalls() int64 {
+	if c.apiCalls == nil {
+		return 0
+	}
+	return atomic.LoadInt64(c.apiCalls)
+}
+
+// countAPICall records a request sent to the API server
+func (c *K8sClient) countAPICall() {
+	if c.apiCalls != nil {
+		atomic.AddInt64(c.apiCalls, 1)
+	}
+}
+
+// Server returns the API server URL of the cluster of the client's context ("" when unknown)
+func (c *K8sClient) Server() string {
+	if c.Config == nil {
+		return ""
+	}
+	return clusterServer(c.Config, c.ContextName)
+}
+
+// forNamespace returns a client searching only namespace, sharing the connection and API call count of c
+func (c *K8sClient) forNamespace(namespace string) *K8sClient {
+	return &K8sClient{
+		Clientset:      c.Clientset,
+		Dynamic:        c.Dynamic,
+		Config:         c.Config,
+		ContextName:    c.ContextName,
+		Namespaces:     []string{namespace},
+		Options:        c.Options,
+		apiCalls:       c.apiCalls,
+		namespaceLimit: c.namespaceLimit,
+	}
+}
+
+// SearchOptions holds settings that apply to every namespace of a search
+type SearchOptions struct {
+	// Retries is the number of times a transient API error is retried before giving up
+	Retries int
+	// Since keeps only pods and services created within this duration (0 = no filter)
+	Since time.Duration
+	// FieldSelector is passed to the API server when listing pods
+	FieldSelector string
+	// RunningOnly keeps only pods in the Running phase, dropping pending, completed, failed and evicted ones
+	RunningOnly bool
+	// ServiceAccount keeps only pods running as this service account ("" = no filter)
+	ServiceAccount string
+	// Annotations keeps only pods matching all filters: "key" (present) or "key=glob"
+	Annotations []string
+	// OwnerKinds keeps only pods whose top owner kind is one of these, case-insensitive ("none" = no owner)
+	OwnerKinds []string
+	// PrecheckTimeout bounds the connectivity check done before searching a context (0 = no check)
+	PrecheckTimeout time.Duration
+	// OnContextSkipped is called when a context is skipped because its client cannot be created
+	// (e.g. its credential plugin failed) or its cluster is unreachable
+	OnContextSkipped func(contextName string, err error)
+	// OnProgress is called before each namespace is searched and once more when the search is done
+	OnProgress func(progress SearchProgress)
+	// MaxResults caps the matches a search collects (0 = no cap); all-contexts searches skip
+	// the remaining namespaces and contexts once it is reached
+	MaxResults int
+	// HostIPOnly makes IP searches match pod host IPs only, skipping pod IPs and services
+	HostIPOnly bool
+	// ServicesOnly makes IP searches match services only, skipping pods
+	ServicesOnly bool
+	// IPContains makes IP searches match addresses containing the query, e.g. ".137", instead of
+	// addresses equal to an IP address or inside a CIDR range
+	IPContains bool
+	// ExactName makes name searches match whole names only instead of names containing the query
+	ExactName bool
+	// MatchContainers makes name searches also match the names of init and ephemeral containers
+	MatchContainers bool
+	// ContainerPorts makes port searches also match pods whose containers declare the port
+	ContainerPorts bool
+	// ContextFromNamespace makes all-contexts searches given namespaces search only the contexts that have
+	// at least one of them, see ContextsWithNamespaces
+	ContextFromNamespace bool
+	// NamespacesDiscovered marks namespaces found by probing which ones one context can access rather than
+	// given by the user. Contexts whose credentials can list pods in all namespaces search them all at once
+	// instead, see forEachNamespaceOrAll.
+	NamespacesDiscovered bool
+	// OnContextSearched is called with the timing of each context once all-contexts searches are done with it
+	OnContextSearched func(timing ContextTiming)
+	// OnIPResult is called with the matches of each namespace as soon as SearchByIPAllContexts finds them,
+	// before they are deduplicated and sorted; calls are serialized
+	OnIPResult func(result SearchResultWithContext)
+	// OnPodResult is called likewise by the all-contexts pod searches, e.g. SearchByNameAllContexts
+	OnPodResult func(result PodResultWithContext)
+	// ContextConcurrency is the number of contexts all-contexts searches search in parallel (0 = 1)
+	ContextConcurrency int
+	// NamespaceConcurrency is the number of namespaces searched in parallel within each context (0 = 1)
+	NamespaceConcurrency int
+	// OnNamespaceSkipped is called when all-contexts searches skip a namespace still throttled after retrying
+	OnNamespaceSkipped func(contextName string, namespace string, err error)
+	// OnSearchFailed is called when all-contexts searches fail to search a namespace for another reason
+	// than throttling, or with an empty namespace when the namespaces of a context cannot be listed
+	OnSearchFailed func(contextName string, namespace string, err error)
+	// ContextTimeout bounds the search of each context in all-contexts searches, on top of the deadline
+	// of the whole search; a context running out of time is reported as skipped (0 = no limit)
+	ContextTimeout time.Duration
+	// QPS and Burst configure the client-side rate limiter of each context's client (0 = client-go defaults)
+	QPS   float32
+	Burst int
+	// ExecTimeout bounds the exec credential plugin of a context, which is run once when its client
+	// is created (0 = no limit, the plugin runs on the first request)
+	ExecTimeout time.Duration
+}
+
+// SearchProgress reports how far an all-contexts search has come
+type SearchProgress struct {
+	Context        string
+	ContextIndex   int
+	Contexts       int
+	NamespaceIndex int
+	Namespaces     int
+	Done           bool
+}
+
+// ContextTiming reports how long searching one context took and how many API calls it made
+type ContextTiming struct {
+	Context    string
+	Duration   time.Duration
+	Namespaces int
+	APICalls   int64
+	// Skipped is set when the context was skipped as unreachable
+	Skipped bool
+	// TimedOut is set when the context's search was cut short by ContextTimeout
+	TimedOut bool
+}
+
+// Fraction returns the completed share of the search between 0 and 1, counting each context equally
+func (p SearchProgress) Fraction() float64 {
+	if p.Done {
+		return 1
+	}
+	if p.Contexts == 0 {
+		return 0
+	}
+	done := float64(p.ContextIndex - 1)
+	if p.Namespaces > 0 {
+		done += float64(p.NamespaceIndex-1) / float64(p.Namespaces)
+	}
+	return done / float64(p.Contexts)
+}
+
+// reportProgress calls OnProgress when set
+func (o SearchOptions) reportProgress(progress SearchProgress) {
+	if o.OnProgress != nil {
+		o.OnProgress(progress)
+	}
+}
+
+// reportContextSearched calls OnContextSearched when set
+func (o SearchOptions) reportContextSearched(timing ContextTiming) {
+	if o.OnContextSearched != nil {
+		o.OnContextSearched(timing)
+	}
+}
+
+// matchesName reports whether candidate is name, or contains it unless ExactName is set
+func (o SearchOptions) matchesName(candidate string, name string) bool {
+	if o.ExactName {
+		return candidate == name
+	}
+	return strings.Contains(candidate, name)
+}
+
+// matchingContainers returns the init and ephemeral containers of pod whose names match name,
+// prefixed with their kind, when MatchContainers is set
+func (o SearchOptions) matchingContainers(pod *corev1.Pod, name string) []string {
+	if !o.MatchContainers {
+		return nil
+	}
+
+	var matched []string
+	for _, container := range pod.Spec.InitContainers {
+		if o.matchesName(container.Name, name) {
+			matched = append(matched, "init: "+container.Name)
+		}
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if o.matchesName(container.Name, name) {
+			matched = append(matched, "ephemeral: "+container.Name)
+		}
+	}
+	return matched
+}
+
+// serializeCallbacks returns options whose callbacks never run concurrently, so callers of
+// parallel searches can update shared state (e.g. a progress bar) from them without locking
+func (o SearchOptions) serializeCallbacks() SearchOptions {
+	var mu sync.Mutex
+	if skipped := o.OnNamespaceSkipped; skipped != nil {
+		o.OnNamespaceSkipped = func(contextName string, namespace string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			skipped(contextName, namespace, err)
+		}
+	}
+	if failed := o.OnSearchFailed; failed != nil {
+		o.OnSearchFailed = func(contextName string, namespace string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed(contextName, namespace, err)
+		}
+	}
+	if skipped := o.OnContextSkipped; skipped != nil {
+		o.OnContextSkipped = func(contextName string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			skipped(contextName, err)
+		}
+	}
+	if progress := o.OnProgress; progress != nil {
+		o.OnProgress = func(p SearchProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress(p)
+		}
+	}
+	if searched := o.OnContextSearched; searched != nil {
+		o.OnContextSearched = func(timing ContextTiming) {
+			mu.Lock()
+			defer mu.Unlock()
+			searched(timing)
+		}
+	}
+	return o
+}
+
+// podFieldSelectorFields are the pod fields the API server supports in field selectors
+var podFieldSelectorFields = map[string]bool{
+	"metadata.name":            true,
+	"metadata.namespace":       true,
+	"spec.nodeName":            true,
+	"spec.restartPolicy":       true,
+	"spec.schedulerName":       true,
+	"spec.serviceAccountName":  true,
+	"spec.hostNetwork":         true,
+	"status.phase":             true,
+	"status.podIP":             true,
+	"status.podIPs":            true,
+	"status.nominatedNodeName": true,
+}
+
+// Validate checks the options before a search starts, so invalid filters fail fast
+// instead of every namespace being skipped with the same error
+func (o SearchOptions) Validate() error {
+	if o.FieldSelector != "" {
+		selector, err := fields.ParseSelector(o.FieldSelector)
+		if err != nil {
+			return withKind(ErrInvalidOptions, fmt.Errorf("invalid field selector %q: %w", o.FieldSelector, err))
+		}
+		for _, requirement := range selector.Requirements() {
+			if !podFieldSelectorFields[requirement.Field] {
+				return withKind(ErrInvalidOptions, fmt.Errorf("field selector field %q is not supported for pods", requirement.Field))
+			}
+		}
+	}
+
+	for _, filter := range o.Annotations {
+		key, pattern, _ := strings.Cut(filter, "=")
+		if key == "" {
+			return withKind(ErrInvalidOptions, fmt.Errorf("invalid annotation filter %q: key cannot be empty", filter))
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return withKind(ErrInvalidOptions, fmt.Errorf("invalid annotation filter %q: %w", filter, err))
+		}
+	}
+
+	for _, kind := range o.OwnerKinds {
+		if strings.TrimSpace(kind) == "" {
+			return withKind(ErrInvalidOptions, fmt.Errorf("owner kind cannot be empty"))
+		}
+	}
+
+	if o.MaxResults < 0 {
+		return withKind(ErrInvalidOptions, fmt.Errorf("max results cannot be negative"))
+	}
+	if o.ContextConcurrency < 0 || o.NamespaceConcurrency < 0 {
+		return withKind(ErrInvalidOptions, fmt.Errorf("concurrency cannot be negative"))
+	}
+	if o.ContextTimeout < 0 {
+		return withKind(ErrInvalidOptions, fmt.Errorf("per-context timeout cannot be negative"))
+	}
+	if o.QPS < 0 || o.Burst < 0 {
+		return withKind(ErrInvalidOptions, fmt.Errorf("qps and burst cannot be negative"))
+	}
+	if o.ExecTimeout < 0 {
+		return withKind(ErrInvalidOptions, fmt.Errorf("exec plugin timeout cannot be negative"))
+	}
+	if o.HostIPOnly && o.ServicesOnly {
+		return withKind(ErrInvalidOptions, fmt.Errorf("host IP searches cannot be limited to services"))
+	}
+	return nil
+}
+
+// LoadKubeConfig loads kubeconfig from the specified path, expanding ~ and environment variables
+// in it and following symlinks. Inside a pod without a kubeconfig file, a config with the single
+// in-cluster context is returned. A path listing several files, e.g. "~/.kube/config,oneoff.yaml",
+// loads them merged, see mergeKubeconfigFiles.
 func LoadKubeConfig(kubeconfigPath string) (*api.Config, error) {
-	config, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if paths := splitKubeconfigPaths(kubeconfigPath); len(paths) > 1 {
+		config, _, err := mergeKubeconfigFiles(paths)
+		return config, err
+	}
+
+	if restConfig := inClusterConfig(expandKubeconfigPath(kubeconfigPath)); restConfig != nil {
+		return inClusterKubeConfig(restConfig), nil
+	}
+
+	path, err := resolveKubeconfigPath(kubeconfigPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		return nil, err
+	}
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, withKind(ErrKubeconfig, fmt.Errorf("failed to load kubeconfig: %w", err))
 	}
 	return config, nil
 }
@@ -36,11 +373,73 @@ func GetContexts(config *api.Config) []string {
 	for name := range config.Contexts {
 		contexts = append(contexts, name)
 	}
+	// Map iteration order is random, sort so output is stable between runs
+	sort.Strings(contexts)
 	return contexts
 }
 
+// ContextDetail is a kubeconfig context with the cluster, user and namespace it refers to
+type ContextDetail struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	// Server is the API server URL of the cluster, "" when the cluster is missing from kubeconfig
+	Server string `json:"server,omitempty"`
+}
+
+// GetContextDetails returns all contexts from kubeconfig with their cluster, user, namespace and
+// API server URL, sorted by name like GetContexts
+func GetContextDetails(config *api.Config) []ContextDetail {
+	details := make([]ContextDetail, 0, len(config.Contexts))
+	for _, name := range GetContexts(config) {
+		kubeContext := config.Contexts[name]
+		details = append(details, ContextDetail{
+			Name:      name,
+			Cluster:   kubeContext.Cluster,
+			User:      kubeContext.AuthInfo,
+			Namespace: kubeContext.Namespace,
+			Server:    clusterServer(config, name),
+		})
+	}
+	return details
+}
+
+// ContextNamespace returns the namespace configured for a context in kubeconfig, like kubectl uses
+// by default, or "" when it has none. An empty contextName means the current context.
+func ContextNamespace(config *api.Config, contextName string) string {
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if kubeContext, ok := config.Contexts[contextName]; ok {
+		return kubeContext.Namespace
+	}
+	return ""
+}
+
+// SelectContexts returns the contexts to search: all contexts from kubeconfig when
+// none are requested, otherwise the requested ones after checking they exist
+func SelectContexts(config *api.Config, contexts []string) ([]string, error) {
+	if len(contexts) == 0 {
+		return GetContexts(config), nil
+	}
+
+	for _, name := range contexts {
+		if _, ok := config.Contexts[name]; !ok {
+			return nil, withKind(ErrKubeconfig, fmt.Errorf("context %q not found in kubeconfig", name))
+		}
+	}
+	return contexts, nil
+}
+
 // NewK8sClient creates a new Kubernetes client from kubeconfig path and context
 func NewK8sClient(kubeconfigPath string, contextName string, namespaces []string) (*K8sClient, error) {
+	return NewK8sClientWithOptions(kubeconfigPath, contextName, namespaces, SearchOptions{})
+}
+
+// NewK8sClientWithOptions creates a client like NewK8sClient whose searches use opts,
+// with the client-side rate limiter set from opts.QPS and opts.Burst
+func NewK8sClientWithOptions(kubeconfigPath string, contextName string, namespaces []string, opts SearchOptions) (*K8sClient, error) {
 	config, err := LoadKubeConfig(kubeconfigPath)
 	if err != nil {
 		return nil, err
@@ -51,349 +450,1924 @@ func NewK8sClient(kubeconfigPath string, contextName string, namespaces []string
 		contextName = config.CurrentContext
 	}
 
-	// Build client config
-	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
-		&clientcmd.ConfigOverrides{CurrentContext: contextName},
-	)
+	// Use the pod's service account when running in-cluster without a kubeconfig
+	merged := len(splitKubeconfigPaths(kubeconfigPath)) > 1
+	var restConfig *rest.Config
+	if !merged {
+		restConfig = inClusterConfig(expandKubeconfigPath(kubeconfigPath))
+	}
+	if restConfig != nil {
+		if contextName != InClusterContext {
+			return nil, withKind(ErrKubeconfig, fmt.Errorf("context %q not found (only %q is available in-cluster)", contextName, InClusterContext))
+		}
+	} else {
+		// Build client config; merged files are only known under the names given by mergeKubeconfigFiles
+		var clientConfig clientcmd.ClientConfig
+		if merged {
+			clientConfig = clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil)
+		} else {
+			path, err := resolveKubeconfigPath(kubeconfigPath)
+			if err != nil {
+				return nil, err
+			}
+			clientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+				&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+				&clientcmd.ConfigOverrides{CurrentContext: contextName},
+			)
+		}
 
-	restConfig, err := clientConfig.ClientConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create rest config: %w", err)
+		restConfig, err = clientConfig.ClientConfig()
+		if err != nil {
+			return nil, withKind(ErrKubeconfig, fmt.Errorf("failed to create rest config: %w", err))
+		}
+		if err := prepareExecCredentials(restConfig, opts.ExecTimeout); err != nil {
+			return nil, withKind(ErrNoAccess, err)
+		}
+	}
+
+	if opts.QPS > 0 {
+		restConfig.QPS = opts.QPS
+		// client-go only fills in the default burst when QPS is unset too
+		if restConfig.Burst == 0 {
+			restConfig.Burst = rest.DefaultBurst
+		}
+	}
+	if opts.Burst > 0 {
+		restConfig.Burst = opts.Burst
 	}
 
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
 	return &K8sClient{
-		Clientset:  clientset,
-		Config:     config,
-		Namespaces: namespaces,
+		Clientset:   clientset,
+		Dynamic:     dynamicClient,
+		Config:      config,
+		ContextName: contextName,
+		Namespaces:  namespaces,
+		Options:     opts,
+		apiCalls:    new(int64),
 	}, nil
 }
 
 // PodInfo represents pod information
 type PodInfo struct {
-	Name        string
-	Namespace   string
-	PodIP       string
-	HostIP      string
-	OwnerKind   string
-	OwnerName   string
-	Labels      map[string]string
-	Annotations map[string]string
+	UID         string            `json:"uid"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	PodIP       string            `json:"podIP"`
+	PodIPs      []string          `json:"podIPs,omitempty"`
+	HostIP      string            `json:"hostIP"`
+	HostIPs     []string          `json:"hostIPs,omitempty"`
+	OwnerKind   string            `json:"ownerKind,omitempty"`
+	OwnerName   string            `json:"ownerName,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Containers  []ContainerInfo   `json:"containers,omitempty"`
+	CreatedAt   metav1.Time       `json:"createdAt"`
+	NodeName    string            `json:"nodeName,omitempty"`
+	Phase       string            `json:"phase,omitempty"`
+	QOSClass    string            `json:"qosClass,omitempty"`
+	// ServiceAccount is the service account the pod runs as
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// Terminated is set for pods that completed, failed or are being deleted: an IP they held
+	// may since have been assigned to another pod
+	Terminated bool `json:"terminated,omitempty"`
+	// DeletedAt is when the pod was requested to be deleted
+	DeletedAt *metav1.Time `json:"deletedAt,omitempty"`
+	// MatchedOn is the address an IP search matched (PodIP or HostIP)
+	MatchedOn string `json:"matchedOn,omitempty"`
+	// MatchedContainers lists the init and ephemeral containers a name search with MatchContainers
+	// matched, e.g. "ephemeral: debugger-x7k2"
+	MatchedContainers []string `json:"matchedContainers,omitempty"`
+	// MatchedPorts lists the container ports a port search with ContainerPorts matched,
+	// e.g. "nginx: 8080/TCP (http)"
+	MatchedPorts []string `json:"matchedPorts,omitempty"`
+	// Managers are the field managers that wrote the pod, telling who deployed it,
+	// e.g. argocd-controller, helm or kubectl-client-side-apply, see getManagers
+	Managers []string `json:"managers,omitempty"`
+}
+
+// Age returns how long ago the pod was created
+func (p PodInfo) Age() time.Duration {
+	return time.Since(p.CreatedAt.Time)
+}
+
+// ContainerInfo represents container information joined from the pod spec and status
+type ContainerInfo struct {
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	State        string `json:"state"`
 }
 
 // ServiceInfo represents service information
 type ServiceInfo struct {
-	Name        string
-	Namespace   string
-	ClusterIP   string
-	ExternalIPs []string
-	Type        string
-	Ports       []corev1.ServicePort
-	Selector    map[string]string
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	ClusterIP   string            `json:"clusterIP"`
+	ClusterIPs  []string          `json:"clusterIPs,omitempty"`
+	ExternalIPs []string          `json:"externalIPs,omitempty"`
+	Type        string            `json:"type"`
+	Ports       []PortInfo        `json:"ports,omitempty"`
+	NodePorts   []int32           `json:"nodePorts,omitempty"`
+	Selector    map[string]string `json:"selector,omitempty"`
+	// ExternalName is the DNS name an ExternalName service aliases
+	ExternalName string `json:"externalName,omitempty"`
+	// EndpointIPs are the ready pod IPs of a headless service, which has no cluster IP
+	EndpointIPs []string `json:"endpointIPs,omitempty"`
+	// NotReadyEndpointIPs are the pod IPs of a headless service that are not ready to serve
+	NotReadyEndpointIPs []string `json:"notReadyEndpointIPs,omitempty"`
+	// CreatedAt is when the service was created, which Since filters on
+	CreatedAt metav1.Time `json:"createdAt"`
+	// SessionAffinity and ExternalTrafficPolicy are shown by wide output
+	SessionAffinity       string `json:"sessionAffinity,omitempty"`
+	ExternalTrafficPolicy string `json:"externalTrafficPolicy,omitempty"`
+	// HealthCheckNodePort is the node port load balancers check for local endpoints, allocated
+	// for LoadBalancer services with the Local external traffic policy (0 = none)
+	HealthCheckNodePort int32 `json:"healthCheckNodePort,omitempty"`
+	// MatchedOn is the address an IP search matched (ClusterIP, ExternalIP or LoadBalancerIngress)
+	MatchedOn string `json:"matchedOn,omitempty"`
+}
+
+// PortInfo is a service port, flattened so the serialized output does not depend on the client-go types
+type PortInfo struct {
+	Name string `json:"name,omitempty"`
+	Port int32  `json:"port"`
+	// TargetPort is the pod port number or name the service forwards to
+	TargetPort string `json:"targetPort"`
+	// NodePort is the port allocated on every node (0 = none)
+	NodePort int32  `json:"nodePort,omitempty"`
+	Protocol string `json:"protocol"`
+}
+
+// newPortInfo converts a service port into PortInfo
+func newPortInfo(port corev1.ServicePort) PortInfo {
+	return PortInfo{
+		Name:       port.Name,
+		Port:       port.Port,
+		TargetPort: FormatTargetPort(port.TargetPort),
+		NodePort:   port.NodePort,
+		Protocol:   string(port.Protocol),
+	}
+}
+
+// IsHeadless reports whether the service has no cluster IP and resolves directly to its pods
+func (s ServiceInfo) IsHeadless() bool {
+	return s.ClusterIP == corev1.ClusterIPNone
 }
 
-// SearchByIP searches for resources by IP address (pod IP, service IP, or LoadBalancer IP)
+// SearchByIP searches for resources by IP address or CIDR range (pod IP, host IP, service IP, or LoadBalancer IP).
+// With HostIPOnly set only the host IPs of pods are matched, with ServicesOnly only services.
 func (c *K8sClient) SearchByIP(ctx context.Context, ip string) ([]PodInfo, []ServiceInfo, error) {
 	pods := []PodInfo{}
 	services := []ServiceInfo{}
 
+	match := c.Options.ipMatcher(ip)
+
 	// Search in all specified namespaces
 	for _, namespace := range c.Namespaces {
-		// Search pods by IP
-		podList, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			// Skip silently if permission denied
-			if isPermissionError(err) {
-				continue
+		// Search pods by IP, unless only services are searched
+		if !c.Options.ServicesOnly {
+			err := c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+				if !c.matchesPodFilters(pod) {
+					return true
+				}
+				if matchedOn := podMatchedOn(pod, match, c.Options.HostIPOnly); matchedOn != "" {
+					info := newPodInfo(pod)
+					info.MatchedOn = matchedOn
+					pods = append(pods, info)
+				}
+				return true
+			})
+			if err != nil {
+				// Skip silently if permission denied
+				if isPermissionError(err) {
+					getMetrics().IncPermissionDenied(c.ContextName)
+					continue
+				}
+				return nil, nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
 			}
-			return nil, nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
 		}
 
-		for _, pod := range podList.Items {
-			if pod.Status.PodIP == ip || pod.Status.HostIP == ip {
-				ownerKind, ownerName := getOwnerInfo(&pod)
-				pods = append(pods, PodInfo{
-					Name:        pod.Name,
-					Namespace:   pod.Namespace,
-					PodIP:       pod.Status.PodIP,
-					HostIP:      pod.Status.HostIP,
-					OwnerKind:   ownerKind,
-					OwnerName:   ownerName,
-					Labels:      pod.Labels,
-					Annotations: pod.Annotations,
-				})
-			}
+		// Services run on no node, so host IP searches skip them
+		if c.Options.HostIPOnly {
+			continue
 		}
 
 		// Search services by ClusterIP or LoadBalancer IP
-		svcList, err := c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		matched, err := c.servicesMatchingIP(ctx, namespace, match)
 		if err != nil {
 			// Skip silently if permission denied
 			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
 				continue
 			}
 			return nil, nil, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
 		}
+		services = append(services, matched...)
+	}
 
-		for _, svc := range svcList.Items {
-			matched := false
-
-			// Check ClusterIP
-			if svc.Spec.ClusterIP == ip {
-				matched = true
-			}
-
-			// Check ExternalIPs
-			for _, externalIP := range svc.Spec.ExternalIPs {
-				if externalIP == ip {
-					matched = true
-					break
-				}
-			}
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortIPPods(pods)
+	sortServices(services)
+	pods, services = newResultLimit(c.Options.MaxResults, nil).takePodsAndServices(pods, services)
+	return pods, services, nil
+}
 
-			// Check LoadBalancer IPs
-			if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
-				for _, ingress := range svc.Status.LoadBalancer.Ingress {
-					if ingress.IP == ip {
-						matched = true
-						break
-					}
-				}
-			}
+// servicesMatchingIP returns the services of a namespace with an address accepted by match
+func (c *K8sClient) servicesMatchingIP(ctx context.Context, namespace string, match ipMatcher) ([]ServiceInfo, error) {
+	svcList, err := c.listServices(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
 
-			if matched {
-				services = append(services, ServiceInfo{
-					Name:        svc.Name,
-					Namespace:   svc.Namespace,
-					ClusterIP:   svc.Spec.ClusterIP,
-					ExternalIPs: svc.Spec.ExternalIPs,
-					Type:        string(svc.Spec.Type),
-					Ports:       svc.Spec.Ports,
-					Selector:    svc.Spec.Selector,
-				})
-			}
+	services := []ServiceInfo{}
+	for _, svc := range svcList.Items {
+		if !c.matchesServiceFilters(&svc) {
+			continue
+		}
+		if matchedOn := serviceMatchedOn(&svc, match); matchedOn != "" {
+			info := newServiceInfo(&svc)
+			info.MatchedOn = matchedOn
+			services = append(services, info)
 		}
 	}
-
-	return pods, services, nil
+	return services, nil
 }
 
-// SearchByName searches for pods by name (supports partial match)
-func (c *K8sClient) SearchByName(ctx context.Context, name string) ([]PodInfo, error) {
+// SearchByIPOrName searches pods both by IP address or CIDR range, when query is one, and by name, and services
+// by IP. The pods of each namespace are listed once and matched both ways: pods matching both are reported
+// by IP, pods found by name only are marked MatchedName. HostIPOnly is ignored, as the CLI rejects --both
+// with --host-ip.
+func (c *K8sClient) SearchByIPOrName(ctx context.Context, query string) ([]PodInfo, []ServiceInfo, error) {
 	pods := []PodInfo{}
+	services := []ServiceInfo{}
+
+	// Queries that are not IPs are only searched by name
+	var match ipMatcher
+	if ValidateIP(query) || ValidateCIDR(query) {
+		match = c.Options.ipMatcher(query)
+	}
 
 	// Search in all specified namespaces
 	for _, namespace := range c.Namespaces {
-		podList, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		err := c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+			if !c.matchesPodFilters(pod) {
+				return true
+			}
+			matchedOn := ""
+			if match != nil && !c.Options.ServicesOnly {
+				matchedOn = podMatchedOn(pod, match, false)
+			}
+			var containers []string
+			if matchedOn == "" {
+				containers = c.Options.matchingContainers(pod, query)
+				if !c.Options.matchesName(pod.Name, query) && len(containers) == 0 {
+					return true
+				}
+				matchedOn = MatchedName
+			}
+			info := newPodInfo(pod)
+			info.MatchedOn = matchedOn
+			info.MatchedContainers = containers
+			pods = append(pods, info)
+			return true
+		})
 		if err != nil {
 			// Skip silently if permission denied
 			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
 				continue
 			}
-			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+			return nil, nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+
+		// Queries that are not IPs match no service
+		if match == nil {
+			continue
 		}
 
-		for _, pod := range podList.Items {
-			if strings.Contains(pod.Name, name) {
-				ownerKind, ownerName := getOwnerInfo(&pod)
-				pods = append(pods, PodInfo{
-					Name:        pod.Name,
-					Namespace:   pod.Namespace,
-					PodIP:       pod.Status.PodIP,
-					HostIP:      pod.Status.HostIP,
-					OwnerKind:   ownerKind,
-					OwnerName:   ownerName,
-					Labels:      pod.Labels,
-					Annotations: pod.Annotations,
-				})
+		matched, err := c.servicesMatchingIP(ctx, namespace, match)
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
 			}
+			return nil, nil, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
 		}
+		services = append(services, matched...)
 	}
 
-	return pods, nil
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortIPPods(pods)
+	sortServices(services)
+	pods, services = newResultLimit(c.Options.MaxResults, nil).takePodsAndServices(pods, services)
+	return pods, services, nil
 }
 
-// getOwnerInfo extracts owner information from pod
-func getOwnerInfo(pod *corev1.Pod) (string, string) {
-	if len(pod.OwnerReferences) == 0 {
-		return "", ""
-	}
+// podPageSize is the number of pods requested per list call
+var podPageSize int64 = 500
 
-	owner := pod.OwnerReferences[0]
-	return owner.Kind, owner.Name
-}
+// Addresses an IP search can match, reported in MatchedOn
+const (
+	MatchedPodIP               = "PodIP"
+	MatchedHostIP              = "HostIP"
+	MatchedClusterIP           = "ClusterIP"
+	MatchedExternalIP          = "ExternalIP"
+	MatchedLoadBalancerIngress = "LoadBalancerIngress"
+)
 
-// ValidateIP validates if a string is a valid IP address
-func ValidateIP(ip string) bool {
-	return net.ParseIP(ip) != nil
-}
+// MatchedName is reported in MatchedOn for pods a combined IP and name search found by name only
+const MatchedName = "Name"
 
-// IsPermissionError checks if an error is a permission/forbidden error (exported for use in cmdbutils)
-func IsPermissionError(err error) bool {
-	return apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)
+// ipMatcher reports whether an address matches the query of an IP search
+type ipMatcher func(address string) bool
+
+// newIPMatcher returns a matcher for addresses inside a CIDR range, or equal to an IP address otherwise.
+// IPs are normalized so equivalent representations (e.g. expanded vs compressed IPv6) match.
+func newIPMatcher(query string) ipMatcher {
+	if _, network, err := net.ParseCIDR(query); err == nil {
+		return func(address string) bool {
+			ip := net.ParseIP(address)
+			return ip != nil && network.Contains(ip)
+		}
+	}
+
+	ip := NormalizeIP(query)
+	return func(address string) bool {
+		return NormalizeIP(address) == ip
+	}
 }
 
-// isPermissionError is the internal version (kept for backward compatibility)
-func isPermissionError(err error) bool {
-	return IsPermissionError(err)
+// ipMatcher returns the matcher of an IP search: addresses containing the query with IPContains set,
+// otherwise newIPMatcher
+func (o SearchOptions) ipMatcher(query string) ipMatcher {
+	if o.IPContains {
+		return func(address string) bool {
+			return address != "" && strings.Contains(address, query)
+		}
+	}
+	return newIPMatcher(query)
 }
 
-// GetDeploymentByReplicaSet gets deployment name from ReplicaSet
-func (c *K8sClient) GetDeploymentByReplicaSet(ctx context.Context, namespace, replicaSetName string) (string, error) {
-	rs, err := c.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, replicaSetName, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("failed to get replicaset: %w", err)
+// podMatchedOn returns which address of the pod matches, or an empty string when none does.
+// Host network pods share the node's IP, so their pod IP takes precedence over the host IP
+// unless only host IPs are matched.
+func podMatchedOn(pod *corev1.Pod, match ipMatcher, hostIPOnly bool) string {
+	switch {
+	case !hostIPOnly && containsIP(getPodIPs(pod), match):
+		return MatchedPodIP
+	case containsIP(getHostIPs(pod), match):
+		return MatchedHostIP
 	}
+	return ""
+}
 
-	if len(rs.OwnerReferences) == 0 {
-		return "", fmt.Errorf("replicaset has no owner")
+// serviceMatchedOn returns which address of the service matches, or an empty string when none does
+func serviceMatchedOn(svc *corev1.Service, match ipMatcher) string {
+	// Check ClusterIPs (both families on dual-stack services)
+	if containsIP(getClusterIPs(svc), match) {
+		return MatchedClusterIP
 	}
 
-	for _, owner := range rs.OwnerReferences {
-		if owner.Kind == "Deployment" {
-			return owner.Name, nil
+	// Check ExternalIPs
+	for _, externalIP := range svc.Spec.ExternalIPs {
+		if match(externalIP) {
+			return MatchedExternalIP
 		}
 	}
 
-	return "", fmt.Errorf("no deployment found for replicaset")
+	// Check LoadBalancer IPs
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if match(ingress.IP) {
+				return MatchedLoadBalancerIngress
+			}
+		}
+	}
+	return ""
 }
 
-// SearchResultWithContext represents search results with context information
-type SearchResultWithContext struct {
-	Context   string
-	Namespace string
-	Pods      []PodInfo
-	Services  []ServiceInfo
+// forEachPod lists the pods of a namespace page by page and calls visit for each pod until it returns false.
+// Only one page is held in memory at a time, and each page request retries transient errors.
+// Namespace NamespaceAll lists the pods of all namespaces the same way.
+func (c *K8sClient) forEachPod(ctx context.Context, namespace string, visit func(pod *corev1.Pod) bool) error {
+	return c.forEachSelectedPod(ctx, namespace, c.Options.FieldSelector, visit)
 }
 
-// SearchByIPAllContexts searches for resources by IP across all contexts and all (or specified) namespaces
-func SearchByIPAllContexts(ctx context.Context, kubeconfigPath string, ip string, namespaces []string) ([]SearchResultWithContext, error) {
-	config, err := LoadKubeConfig(kubeconfigPath)
-	if err != nil {
-		return nil, err
+// forEachSelectedPod is forEachPod listing only the pods matching fieldSelector
+func (c *K8sClient) forEachSelectedPod(ctx context.Context, namespace string, fieldSelector string, visit func(pod *corev1.Pod) bool) error {
+	options := metav1.ListOptions{
+		FieldSelector: fieldSelector,
+		Limit:         podPageSize,
 	}
 
-	results := []SearchResultWithContext{}
-	contexts := GetContexts(config)
-
-	// Search in each context
-	for _, contextName := range contexts {
-		// Create client for this context
-		client, err := NewK8sClient(kubeconfigPath, contextName, []string{})
+	for {
+		var podList *corev1.PodList
+		err := c.withRetry(ctx, func() error {
+			var err error
+			podList, err = c.Clientset.CoreV1().Pods(namespace).List(ctx, options)
+			return err
+		})
 		if err != nil {
-			// Skip contexts that fail to initialize (might not have access)
-			continue
+			return err
 		}
 
-		// Determine which namespaces to search
-		var namespacesToSearch []string
-		if len(namespaces) > 0 {
-			// Use provided namespace list
-			namespacesToSearch = namespaces
-		} else {
-			// Get all namespaces in this context
-			namespaceList, err := client.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-			if err != nil {
-				// Skip if can't list namespaces
-				continue
-			}
-			for _, ns := range namespaceList.Items {
-				namespacesToSearch = append(namespacesToSearch, ns.Name)
+		for i := range podList.Items {
+			if !visit(&podList.Items[i]) {
+				return nil
 			}
 		}
 
-		// Search in each namespace
-		for _, nsName := range namespacesToSearch {
-			client.Namespaces = []string{nsName}
-			pods, services, err := client.SearchByIP(ctx, ip)
-			if err != nil {
-				// Continue even if one namespace fails
-				// Uncomment for debugging: fmt.Printf("DEBUG: Error searching namespace %s: %v\n", nsName, err)
-				continue
-			}
-
-			// Only add results if found something
-			if len(pods) > 0 || len(services) > 0 {
-				results = append(results, SearchResultWithContext{
-					Context:   contextName,
-					Namespace: nsName,
-					Pods:      pods,
-					Services:  services,
-				})
-			}
+		if podList.Continue == "" {
+			return nil
 		}
+		options.Continue = podList.Continue
 	}
-
-	return results, nil
 }
 
-// PodResultWithContext represents pod search results with context information
-type PodResultWithContext struct {
-	Context   string
-	Namespace string
-	Pods      []PodInfo
+// listServices lists the services of a namespace, retrying transient errors
+func (c *K8sClient) listServices(ctx context.Context, namespace string) (*corev1.ServiceList, error) {
+	var svcList *corev1.ServiceList
+	err := c.withRetry(ctx, func() error {
+		var err error
+		svcList, err = c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	return svcList, err
 }
 
-// SearchByNameAllContexts searches for pods by name across all contexts and all (or specified) namespaces
-func SearchByNameAllContexts(ctx context.Context, kubeconfigPath string, name string, namespaces []string) ([]PodResultWithContext, error) {
-	config, err := LoadKubeConfig(kubeconfigPath)
-	if err != nil {
-		return nil, err
+// matchesPodFilters checks a pod against the result filters in the search options
+func (c *K8sClient) matchesPodFilters(pod *corev1.Pod) bool {
+	if c.Options.Since > 0 && time.Since(pod.CreationTimestamp.Time) > c.Options.Since {
+		return false
+	}
+	if c.Options.RunningOnly && pod.Status.Phase != corev1.PodRunning {
+		return false
 	}
+	if c.Options.ServiceAccount != "" && pod.Spec.ServiceAccountName != c.Options.ServiceAccount {
+		return false
+	}
+	for _, filter := range c.Options.Annotations {
+		if !matchesAnnotation(pod.Annotations, filter) {
+			return false
+		}
+	}
+	return true
+}
 
-	results := []PodResultWithContext{}
-	contexts := GetContexts(config)
+// matchesServiceFilters checks a service against the result filters in the search options that apply to services
+func (c *K8sClient) matchesServiceFilters(svc *corev1.Service) bool {
+	return c.Options.Since <= 0 || time.Since(svc.CreationTimestamp.Time) <= c.Options.Since
+}
 
-	// Search in each context
-	for _, contextName := range contexts {
-		// Create client for this context
-		client, err := NewK8sClient(kubeconfigPath, contextName, []string{})
-		if err != nil {
-			// Skip contexts that fail to initialize
-			continue
-		}
+// NoOwnerKind is the owner kind filter value matching pods without an owner
+const NoOwnerKind = "none"
 
-		// Determine which namespaces to search
-		var namespacesToSearch []string
-		if len(namespaces) > 0 {
-			// Use provided namespace list
-			namespacesToSearch = namespaces
-		} else {
-			// Get all namespaces in this context
-			namespaceList, err := client.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-			if err != nil {
-				// Skip if can't list namespaces
+// TopOwnerKind returns the kind at the top of a pod's owner chain. ReplicaSets owned by a Deployment
+// resolve to Deployment, and pods without an owner return NoOwnerKind.
+func (c *K8sClient) TopOwnerKind(ctx context.Context, pod PodInfo) string {
+	kind, _ := c.TopOwner(ctx, pod)
+	return kind
+}
+
+// TopOwner returns the kind and name at the top of a pod's owner chain. ReplicaSets owned by a
+// Deployment resolve to that Deployment, Jobs spawned by a CronJob to that CronJob, and pods
+// without an owner return NoOwnerKind and no name.
+func (c *K8sClient) TopOwner(ctx context.Context, pod PodInfo) (string, string) {
+	switch pod.OwnerKind {
+	case "":
+		return NoOwnerKind, ""
+	case "ReplicaSet":
+		if deploymentName, err := c.GetDeploymentByReplicaSet(ctx, pod.Namespace, pod.OwnerName); err == nil {
+			return "Deployment", deploymentName
+		}
+	case "Job":
+		if cronJobName, err := c.getCronJobNameForJob(ctx, pod.Namespace, pod.OwnerName); err == nil {
+			return "CronJob", cronJobName
+		}
+	}
+	return pod.OwnerKind, pod.OwnerName
+}
+
+// filterByOwnerKind keeps the pods whose top owner kind matches one of the owner kind filters.
+// Owners are resolved after the pod filters so only matching pods cost an API call.
+func (c *K8sClient) filterByOwnerKind(ctx context.Context, pods []PodInfo) []PodInfo {
+	if len(c.Options.OwnerKinds) == 0 {
+		return pods
+	}
+
+	// Pods of the same ReplicaSet share their top owner
+	resolved := map[string]string{}
+	filtered := []PodInfo{}
+	for _, pod := range pods {
+		key := pod.Namespace + "/" + pod.OwnerKind + "/" + pod.OwnerName
+		kind, ok := resolved[key]
+		if !ok {
+			kind = c.TopOwnerKind(ctx, pod)
+			resolved[key] = kind
+		}
+
+		for _, filter := range c.Options.OwnerKinds {
+			if strings.EqualFold(strings.TrimSpace(filter), kind) {
+				filtered = append(filtered, pod)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// matchesAnnotation checks an annotation filter: "key" requires the key to be present,
+// "key=pattern" requires its value to match the glob pattern
+func matchesAnnotation(annotations map[string]string, filter string) bool {
+	key, pattern, hasValue := strings.Cut(filter, "=")
+	value, ok := annotations[key]
+	if !ok {
+		return false
+	}
+	if !hasValue {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// ListServices lists the services of a namespace
+func (c *K8sClient) ListServices(ctx context.Context, namespace string) ([]ServiceInfo, error) {
+	svcList, err := c.listServices(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
+	}
+
+	services := make([]ServiceInfo, 0, len(svcList.Items))
+	for _, svc := range svcList.Items {
+		services = append(services, newServiceInfo(&svc))
+	}
+	sortServices(services)
+	return services, nil
+}
+
+// ServicesSelectingPod returns the services in the pod's namespace whose selector matches the pod's labels.
+// Services without a selector never match, as their endpoints are managed manually.
+func ServicesSelectingPod(services []ServiceInfo, pod PodInfo) []ServiceInfo {
+	matched := []ServiceInfo{}
+	for _, svc := range services {
+		if svc.Namespace != pod.Namespace || len(svc.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Selector).Matches(labels.Set(pod.Labels)) {
+			matched = append(matched, svc)
+		}
+	}
+	return matched
+}
+
+// SearchByName searches for pods by name (partial match unless Options.ExactName is set)
+func (c *K8sClient) SearchByName(ctx context.Context, name string) ([]PodInfo, error) {
+	pods := []PodInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		err := c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+			containers := c.Options.matchingContainers(pod, name)
+			if (c.Options.matchesName(pod.Name, name) || len(containers) > 0) && c.matchesPodFilters(pod) {
+				info := newPodInfo(pod)
+				info.MatchedContainers = containers
+				pods = append(pods, info)
+			}
+			return true
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
 				continue
 			}
-			for _, ns := range namespaceList.Items {
-				namespacesToSearch = append(namespacesToSearch, ns.Name)
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+	}
+
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortPods(pods)
+	pods = pods[:newResultLimit(c.Options.MaxResults, nil).take(len(pods))]
+	return pods, nil
+}
+
+// newServiceInfo converts a service into ServiceInfo
+func newServiceInfo(svc *corev1.Service) ServiceInfo {
+	return ServiceInfo{
+		Name:         svc.Name,
+		Namespace:    svc.Namespace,
+		ClusterIP:    svc.Spec.ClusterIP,
+		ClusterIPs:   getClusterIPs(svc),
+		ExternalIPs:  svc.Spec.ExternalIPs,
+		Type:         string(svc.Spec.Type),
+		Ports:        getPorts(svc),
+		NodePorts:    getNodePorts(svc),
+		Selector:     svc.Spec.Selector,
+		ExternalName: svc.Spec.ExternalName,
+		CreatedAt:    svc.CreationTimestamp,
+		// ExternalTrafficPolicy is only set for NodePort and LoadBalancer services
+		SessionAffinity:       string(svc.Spec.SessionAffinity),
+		ExternalTrafficPolicy: string(svc.Spec.ExternalTrafficPolicy),
+		HealthCheckNodePort:   svc.Spec.HealthCheckNodePort,
+	}
+}
+
+// getPorts returns the ports of a service
+func getPorts(svc *corev1.Service) []PortInfo {
+	if len(svc.Spec.Ports) == 0 {
+		return nil
+	}
+	ports := make([]PortInfo, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, newPortInfo(port))
+	}
+	return ports
+}
+
+// getNodePorts returns the node ports allocated to a service
+func getNodePorts(svc *corev1.Service) []int32 {
+	nodePorts := []int32{}
+	for _, port := range svc.Spec.Ports {
+		if port.NodePort != 0 {
+			nodePorts = append(nodePorts, port.NodePort)
+		}
+	}
+	if len(nodePorts) == 0 {
+		return nil
+	}
+	return nodePorts
+}
+
+// newPodInfo converts a pod into PodInfo
+func newPodInfo(pod *corev1.Pod) PodInfo {
+	ownerKind, ownerName := getOwnerInfo(pod)
+	return PodInfo{
+		UID:         string(pod.UID),
+		Name:        pod.Name,
+		Namespace:   pod.Namespace,
+		PodIP:       pod.Status.PodIP,
+		PodIPs:      getPodIPs(pod),
+		HostIP:      pod.Status.HostIP,
+		HostIPs:     getHostIPs(pod),
+		OwnerKind:   ownerKind,
+		OwnerName:   ownerName,
+		Labels:      pod.Labels,
+		Annotations: pod.Annotations,
+		Containers:  getContainerInfo(pod),
+		Managers:    getManagers(pod),
+		CreatedAt:   pod.CreationTimestamp,
+		NodeName:    pod.Spec.NodeName,
+		Phase:       string(pod.Status.Phase),
+		QOSClass:    string(pod.Status.QOSClass),
+		Terminated:  isPodTerminated(pod),
+		DeletedAt:   pod.DeletionTimestamp,
+
+		ServiceAccount: pod.Spec.ServiceAccountName,
+	}
+}
+
+// isPodTerminated reports whether a pod has completed, failed or is being deleted
+func isPodTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || pod.DeletionTimestamp != nil
+}
+
+// getPodIPs returns all pod IPs (both families on dual-stack clusters), falling back to the primary PodIP
+func getPodIPs(pod *corev1.Pod) []string {
+	ips := []string{}
+	for _, podIP := range pod.Status.PodIPs {
+		ips = append(ips, podIP.IP)
+	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ips
+}
+
+// getClusterIPs returns all cluster IPs of a service (both families on dual-stack services),
+// falling back to the primary ClusterIP
+func getClusterIPs(svc *corev1.Service) []string {
+	ips := append([]string{}, svc.Spec.ClusterIPs...)
+	if len(ips) == 0 && svc.Spec.ClusterIP != "" {
+		ips = append(ips, svc.Spec.ClusterIP)
+	}
+	return ips
+}
+
+// getHostIPs returns all host IPs (both families on dual-stack clusters), falling back to the primary HostIP
+func getHostIPs(pod *corev1.Pod) []string {
+	ips := []string{}
+	for _, hostIP := range pod.Status.HostIPs {
+		ips = append(ips, hostIP.IP)
+	}
+	if len(ips) == 0 && pod.Status.HostIP != "" {
+		ips = append(ips, pod.Status.HostIP)
+	}
+	return ips
+}
+
+// containsIP reports whether any of ips matches
+func containsIP(ips []string, match ipMatcher) bool {
+	for _, candidate := range ips {
+		if match(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastAppliedManager is reported as the manager of pods without managed fields that kubectl apply
+// left its last-applied-configuration annotation on
+const LastAppliedManager = "kubectl (last-applied)"
+
+// getManagers returns the field managers of a pod's managedFields in order, without duplicates.
+// Entries for subresources are skipped: they are written by the kubelet reporting the pod status,
+// not by whoever deployed the pod. Pods without managed fields fall back to the last-applied annotation.
+func getManagers(pod *corev1.Pod) []string {
+	managers := []string{}
+	seen := map[string]bool{}
+	for _, entry := range pod.ManagedFields {
+		if entry.Subresource != "" || entry.Manager == "" || seen[entry.Manager] {
+			continue
+		}
+		seen[entry.Manager] = true
+		managers = append(managers, entry.Manager)
+	}
+	if len(managers) == 0 {
+		if _, ok := pod.Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+			managers = append(managers, LastAppliedManager)
+		}
+	}
+	return managers
+}
+
+// getContainerInfo joins the pod spec containers with their statuses
+func getContainerInfo(pod *corev1.Pod) []ContainerInfo {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	containers := make([]ContainerInfo, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		info := ContainerInfo{
+			Name:  container.Name,
+			Image: container.Image,
+		}
+		if status, ok := statuses[container.Name]; ok {
+			info.Ready = status.Ready
+			info.RestartCount = status.RestartCount
+			info.State = containerState(status.State)
+		}
+		containers = append(containers, info)
+	}
+	return containers
+}
+
+// containerState describes a container state, including the reason when waiting or terminated
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "Running"
+	case state.Waiting != nil:
+		if state.Waiting.Reason != "" {
+			return fmt.Sprintf("Waiting (%s)", state.Waiting.Reason)
+		}
+		return "Waiting"
+	case state.Terminated != nil:
+		if state.Terminated.Reason != "" {
+			return fmt.Sprintf("Terminated (%s)", state.Terminated.Reason)
+		}
+		return "Terminated"
+	}
+	return ""
+}
+
+// SearchByUID searches for a pod by UID. UIDs are unique within a cluster, so the search stops at the first match.
+func (c *K8sClient) SearchByUID(ctx context.Context, uid string) ([]PodInfo, error) {
+	pods := []PodInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		err := c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+			if string(pod.UID) == uid {
+				pods = append(pods, newPodInfo(pod))
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
 			}
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+		if len(pods) > 0 {
+			return c.filterByOwnerKind(ctx, pods), nil
 		}
+	}
 
-		// Search in each namespace
-		for _, nsName := range namespacesToSearch {
-			client.Namespaces = []string{nsName}
-			pods, err := client.SearchByName(ctx, name)
-			if err != nil {
-				// Continue even if one namespace fails
+	return pods, nil
+}
+
+// SearchByNode searches for the pods scheduled on the node nodeName, e.g. to see what draining it affects.
+// The API server selects them by spec.nodeName, on top of the FieldSelector of the search options.
+func (c *K8sClient) SearchByNode(ctx context.Context, nodeName string) ([]PodInfo, error) {
+	pods := []PodInfo{}
+
+	fieldSelector := fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	if c.Options.FieldSelector != "" {
+		fieldSelector = c.Options.FieldSelector + "," + fieldSelector
+	}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		err := c.forEachSelectedPod(ctx, namespace, fieldSelector, func(pod *corev1.Pod) bool {
+			// Checked again for API servers (and fakes) ignoring the field selector
+			if pod.Spec.NodeName == nodeName && c.matchesPodFilters(pod) {
+				pods = append(pods, newPodInfo(pod))
+			}
+			return true
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
 				continue
 			}
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+	}
+
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortPods(pods)
+	pods = pods[:newResultLimit(c.Options.MaxResults, nil).take(len(pods))]
+	return pods, nil
+}
+
+// SearchByServiceAccount searches for the pods running as the service account name, e.g. to trace the
+// workloads tied to a compromised or over-privileged one. The API server selects them by
+// spec.serviceAccountName, on top of the FieldSelector of the search options.
+func (c *K8sClient) SearchByServiceAccount(ctx context.Context, name string) ([]PodInfo, error) {
+	pods := []PodInfo{}
+
+	fieldSelector := fields.OneTermEqualSelector("spec.serviceAccountName", name).String()
+	if c.Options.FieldSelector != "" {
+		fieldSelector = c.Options.FieldSelector + "," + fieldSelector
+	}
 
-			// Only add results if found something
-			if len(pods) > 0 {
-				results = append(results, PodResultWithContext{
-					Context:   contextName,
-					Namespace: nsName,
-					Pods:      pods,
-				})
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		err := c.forEachSelectedPod(ctx, namespace, fieldSelector, func(pod *corev1.Pod) bool {
+			// Checked again for API servers (and fakes) ignoring the field selector
+			if pod.Spec.ServiceAccountName == name && c.matchesPodFilters(pod) {
+				pods = append(pods, newPodInfo(pod))
+			}
+			return true
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
 			}
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
 		}
 	}
 
-	return results, nil
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortPods(pods)
+	pods = pods[:newResultLimit(c.Options.MaxResults, nil).take(len(pods))]
+	return pods, nil
+}
+
+// SearchServicesByPort searches for services exposing a port. A numeric port matches the service port,
+// the numeric target port or the node port; a named port matches the port name or a named target port.
+func (c *K8sClient) SearchServicesByPort(ctx context.Context, port string) ([]ServiceInfo, error) {
+	services := []ServiceInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		svcList, err := c.listServices(ctx, namespace)
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
+		}
+
+		for _, svc := range svcList.Items {
+			if serviceExposesPort(&svc, port) && c.matchesServiceFilters(&svc) {
+				services = append(services, newServiceInfo(&svc))
+			}
+		}
+	}
+
+	sortServices(services)
+	_, services = newResultLimit(c.Options.MaxResults, nil).takePodsAndServices(nil, services)
+	c.resolveHeadlessEndpoints(ctx, services)
+	return services, nil
+}
+
+// SearchPodsByContainerPort searches for pods whose containers declare a port. A numeric port matches
+// the container port, a named port the port name; init containers (e.g. sidecars) are searched too.
+func (c *K8sClient) SearchPodsByContainerPort(ctx context.Context, port string) ([]PodInfo, error) {
+	pods := []PodInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		err := c.forEachPod(ctx, namespace, func(pod *corev1.Pod) bool {
+			if !c.matchesPodFilters(pod) {
+				return true
+			}
+			if ports := matchingContainerPorts(pod, port); len(ports) > 0 {
+				info := newPodInfo(pod)
+				info.MatchedPorts = ports
+				pods = append(pods, info)
+			}
+			return true
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+	}
+
+	pods = c.filterByOwnerKind(ctx, pods)
+	sortPods(pods)
+	pods = pods[:newResultLimit(c.Options.MaxResults, nil).take(len(pods))]
+	return pods, nil
+}
+
+// matchingContainerPorts returns the container ports of pod matching port, formatted as
+// "container: port/protocol (name)" with init containers prefixed by "init: "
+func matchingContainerPorts(pod *corev1.Pod, port string) []string {
+	matched := []string{}
+	match := func(prefix string, container corev1.Container) {
+		for _, containerPort := range container.Ports {
+			if strconv.Itoa(int(containerPort.ContainerPort)) != port && containerPort.Name != port {
+				continue
+			}
+			protocol := containerPort.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			entry := fmt.Sprintf("%s%s: %d/%s", prefix, container.Name, containerPort.ContainerPort, protocol)
+			if containerPort.Name != "" {
+				entry += " (" + containerPort.Name + ")"
+			}
+			matched = append(matched, entry)
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		match("init: ", container)
+	}
+	for _, container := range pod.Spec.Containers {
+		match("", container)
+	}
+	return matched
+}
+
+// resolveHeadlessEndpoints fills in the endpoint IPs of headless services, which have no cluster IP to show.
+// Services whose endpoints cannot be read are left without endpoint IPs.
+func (c *K8sClient) resolveHeadlessEndpoints(ctx context.Context, services []ServiceInfo) {
+	for i := range services {
+		if !services[i].IsHeadless() {
+			continue
+		}
+		if endpoints, err := c.GetServiceEndpoints(ctx, services[i].Namespace, services[i].Name); err == nil {
+			services[i].EndpointIPs = endpoints.Ready
+			services[i].NotReadyEndpointIPs = endpoints.NotReady
+		}
+	}
+}
+
+// serviceExposesPort checks whether any port of the service matches port
+func serviceExposesPort(svc *corev1.Service, port string) bool {
+	for _, servicePort := range svc.Spec.Ports {
+		if strconv.Itoa(int(servicePort.Port)) == port ||
+			FormatTargetPort(servicePort.TargetPort) == port ||
+			(servicePort.NodePort != 0 && strconv.Itoa(int(servicePort.NodePort)) == port) ||
+			(servicePort.Name != "" && servicePort.Name == port) {
+			return true
+		}
+	}
+	return false
+}
+
+// Default NodePort range of the API server (--service-node-port-range)
+const (
+	DefaultNodePortMin = 30000
+	DefaultNodePortMax = 32767
+)
+
+// IsNodePort reports whether port is a number within the default NodePort range
+func IsNodePort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= DefaultNodePortMin && n <= DefaultNodePortMax
+}
+
+// FormatNodePort formats the node port of a service port, or returns an empty string when none is allocated
+func FormatNodePort(port PortInfo) string {
+	if port.NodePort == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s", port.NodePort, port.Protocol)
+}
+
+// FormatTargetPort properly formats a target port, handling both integer and string (named) ports
+func FormatTargetPort(targetPort intstr.IntOrString) string {
+	if targetPort.Type == intstr.String {
+		return targetPort.StrVal
+	}
+	return fmt.Sprintf("%d", targetPort.IntVal)
+}
+
+// getOwnerInfo extracts owner information from pod
+func getOwnerInfo(pod *corev1.Pod) (string, string) {
+	if len(pod.OwnerReferences) == 0 {
+		return "", ""
+	}
+
+	owner := pod.OwnerReferences[0]
+	return owner.Kind, owner.Name
+}
+
+// ValidateIP validates if a string is a valid IP address
+func ValidateIP(ip string) bool {
+	return net.ParseIP(ip) != nil
+}
+
+// ValidateCIDR validates if a string is a CIDR range such as 10.1.2.0/24
+func ValidateCIDR(cidr string) bool {
+	_, _, err := net.ParseCIDR(cidr)
+	return err == nil
+}
+
+// ValidateIPFragment validates if a string can be part of an IP address, e.g. ".137" or "10.2.",
+// i.e. only has hex digits, dots and colons
+func ValidateIPFragment(fragment string) bool {
+	if fragment == "" {
+		return false
+	}
+	for _, r := range fragment {
+		if !strings.ContainsRune("0123456789abcdefABCDEF.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeIP returns the canonical form of an IP address so that equivalent
+// representations compare equal. Invalid addresses are returned unchanged.
+func NormalizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	return parsed.String()
+}
+
+// uidPattern matches the UUID format used for Kubernetes object UIDs
+var uidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateUID validates if a string looks like a Kubernetes object UID
+func ValidateUID(uid string) bool {
+	return uidPattern.MatchString(uid)
+}
+
+// IsPermissionError checks if an error is a permission/forbidden error (exported for use in cmdbutils)
+func IsPermissionError(err error) bool {
+	return apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)
+}
+
+// isPermissionError is the internal version (kept for backward compatibility)
+func isPermissionError(err error) bool {
+	return IsPermissionError(err)
+}
+
+// CheckConnectivity requests the server version to verify the cluster answers within timeout (0 = no check).
+// A permission error still proves the cluster is reachable.
+func (c *K8sClient) CheckConnectivity(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	restClient := c.Clientset.Discovery().RESTClient()
+	if restClient == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c.countAPICall()
+	err := restClient.Get().AbsPath("/version").Do(ctx).Error()
+	if err != nil && !isPermissionError(err) {
+		return withKind(ErrUnreachable, fmt.Errorf("cluster unreachable: %w", err))
+	}
+	return nil
+}
+
+// GetDeploymentByReplicaSet gets deployment name from ReplicaSet
+func (c *K8sClient) GetDeploymentByReplicaSet(ctx context.Context, namespace, replicaSetName string) (string, error) {
+	c.countAPICall()
+	rs, err := c.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, replicaSetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get replicaset: %w", err)
+	}
+
+	if len(rs.OwnerReferences) == 0 {
+		return "", fmt.Errorf("replicaset has no owner")
+	}
+
+	for _, owner := range rs.OwnerReferences {
+		if owner.Kind == "Deployment" {
+			return owner.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no deployment found for replicaset")
+}
+
+// GetCronJobForJob returns the name and schedule of the CronJob that spawned a Job. The schedule is
+// empty when the CronJob cannot be read, e.g. because it was deleted while its Jobs are kept.
+func (c *K8sClient) GetCronJobForJob(ctx context.Context, namespace, jobName string) (string, string, error) {
+	cronJobName, err := c.getCronJobNameForJob(ctx, namespace, jobName)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.countAPICall()
+	cronJob, err := c.Clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+	if err != nil {
+		return cronJobName, "", nil
+	}
+	return cronJobName, cronJob.Spec.Schedule, nil
+}
+
+// getCronJobNameForJob returns the name of the CronJob owning a Job
+func (c *K8sClient) getCronJobNameForJob(ctx context.Context, namespace, jobName string) (string, error) {
+	c.countAPICall()
+	job, err := c.Clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get job: %w", err)
+	}
+
+	for _, owner := range job.OwnerReferences {
+		if owner.Kind == "CronJob" {
+			return owner.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no cronjob found for job")
+}
+
+// SearchResultWithContext represents search results with context information
+type SearchResultWithContext struct {
+	Context string `json:"context"`
+	// Server is the API server URL of the context's cluster, telling apart contexts with the same name
+	Server    string        `json:"server,omitempty"`
+	Namespace string        `json:"namespace"`
+	Pods      []PodInfo     `json:"pods"`
+	Services  []ServiceInfo `json:"services"`
+}
+
+// ListNamespaces returns the names of all namespaces in the cluster, without checking access to them
+func (c *K8sClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	var namespaceList *corev1.NamespaceList
+	err := c.withRetry(ctx, func() error {
+		var err error
+		namespaceList, err = c.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	names := make([]string, 0, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
+
+// CanListAllNamespaces reports whether the credentials can list pods across all namespaces, with a one-pod list.
+// A list that is forbidden returns false without error; searches then have to find the namespaces they can read.
+func (c *K8sClient) CanListAllNamespaces(ctx context.Context) (bool, error) {
+	c.countAPICall()
+	_, err := c.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1})
+	switch {
+	case err == nil:
+		return true, nil
+	case isPermissionError(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// NamespaceAccess is whether pods can be listed in a namespace, with the error of the probe when they cannot
+type NamespaceAccess struct {
+	Namespace string
+	HasAccess bool
+	Err       error
+}
+
+// ProbeNamespaceAccess checks in which namespaces pods can be listed, probing up to concurrency
+// namespaces at once (< 1 = 1) with a one-pod list. Results are sorted by namespace; namespaces
+// left unprobed because ctx was cancelled are left out.
+func (c *K8sClient) ProbeNamespaceAccess(ctx context.Context, namespaces []string, concurrency int) []NamespaceAccess {
+	probed := make([]NamespaceAccess, len(namespaces))
+	runLimited(ctx, len(namespaces), newConcurrencyLimit(concurrency), func(i int) {
+		c.countAPICall()
+		_, err := c.Clientset.CoreV1().Pods(namespaces[i]).List(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		// Each call writes its own index, so no lock is needed
+		probed[i] = NamespaceAccess{Namespace: namespaces[i], HasAccess: err == nil, Err: err}
+	})
+
+	results := []NamespaceAccess{}
+	for _, access := range probed {
+		if access.Namespace != "" {
+			results = append(results, access)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Namespace < results[j].Namespace
+	})
+	return results
+}
+
+// ListContextNamespaces returns the sorted names of the namespaces of a context without probing access to
+// each of them, for quick lookups such as shell completion. When listing namespaces is not allowed,
+// the namespace configured for the context in kubeconfig is returned if it has one.
+func ListContextNamespaces(ctx context.Context, kubeconfigPath string, contextName string, opts SearchOptions) ([]string, error) {
+	client, err := NewK8sClientWithOptions(kubeconfigPath, contextName, []string{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := client.ListNamespaces(ctx)
+	if err != nil {
+		if namespace := ContextNamespace(client.Config, client.ContextName); namespace != "" && isPermissionError(err) {
+			return []string{namespace}, nil
+		}
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// namespaceSearchFunc searches one namespace of one context using a client scoped to that namespace.
+// Returning stop ends the search of the current context.
+type namespaceSearchFunc func(ctx context.Context, client *K8sClient, contextName string, namespace string) (stop bool, err error)
+
+// forEachNamespace runs search for every namespace of every selected context.
+// An empty namespaces list means every namespace of each context, listed without any access check.
+// Contexts and namespaces that fail are skipped so one failure doesn't abort the whole search,
+// and the search ends early when ctx is cancelled. With ContextConcurrency or NamespaceConcurrency
+// above 1, search is called concurrently and must synchronize access to shared state.
+func forEachNamespace(ctx context.Context, kubeconfigPath string, namespaces []string, contexts []string, opts SearchOptions, search namespaceSearchFunc) error {
+	return searchContexts(ctx, kubeconfigPath, namespaces, contexts, opts, false, search)
+}
+
+// forEachNamespaceOrAll is forEachNamespace for searches of pods and services, which search every namespace
+// of a context at once, calling search with namespace NamespaceAll, when namespaces is empty or discovered
+// (see SearchOptions.NamespacesDiscovered) and the context's credentials can list pods in all namespaces.
+// search then has to split its matches by namespace.
+func forEachNamespaceOrAll(ctx context.Context, kubeconfigPath string, namespaces []string, contexts []string, opts SearchOptions, search namespaceSearchFunc) error {
+	return searchContexts(ctx, kubeconfigPath, namespaces, contexts, opts, true, search)
+}
+
+// searchContexts runs search for every namespace of every selected context, see forEachNamespace,
+// or with acrossNamespaces once across the namespaces of contexts that allow it, see forEachNamespaceOrAll
+func searchContexts(ctx context.Context, kubeconfigPath string, namespaces []string, contexts []string, opts SearchOptions, acrossNamespaces bool, search namespaceSearchFunc) error {
+	config, err := LoadKubeConfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	contexts, err = SelectContexts(config, contexts)
+	if err != nil {
+		return err
+	}
+
+	if opts.ContextFromNamespace && len(namespaces) > 0 {
+		contexts = ContextsWithNamespaces(ctx, kubeconfigPath, contexts, namespaces, opts)
+	}
+
+	opts = opts.serializeCallbacks()
+
+	// A cancelled search (e.g. once the result cap is reached) skips the remaining contexts
+	runLimited(ctx, len(contexts), newConcurrencyLimit(opts.ContextConcurrency), func(contextIndex int) {
+		searchContext(ctx, kubeconfigPath, contexts[contextIndex], contextIndex, len(contexts), namespaces, opts, acrossNamespaces, search)
+	})
+
+	opts.reportProgress(SearchProgress{Contexts: len(contexts), ContextIndex: len(contexts), Done: true})
+	return nil
+}
+
+// searchContext runs search for every namespace of one context, see searchContexts
+func searchContext(parent context.Context, kubeconfigPath string, contextName string, contextIndex int, contexts int, namespaces []string, opts SearchOptions, acrossNamespaces bool, search namespaceSearchFunc) {
+	started := time.Now()
+
+	// A deadline of its own keeps one slow cluster from using up the time of the whole search
+	ctx := parent
+	if opts.ContextTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, opts.ContextTimeout)
+		defer cancel()
+	}
+	// timedOut reports whether the context's own deadline, rather than the whole search, ended it
+	timedOut := func() bool {
+		return parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+	}
+	skipTimedOut := func(timing ContextTiming) {
+		if opts.OnContextSkipped != nil {
+			opts.OnContextSkipped(contextName, fmt.Errorf("search timed out after %s", opts.ContextTimeout))
+		}
+		timing.TimedOut = true
+		opts.reportContextSearched(timing)
+	}
+
+	// Create client for this context
+	client, err := NewK8sClientWithOptions(kubeconfigPath, contextName, []string{}, opts)
+	if err != nil {
+		// Skip contexts that fail to initialize, e.g. because their credential plugin failed
+		if opts.OnContextSkipped != nil {
+			opts.OnContextSkipped(contextName, err)
+		}
+		opts.reportContextSearched(ContextTiming{Context: contextName, Duration: time.Since(started), Skipped: true})
+		return
+	}
+
+	// Skip unreachable clusters before the expensive namespace enumeration
+	if err := client.CheckConnectivity(ctx, opts.PrecheckTimeout); err != nil {
+		if timedOut() {
+			skipTimedOut(ContextTiming{Context: contextName, Duration: time.Since(started), APICalls: client.APICalls()})
+			return
+		}
+		if opts.OnContextSkipped != nil {
+			opts.OnContextSkipped(contextName, err)
+		}
+		opts.reportContextSearched(ContextTiming{Context: contextName, Duration: time.Since(started), APICalls: client.APICalls(), Skipped: true})
+		return
+	}
+
+	// Determine which namespaces to search
+	namespacesToSearch := namespaces
+	if acrossNamespaces && (len(namespacesToSearch) == 0 || opts.NamespacesDiscovered) {
+		// Each cluster decides for itself, as contexts of one kubeconfig often hold different rights.
+		// A failed check searches namespace by namespace, whose listing reports the failure.
+		if clusterWide, _ := client.CanListAllNamespaces(ctx); clusterWide {
+			namespacesToSearch = []string{metav1.NamespaceAll}
+		}
+	}
+	if len(namespacesToSearch) == 0 {
+		// Get all namespaces in this context
+		namespacesToSearch, err = client.ListNamespaces(ctx)
+		if err != nil {
+			if timedOut() {
+				skipTimedOut(ContextTiming{Context: contextName, Duration: time.Since(started), APICalls: client.APICalls()})
+				return
+			}
+			// Skip if can't list namespaces
+			if opts.OnSearchFailed != nil && ctx.Err() == nil {
+				opts.OnSearchFailed(contextName, "", err)
+			}
+			opts.reportContextSearched(ContextTiming{Context: contextName, Duration: time.Since(started), APICalls: client.APICalls()})
+			return
+		}
+	}
+
+	// Search in each namespace until one asks to stop, with fewer namespaces at once while the API server throttles
+	var scanned int64
+	var stopped atomic.Bool
+	client.namespaceLimit = newConcurrencyLimit(opts.NamespaceConcurrency)
+	runLimited(ctx, len(namespacesToSearch), client.namespaceLimit, func(namespaceIndex int) {
+		if stopped.Load() {
+			return
+		}
+		namespace := namespacesToSearch[namespaceIndex]
+		opts.reportProgress(SearchProgress{
+			Context:        contextName,
+			ContextIndex:   contextIndex + 1,
+			Contexts:       contexts,
+			NamespaceIndex: namespaceIndex + 1,
+			Namespaces:     len(namespacesToSearch),
+		})
+
+		atomic.AddInt64(&scanned, 1)
+		stop, err := search(ctx, client.forNamespace(namespace), contextName, namespace)
+		// Continue even if one namespace fails, reporting those still throttled after retrying apart from
+		// other failures. Failures of a cancelled or timed out search are expected and not reported.
+		switch {
+		case err == nil || ctx.Err() != nil:
+		case apierrors.IsTooManyRequests(err):
+			if opts.OnNamespaceSkipped != nil {
+				opts.OnNamespaceSkipped(contextName, namespace, err)
+			}
+		case opts.OnSearchFailed != nil:
+			opts.OnSearchFailed(contextName, namespace, err)
+		}
+		if err == nil && stop {
+			stopped.Store(true)
+		}
+	})
+
+	getMetrics().AddNamespacesScanned(contextName, int(scanned))
+	getMetrics().ObserveContextSearch(contextName, time.Since(started))
+	timing := ContextTiming{Context: contextName, Duration: time.Since(started), Namespaces: int(scanned), APICalls: client.APICalls()}
+	// Matches found before the deadline are kept
+	if timedOut() {
+		skipTimedOut(timing)
+		return
+	}
+	opts.reportContextSearched(timing)
+}
+
+// resultLimit caps the number of matches a search collects. It is safe for concurrent use.
+type resultLimit struct {
+	mu sync.Mutex
+	// remaining is the number of matches still accepted (negative = no cap)
+	remaining int
+	// full is called once the cap is reached (may be nil)
+	full func()
+}
+
+// newResultLimit returns a limit accepting max matches (0 = no cap), calling full once it is reached
+func newResultLimit(max int, full func()) *resultLimit {
+	if max <= 0 {
+		return &resultLimit{remaining: -1}
+	}
+	return &resultLimit{remaining: max, full: full}
+}
+
+// take returns how many of n new matches fit under the cap
+func (l *resultLimit) take(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.remaining < 0 {
+		return n
+	}
+	if n > l.remaining {
+		n = l.remaining
+	}
+	l.remaining -= n
+	if l.remaining == 0 && l.full != nil {
+		l.full()
+	}
+	return n
+}
+
+// takePodsAndServices trims pods and services to the matches that fit under the cap, pods first
+func (l *resultLimit) takePodsAndServices(pods []PodInfo, services []ServiceInfo) ([]PodInfo, []ServiceInfo) {
+	keep := l.take(len(pods) + len(services))
+	if keep < len(pods) {
+		return pods[:keep], services[:0]
+	}
+	return pods, services[:keep-len(pods)]
+}
+
+// ContextPlan lists the namespaces a search would scan in one context
+type ContextPlan struct {
+	Context    string   `json:"context"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// PlanSearch returns the contexts and namespaces an all-contexts search would scan, without listing any pods or services.
+// It walks the same context selection and namespace discovery as the real search.
+func PlanSearch(ctx context.Context, kubeconfigPath string, namespaces []string, contexts []string, opts SearchOptions) ([]ContextPlan, error) {
+	plans := []ContextPlan{}
+	// Plans are built in search order, one context after the other
+	opts.ContextConcurrency, opts.NamespaceConcurrency = 1, 1
+
+	err := forEachNamespace(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		if len(plans) == 0 || plans[len(plans)-1].Context != contextName {
+			plans = append(plans, ContextPlan{Context: contextName, Namespaces: []string{}})
+		}
+		plan := &plans[len(plans)-1]
+		plan.Namespaces = append(plan.Namespaces, namespace)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// SearchByIPAllContexts searches for resources by IP across all (or specified) contexts and all (or specified) namespaces
+func SearchByIPAllContexts(ctx context.Context, kubeconfigPath string, ip string, namespaces []string, contexts []string, opts SearchOptions) ([]SearchResultWithContext, error) {
+	collected, ctx, cancel := newCollector[SearchResultWithContext](ctx, opts.MaxResults, opts.OnIPResult)
+	defer cancel()
+
+	err := forEachNamespaceOrAll(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		pods, services, err := client.SearchByIP(ctx, ip)
+		if err != nil {
+			return false, err
+		}
+		getMetrics().AddMatches(contextName, len(pods), len(services))
+		pods, services = collected.limit.takePodsAndServices(pods, services)
+
+		// Only add results if found something
+		if len(pods) > 0 || len(services) > 0 {
+			result := SearchResultWithContext{
+				Context:   contextName,
+				Server:    client.Server(),
+				Namespace: namespace,
+				Pods:      pods,
+				Services:  services,
+			}
+			for _, result := range splitIPResult(result) {
+				collected.add(result)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	SortIPResults(results)
+	return results, nil
+}
+
+// SearchByIPOrNameAllContexts searches for query both as an IP address or CIDR range (when it is one) and as a pod name,
+// in one pass over all (or specified) contexts and all (or specified) namespaces, see SearchByIPOrName.
+func SearchByIPOrNameAllContexts(ctx context.Context, kubeconfigPath string, query string, namespaces []string, contexts []string, opts SearchOptions) ([]SearchResultWithContext, error) {
+	collected, ctx, cancel := newCollector[SearchResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+
+	err := forEachNamespaceOrAll(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		pods, services, err := client.SearchByIPOrName(ctx, query)
+		if err != nil {
+			return false, err
+		}
+		getMetrics().AddMatches(contextName, len(pods), len(services))
+		pods, services = collected.limit.takePodsAndServices(pods, services)
+
+		// Only add results if found something
+		if len(pods) == 0 && len(services) == 0 {
+			return false, nil
+		}
+		result := SearchResultWithContext{
+			Context:   contextName,
+			Server:    client.Server(),
+			Namespace: namespace,
+			Pods:      pods,
+			Services:  services,
+		}
+		for _, result := range splitIPResult(result) {
+			collected.add(result)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	SortIPResults(results)
+	return results, nil
+}
+
+// hasNodePort reports whether port is one of the node ports of svc
+func hasNodePort(svc ServiceInfo, port string) bool {
+	for _, nodePort := range svc.NodePorts {
+		if strconv.Itoa(int(nodePort)) == port {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchByPortAllContexts searches for services exposing a port across all (or specified) contexts and all (or specified) namespaces,
+// and with ContainerPorts for pods whose containers declare it. The search of a context stops at the service owning the port as a node port.
+func SearchByPortAllContexts(ctx context.Context, kubeconfigPath string, port string, namespaces []string, contexts []string, opts SearchOptions) ([]SearchResultWithContext, error) {
+	collected, ctx, cancel := newCollector[SearchResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+
+	err := forEachNamespaceOrAll(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		services, err := client.SearchServicesByPort(ctx, port)
+		if err != nil {
+			return false, err
+		}
+		pods := []PodInfo{}
+		if opts.ContainerPorts {
+			if pods, err = client.SearchPodsByContainerPort(ctx, port); err != nil {
+				return false, err
+			}
+		}
+		getMetrics().AddMatches(contextName, len(pods), len(services))
+		pods, services = collected.limit.takePodsAndServices(pods, services)
+
+		// Only add results if found something
+		if len(pods) == 0 && len(services) == 0 {
+			return false, nil
+		}
+		result := SearchResultWithContext{
+			Context:   contextName,
+			Server:    client.Server(),
+			Namespace: namespace,
+			Pods:      pods,
+			Services:  services,
+		}
+		for _, result := range splitIPResult(result) {
+			collected.add(result)
+		}
+
+		// Node ports are unique within a cluster, so the owning service ends the search of this context
+		for _, svc := range services {
+			if hasNodePort(svc, port) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	SortIPResults(results)
+	return results, nil
+}
+
+// PodResultWithContext represents pod search results with context information
+type PodResultWithContext struct {
+	Context   string    `json:"context"`
+	Server    string    `json:"server,omitempty"`
+	Namespace string    `json:"namespace"`
+	Pods      []PodInfo `json:"pods"`
+}
+
+// SearchByNameAllContexts searches for pods by name across all (or specified) contexts and all (or specified) namespaces
+func SearchByNameAllContexts(ctx context.Context, kubeconfigPath string, name string, namespaces []string, contexts []string, opts SearchOptions) ([]PodResultWithContext, error) {
+	return searchPodsAllContexts(ctx, kubeconfigPath, namespaces, contexts, opts, false, func(ctx context.Context, client *K8sClient) ([]PodInfo, error) {
+		return client.SearchByName(ctx, name)
+	})
+}
+
+// SearchByUIDAllContexts searches for a pod by UID across all (or specified) contexts and all (or specified) namespaces.
+// UIDs are unique within a cluster, so the search of a context stops at its first match.
+func SearchByUIDAllContexts(ctx context.Context, kubeconfigPath string, uid string, namespaces []string, contexts []string, opts SearchOptions) ([]PodResultWithContext, error) {
+	return searchPodsAllContexts(ctx, kubeconfigPath, namespaces, contexts, opts, true, func(ctx context.Context, client *K8sClient) ([]PodInfo, error) {
+		return client.SearchByUID(ctx, uid)
+	})
+}
+
+// SearchByNodeAllContexts searches for the pods scheduled on a node across all (or specified) contexts
+// and all (or specified) namespaces
+func SearchByNodeAllContexts(ctx context.Context, kubeconfigPath string, nodeName string, namespaces []string, contexts []string, opts SearchOptions) ([]PodResultWithContext, error) {
+	return searchPodsAllContexts(ctx, kubeconfigPath, namespaces, contexts, opts, false, func(ctx context.Context, client *K8sClient) ([]PodInfo, error) {
+		return client.SearchByNode(ctx, nodeName)
+	})
+}
+
+// SearchByServiceAccountAllContexts searches for the pods running as a service account across all
+// (or specified) contexts and all (or specified) namespaces
+func SearchByServiceAccountAllContexts(ctx context.Context, kubeconfigPath string, name string, namespaces []string, contexts []string, opts SearchOptions) ([]PodResultWithContext, error) {
+	return searchPodsAllContexts(ctx, kubeconfigPath, namespaces, contexts, opts, false, func(ctx context.Context, client *K8sClient) ([]PodInfo, error) {
+		return client.SearchByServiceAccount(ctx, name)
+	})
+}
+
+// searchPodsAllContexts runs a pod search in every namespace of every selected context,
+// optionally stopping the search of a context at its first match
+func searchPodsAllContexts(ctx context.Context, kubeconfigPath string, namespaces []string, contexts []string, opts SearchOptions, stopAtFirstMatch bool, search func(ctx context.Context, client *K8sClient) ([]PodInfo, error)) ([]PodResultWithContext, error) {
+	collected, ctx, cancel := newCollector[PodResultWithContext](ctx, opts.MaxResults, opts.OnPodResult)
+	defer cancel()
+
+	err := forEachNamespaceOrAll(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		pods, err := search(ctx, client)
+		if err != nil {
+			return false, err
+		}
+		getMetrics().AddMatches(contextName, len(pods), 0)
+		pods = pods[:collected.limit.take(len(pods))]
+
+		// Only add results if found something
+		if len(pods) == 0 {
+			return false, nil
+		}
+		result := PodResultWithContext{
+			Context:   contextName,
+			Server:    client.Server(),
+			Namespace: namespace,
+			Pods:      pods,
+		}
+		for _, result := range splitPodResult(result) {
+			collected.add(result)
+		}
+		return stopAtFirstMatch, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	SortPodResults(results)
+	return results, nil
+}
+
+// SortIPResults sorts results by context then namespace, the pods of each result like sortIPPods
+// and the services by name
+func SortIPResults(results []SearchResultWithContext) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	for _, result := range results {
+		sortIPPods(result.Pods)
+		sortServices(result.Services)
+	}
+}
+
+// SortPodResults sorts results by context then namespace, and the pods of each result by name
+func SortPodResults(results []PodResultWithContext) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	for _, result := range results {
+		sortPods(result.Pods)
+	}
+}
+
+// sortPods sorts pods by namespace then name
+func sortPods(pods []PodInfo) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
+
+// sortIPPods sorts pods matching an IP by namespace, then Running pods first and terminated ones
+// last, then by name: when an IP was reused, the pod holding it now comes before the one that held it
+func sortIPPods(pods []PodInfo) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		if rankI, rankJ := podLivenessRank(pods[i]), podLivenessRank(pods[j]); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
+
+// podLivenessRank orders Running pods before other live pods, and those before terminated pods
+func podLivenessRank(pod PodInfo) int {
+	switch {
+	case pod.Terminated:
+		return 2
+	case pod.Phase == string(corev1.PodRunning):
+		return 0
+	}
+	return 1
+}
+
+// sortServices sorts services by namespace then name
+func sortServices(services []ServiceInfo) {
+	sort.SliceStable(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+}
+
+// clusterServer returns the API server URL of the cluster a context points at
+func clusterServer(config *api.Config, contextName string) string {
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return ""
+	}
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return ""
+	}
+	return cluster.Server
+}
+
+// ResultDeduper removes pods and services already reported by an earlier context pointing at the same
+// cluster from results handed to it one at a time, e.g. as a search streams them. Pods are keyed by
+// cluster server URL, namespace and UID, services by cluster server URL, namespace and name. Results
+// without a server, whose cluster is unknown, are kept as they are.
+type ResultDeduper struct {
+	seen map[string]bool
+}
+
+// NewResultDeduper creates a deduper telling clusters apart by the server of each result
+func NewResultDeduper() *ResultDeduper {
+	return &ResultDeduper{seen: map[string]bool{}}
+}
+
+// IPResult returns result without the pods and services already seen, and false when none are left
+func (d *ResultDeduper) IPResult(result SearchResultWithContext) (SearchResultWithContext, bool) {
+	if result.Server == "" {
+		return result, len(result.Pods) > 0 || len(result.Services) > 0
+	}
+	result.Pods = d.pods(result.Server, result.Pods)
+
+	services := []ServiceInfo{}
+	for _, svc := range result.Services {
+		key := fmt.Sprintf("svc/%s/%s/%s", result.Server, svc.Namespace, svc.Name)
+		if d.seen[key] {
+			continue
+		}
+		d.seen[key] = true
+		services = append(services, svc)
+	}
+	result.Services = services
+
+	return result, len(result.Pods) > 0 || len(result.Services) > 0
+}
+
+// PodResult returns result without the pods already seen, and false when none are left
+func (d *ResultDeduper) PodResult(result PodResultWithContext) (PodResultWithContext, bool) {
+	if result.Server == "" {
+		return result, len(result.Pods) > 0
+	}
+	result.Pods = d.pods(result.Server, result.Pods)
+	return result, len(result.Pods) > 0
+}
+
+// pods returns the pods not seen yet in the cluster at server, marking them seen
+func (d *ResultDeduper) pods(server string, pods []PodInfo) []PodInfo {
+	unseen := []PodInfo{}
+	for _, pod := range pods {
+		key := podDedupKey(server, pod)
+		if d.seen[key] {
+			continue
+		}
+		d.seen[key] = true
+		unseen = append(unseen, pod)
+	}
+	return unseen
+}
+
+// DedupIPResults removes pods and services already reported by an earlier context
+// pointing at the same cluster, see ResultDeduper
+func DedupIPResults(results []SearchResultWithContext) []SearchResultWithContext {
+	deduper := NewResultDeduper()
+	deduped := []SearchResultWithContext{}
+	for _, result := range results {
+		if result, ok := deduper.IPResult(result); ok {
+			deduped = append(deduped, result)
+		}
+	}
+	return deduped
+}
+
+// DedupPodResults removes pods already reported by an earlier context pointing at the same cluster
+func DedupPodResults(results []PodResultWithContext) []PodResultWithContext {
+	deduper := NewResultDeduper()
+	deduped := []PodResultWithContext{}
+	for _, result := range results {
+		if result, ok := deduper.PodResult(result); ok {
+			deduped = append(deduped, result)
+		}
+	}
+	return deduped
+}
+
+// podDedupKey builds the key identifying a pod within a cluster
+func podDedupKey(server string, pod PodInfo) string {
+	// Fall back to the name when the UID is unknown
+	id := pod.UID
+	if id == "" {
+		id = pod.Name
+	}
+	return fmt.Sprintf("pod/%s/%s/%s", server, pod.Namespace, id)
 }
@@ -2,15 +2,27 @@ package pkg
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -63,10 +75,7 @@ func TestGetContexts(t *testing.T) {
 	}
 
 	contexts := GetContexts(config)
-	assert.Len(t, contexts, 3)
-	assert.Contains(t, contexts, "context1")
-	assert.Contains(t, contexts, "context2")
-	assert.Contains(t, contexts, "context3")
+	assert.Equal(t, []string{"context1", "context2", "context3"}, contexts)
 
 	// Test empty contexts
 	emptyConfig := &api.Config{
@@ -76,6 +85,66 @@ func TestGetContexts(t *testing.T) {
 	assert.Len(t, emptyContexts, 0)
 }
 
+// TestGetContextDetails tests reading the cluster, user, namespace and server of every context
+func TestGetContextDetails(t *testing.T) {
+	config := &api.Config{
+		Clusters: map[string]*api.Cluster{
+			"prod-cluster": {Server: "https://prod.example.com:6443"},
+		},
+		Contexts: map[string]*api.Context{
+			"prod":    {Cluster: "prod-cluster", AuthInfo: "admin", Namespace: "web"},
+			"staging": {Cluster: "missing-cluster", AuthInfo: "dev"},
+		},
+	}
+
+	assert.Equal(t, []ContextDetail{
+		{Name: "prod", Cluster: "prod-cluster", User: "admin", Namespace: "web", Server: "https://prod.example.com:6443"},
+		{Name: "staging", Cluster: "missing-cluster", User: "dev"},
+	}, GetContextDetails(config))
+
+	assert.Empty(t, GetContextDetails(&api.Config{}))
+}
+
+// TestContextNamespace tests reading the namespace configured for a context
+func TestContextNamespace(t *testing.T) {
+	config := &api.Config{
+		CurrentContext: "prod",
+		Contexts: map[string]*api.Context{
+			"prod":    {Namespace: "web"},
+			"staging": {},
+		},
+	}
+
+	assert.Equal(t, "web", ContextNamespace(config, "prod"))
+	assert.Equal(t, "web", ContextNamespace(config, ""))
+	assert.Equal(t, "", ContextNamespace(config, "staging"))
+	assert.Equal(t, "", ContextNamespace(config, "missing"))
+}
+
+// TestSelectContexts tests restricting the searched contexts
+func TestSelectContexts(t *testing.T) {
+	config := &api.Config{
+		Contexts: map[string]*api.Context{
+			"prod":    {},
+			"staging": {},
+		},
+	}
+
+	// No requested contexts means all contexts
+	contexts, err := SelectContexts(config, nil)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"prod", "staging"}, contexts)
+
+	// Requested contexts restrict the search
+	contexts, err = SelectContexts(config, []string{"prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod"}, contexts)
+
+	// Unknown contexts are rejected
+	_, err = SelectContexts(config, []string{"missing"})
+	assert.Error(t, err)
+}
+
 // TestValidateIP tests IP validation
 func TestValidateIP(t *testing.T) {
 	tests := []struct {
@@ -173,8 +242,16 @@ func TestSearchByIP(t *testing.T) {
 	assert.Equal(t, "default", pods[0].Namespace)
 	assert.Equal(t, "ReplicaSet", pods[0].OwnerKind)
 	assert.Equal(t, "test-rs-1", pods[0].OwnerName)
+	assert.Equal(t, MatchedPodIP, pods[0].MatchedOn)
 	assert.Len(t, services, 0)
 
+	// Test searching by host IP
+	pods, _, err = client.SearchByIP(ctx, "192.168.1.2")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "test-pod-2", pods[0].Name)
+	assert.Equal(t, MatchedHostIP, pods[0].MatchedOn)
+
 	// Test searching by service ClusterIP
 	pods, services, err = client.SearchByIP(ctx, "10.96.0.1")
 	assert.NoError(t, err)
@@ -183,6 +260,7 @@ func TestSearchByIP(t *testing.T) {
 	assert.Equal(t, "test-service", services[0].Name)
 	assert.Equal(t, "default", services[0].Namespace)
 	assert.Equal(t, "10.96.0.1", services[0].ClusterIP)
+	assert.Equal(t, MatchedClusterIP, services[0].MatchedOn)
 
 	// Test searching by non-existent IP
 	pods, services, err = client.SearchByIP(ctx, "10.0.0.99")
@@ -271,6 +349,72 @@ func TestSearchByName(t *testing.T) {
 	pods, err = client.SearchByName(ctx, "nonexistent")
 	assert.NoError(t, err)
 	assert.Len(t, pods, 0)
+
+	// Test exact matching, which ignores names merely containing the query
+	client.Options.ExactName = true
+	pods, err = client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+
+	pods, err = client.SearchByName(ctx, "nginx-deployment-def456")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "test-ns", pods[0].Namespace)
+}
+
+// TestSearchByNameMatchContainers tests matching init and ephemeral container names, reporting the matched containers
+func TestSearchByNameMatchContainers(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "debug-sidecar"}},
+				EphemeralContainers: []corev1.EphemeralContainer{
+					{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger-x7k2"}},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "wait-for-db"}, {Name: "debug-config"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debug-tools", Namespace: "default"},
+		},
+	)
+	client := &K8sClient{Clientset: fakeClient, Namespaces: []string{"default"}}
+
+	// Without --match-containers only pod names match
+	pods, err := client.SearchByName(ctx, "debug")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "debug-tools", pods[0].Name)
+	assert.Empty(t, pods[0].MatchedContainers)
+
+	// App containers are not matched, init and ephemeral containers are
+	client.Options.MatchContainers = true
+	pods, err = client.SearchByName(ctx, "debug")
+	require.NoError(t, err)
+	require.Len(t, pods, 3)
+	matched := map[string][]string{}
+	for _, pod := range pods {
+		matched[pod.Name] = pod.MatchedContainers
+	}
+	assert.Equal(t, map[string][]string{
+		"debug-tools": nil,
+		"web-1":       {"ephemeral: debugger-x7k2"},
+		"web-2":       {"init: debug-config"},
+	}, matched)
+
+	// Exact matching applies to container names too
+	client.Options.ExactName = true
+	pods, err = client.SearchByName(ctx, "wait-for-db")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, []string{"init: wait-for-db"}, pods[0].MatchedContainers)
 }
 
 // TestGetOwnerInfo tests extracting owner information from pod
@@ -328,6 +472,47 @@ func TestGetOwnerInfo(t *testing.T) {
 	assert.Equal(t, "test-rs-1", name)
 }
 
+// TestSearchByIPTerminatedPods tests that live pods holding a reused IP come before terminated ones
+func TestSearchByIPTerminatedPods(t *testing.T) {
+	deletedAt := metav1.Now()
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-completed", Namespace: "default", UID: "uid-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded, PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-deleting", Namespace: "default", UID: "uid-2", DeletionTimestamp: &deletedAt, Finalizers: []string{"example.com/cleanup"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "c-pending", Namespace: "default", UID: "uid-3"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending, PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "d-running", Namespace: "default", UID: "uid-4"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+		},
+	)
+
+	client := &K8sClient{Clientset: fakeClient, Namespaces: []string{"default"}}
+
+	pods, _, err := client.SearchByIP(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, pods, 4)
+
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	assert.Equal(t, []string{"d-running", "c-pending", "a-completed", "b-deleting"}, names)
+	assert.False(t, pods[0].Terminated)
+	assert.False(t, pods[1].Terminated)
+	assert.True(t, pods[2].Terminated)
+	assert.Nil(t, pods[2].DeletedAt)
+	assert.True(t, pods[3].Terminated)
+	require.NotNil(t, pods[3].DeletedAt)
+}
+
 // TestSearchByIPWithLoadBalancer tests searching LoadBalancer services
 func TestSearchByIPWithLoadBalancer(t *testing.T) {
 	// Create fake clientset
@@ -352,6 +537,8 @@ func TestSearchByIPWithLoadBalancer(t *testing.T) {
 			ExternalIPs: []string{
 				"203.0.113.1",
 			},
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			HealthCheckNodePort:   32456,
 		},
 		Status: corev1.ServiceStatus{
 			LoadBalancer: corev1.LoadBalancerStatus{
@@ -374,6 +561,9 @@ func TestSearchByIPWithLoadBalancer(t *testing.T) {
 	assert.Len(t, services, 1)
 	assert.Equal(t, "lb-service", services[0].Name)
 	assert.Equal(t, "LoadBalancer", services[0].Type)
+	assert.Equal(t, MatchedLoadBalancerIngress, services[0].MatchedOn)
+	assert.Equal(t, "Local", services[0].ExternalTrafficPolicy)
+	assert.Equal(t, int32(32456), services[0].HealthCheckNodePort)
 
 	// Test searching by ExternalIP
 	pods, services, err = client.SearchByIP(ctx, "203.0.113.1")
@@ -381,6 +571,7 @@ func TestSearchByIPWithLoadBalancer(t *testing.T) {
 	assert.Len(t, pods, 0)
 	assert.Len(t, services, 1)
 	assert.Equal(t, "lb-service", services[0].Name)
+	assert.Equal(t, MatchedExternalIP, services[0].MatchedOn)
 }
 
 // TestSearchByIPAllContexts tests searching across all contexts and namespaces
@@ -422,7 +613,7 @@ users:
 	// Note: This test will try to connect to real API servers, which will fail
 	// In a real test environment, you would need to mock the entire kubeconfig system
 	// For now, we just test that the function doesn't panic and handles errors gracefully
-	results, err := SearchByIPAllContexts(ctx, kubeconfigPath, "10.0.0.1", []string{})
+	results, err := SearchByIPAllContexts(ctx, kubeconfigPath, "10.0.0.1", []string{}, nil, SearchOptions{})
 
 	// Since we can't connect to the test clusters, we expect either an error or empty results
 	// The important thing is that the function doesn't panic
@@ -463,7 +654,7 @@ users:
 	// Note: This test will try to connect to real API servers, which will fail
 	// In a real test environment, you would need to mock the entire kubeconfig system
 	// For now, we just test that the function doesn't panic and handles errors gracefully
-	results, err := SearchByNameAllContexts(ctx, kubeconfigPath, "nginx", []string{})
+	results, err := SearchByNameAllContexts(ctx, kubeconfigPath, "nginx", []string{}, nil, SearchOptions{})
 
 	// Since we can't connect to the test clusters, we expect either an error or empty results
 	// The important thing is that the function doesn't panic
@@ -471,3 +662,1564 @@ users:
 		assert.NotNil(t, results)
 	}
 }
+
+// TestDedupResults tests removing pods and services reported by contexts sharing a cluster
+func TestDedupResults(t *testing.T) {
+
+	pod := PodInfo{UID: "uid-1", Name: "web-1", Namespace: "default"}
+	svc := ServiceInfo{Name: "web", Namespace: "default"}
+
+	results := []SearchResultWithContext{
+		{Context: "a-admin", Server: "https://a:6443", Namespace: "default", Pods: []PodInfo{pod}, Services: []ServiceInfo{svc}},
+		{Context: "a-viewer", Server: "https://a:6443", Namespace: "default", Pods: []PodInfo{pod}, Services: []ServiceInfo{svc}},
+		{Context: "b-admin", Server: "https://b:6443", Namespace: "default", Pods: []PodInfo{pod}, Services: []ServiceInfo{svc}},
+		// Contexts without a known server could be any cluster, so none of their results are dropped
+		{Context: "unknown-1", Namespace: "default", Pods: []PodInfo{pod}, Services: []ServiceInfo{svc}},
+		{Context: "unknown-2", Namespace: "default", Pods: []PodInfo{pod}, Services: []ServiceInfo{svc}},
+	}
+
+	deduped := DedupIPResults(results)
+	require.Len(t, deduped, 4)
+	assert.Equal(t, "a-admin", deduped[0].Context)
+	assert.Equal(t, "b-admin", deduped[1].Context)
+	assert.Equal(t, []ServiceInfo{svc}, deduped[3].Services)
+
+	podResults := []PodResultWithContext{
+		{Context: "a-admin", Server: "https://a:6443", Namespace: "default", Pods: []PodInfo{pod}},
+		{Context: "a-viewer", Server: "https://a:6443", Namespace: "default", Pods: []PodInfo{pod, {UID: "uid-2", Name: "web-2", Namespace: "default"}}},
+	}
+
+	dedupedPods := DedupPodResults(podResults)
+	assert.Len(t, dedupedPods, 2)
+	assert.Len(t, dedupedPods[1].Pods, 1)
+	assert.Equal(t, "web-2", dedupedPods[1].Pods[0].Name)
+}
+
+// TestGetManagers tests listing the field managers of a pod, skipping status updates
+func TestGetManagers(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+		{Manager: "argocd-controller", Operation: metav1.ManagedFieldsOperationApply},
+		{Manager: "kubelet", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status"},
+		{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate},
+		{Manager: "argocd-controller", Operation: metav1.ManagedFieldsOperationUpdate},
+	}}}
+	assert.Equal(t, []string{"argocd-controller", "kubectl-edit"}, getManagers(pod))
+	assert.Equal(t, []string{"argocd-controller", "kubectl-edit"}, newPodInfo(pod).Managers)
+
+	// Without managed fields kubectl apply is still told by its annotation
+	pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}"}}}
+	assert.Equal(t, []string{LastAppliedManager}, getManagers(pod))
+	assert.Empty(t, getManagers(&corev1.Pod{}))
+}
+
+// TestGetContainerInfo tests joining container specs with their statuses
+func TestGetContainerInfo(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Image: "app:1.0"},
+				{Name: "sidecar", Image: "proxy:2.0"},
+				{Name: "pending", Image: "pending:1.0"},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					Ready:        true,
+					RestartCount: 1,
+					State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+				{
+					Name:         "sidecar",
+					RestartCount: 12,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+				},
+			},
+		},
+	}
+
+	containers := getContainerInfo(pod)
+	require.Len(t, containers, 3)
+
+	assert.Equal(t, ContainerInfo{Name: "app", Image: "app:1.0", Ready: true, RestartCount: 1, State: "Running"}, containers[0])
+	assert.Equal(t, ContainerInfo{Name: "sidecar", Image: "proxy:2.0", RestartCount: 12, State: "Waiting (CrashLoopBackOff)"}, containers[1])
+	assert.Equal(t, ContainerInfo{Name: "pending", Image: "pending:1.0"}, containers[2])
+}
+
+// TestSearchByUID tests searching a pod by UID
+func TestSearchByUID(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default", "test-ns"},
+	}
+
+	ctx := context.Background()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "api-7d9c",
+			Namespace: "test-ns",
+			UID:       "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b",
+		},
+	}
+	_, err := fakeClient.CoreV1().Pods("test-ns").Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	pods, err := client.SearchByUID(ctx, "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "api-7d9c", pods[0].Name)
+	assert.Equal(t, "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b", pods[0].UID)
+
+	pods, err = client.SearchByUID(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+}
+
+// TestSearchByNode tests listing the pods scheduled on a node
+func TestSearchByNode(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default", "test-ns"},
+	}
+
+	ctx := context.Background()
+
+	for _, pod := range []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "test-ns"}, Spec: corev1.PodSpec{NodeName: "ip-10-1-2-3.ec2.internal"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "ip-10-1-2-3.ec2.internal"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-3", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "ip-10-1-2-4.ec2.internal"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}},
+	} {
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	pods, err := client.SearchByNode(ctx, "ip-10-1-2-3.ec2.internal")
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "web-1", pods[0].Name)
+	assert.Equal(t, "web-2", pods[1].Name)
+	assert.Equal(t, "ip-10-1-2-3.ec2.internal", pods[0].NodeName)
+
+	// The node is selected by the API server on top of the search's field selector
+	client.Options.FieldSelector = "status.phase=Running"
+	_, err = client.SearchByNode(ctx, "ip-10-1-2-3.ec2.internal")
+	require.NoError(t, err)
+	for _, action := range fakeClient.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok && action.GetResource().Resource == "pods" {
+			assert.Contains(t, list.GetListRestrictions().Fields.String(), "spec.nodeName=ip-10-1-2-3.ec2.internal")
+		}
+	}
+
+	pods, err = client.SearchByNode(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, pods)
+}
+
+// TestSearchByServiceAccount tests listing the pods running as a service account and filtering other searches by it
+func TestSearchByServiceAccount(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default", "test-ns"},
+	}
+
+	ctx := context.Background()
+
+	for _, pod := range []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "deployer-2", Namespace: "test-ns"}, Spec: corev1.PodSpec{ServiceAccountName: "deployer"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "deployer-1", Namespace: "default"}, Spec: corev1.PodSpec{ServiceAccountName: "deployer"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Spec: corev1.PodSpec{ServiceAccountName: "default"}},
+	} {
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	pods, err := client.SearchByServiceAccount(ctx, "deployer")
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "deployer-1", pods[0].Name)
+	assert.Equal(t, "deployer-2", pods[1].Name)
+	assert.Equal(t, "deployer", pods[0].ServiceAccount)
+	for _, action := range fakeClient.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok && action.GetResource().Resource == "pods" {
+			assert.Equal(t, "spec.serviceAccountName=deployer", list.GetListRestrictions().Fields.String())
+		}
+	}
+
+	// As a filter it composes with name searches
+	client.Options.ServiceAccount = "deployer"
+	pods, err = client.SearchByName(ctx, "-1")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "deployer-1", pods[0].Name)
+
+	pods, err = client.SearchByServiceAccount(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, pods)
+}
+
+// TestValidateUID tests UID validation
+func TestValidateUID(t *testing.T) {
+	assert.True(t, ValidateUID("0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b"))
+	assert.True(t, ValidateUID("0B6F1A2E-4C3D-4E5F-8A9B-1C2D3E4F5A6B"))
+	assert.False(t, ValidateUID("nginx-deployment-abc123"))
+	assert.False(t, ValidateUID("10.0.0.1"))
+	assert.False(t, ValidateUID(""))
+}
+
+// TestSearchServicesByPort tests searching services by port, target port and node port
+func TestSearchServicesByPort(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type: corev1.ServiceTypeNodePort,
+				Ports: []corev1.ServicePort{
+					{Name: "https", Port: 443, TargetPort: intstr.FromInt32(8443), NodePort: 31443},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Port: 80, TargetPort: intstr.FromString("http")},
+				},
+			},
+		},
+	}
+	for _, svc := range services {
+		_, err := fakeClient.CoreV1().Services("default").Create(ctx, svc, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		port     string
+		expected []string
+	}{
+		{"443", []string{"web"}},
+		{"8443", []string{"web"}},
+		{"31443", []string{"web"}},
+		{"https", []string{"web"}},
+		{"http", []string{"api"}},
+		{"80", []string{"api"}},
+		{"9999", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.port, func(t *testing.T) {
+			found, err := client.SearchServicesByPort(ctx, tt.port)
+			assert.NoError(t, err)
+
+			names := []string{}
+			for _, svc := range found {
+				names = append(names, svc.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestSearchPodsByContainerPort(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "proxy", Ports: []corev1.ContainerPort{{Name: "admin", ContainerPort: 9901}}},
+				},
+				Containers: []corev1.Container{
+					{Name: "nginx", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}}},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "coredns", Ports: []corev1.ContainerPort{{ContainerPort: 53, Protocol: corev1.ProtocolUDP}, {ContainerPort: 53}}},
+				},
+			},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	tests := []struct {
+		port     string
+		expected map[string][]string
+	}{
+		{"8080", map[string][]string{"web": {"nginx: 8080/TCP (http)"}}},
+		{"http", map[string][]string{"web": {"nginx: 8080/TCP (http)"}}},
+		{"9901", map[string][]string{"web": {"init: proxy: 9901/TCP (admin)"}}},
+		{"53", map[string][]string{"dns": {"coredns: 53/UDP", "coredns: 53/TCP"}}},
+		{"80", map[string][]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.port, func(t *testing.T) {
+			found, err := client.SearchPodsByContainerPort(context.Background(), tt.port)
+			require.NoError(t, err)
+
+			matched := map[string][]string{}
+			for _, pod := range found {
+				matched[pod.Name] = pod.MatchedPorts
+			}
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+// TestNodePorts tests node port reporting and formatting
+func TestNodePorts(t *testing.T) {
+	svc := newServiceInfo(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{
+				{Port: 443, NodePort: 31443, Protocol: corev1.ProtocolTCP},
+				{Port: 9090, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	})
+	assert.Equal(t, []int32{31443}, svc.NodePorts)
+	assert.True(t, hasNodePort(svc, "31443"))
+	assert.False(t, hasNodePort(svc, "443"))
+
+	assert.Equal(t, "31443/TCP", FormatNodePort(svc.Ports[0]))
+	assert.Equal(t, "", FormatNodePort(svc.Ports[1]))
+
+	assert.True(t, IsNodePort("30000"))
+	assert.True(t, IsNodePort("32767"))
+	assert.False(t, IsNodePort("8080"))
+	assert.False(t, IsNodePort("https"))
+}
+
+// TestServicePorts tests flattening service ports into PortInfo
+func TestServicePorts(t *testing.T) {
+	svc := newServiceInfo(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "https", Port: 443, TargetPort: intstr.FromString("https"), NodePort: 31443, Protocol: corev1.ProtocolTCP},
+				{Port: 53, TargetPort: intstr.FromInt32(5353), Protocol: corev1.ProtocolUDP},
+			},
+		},
+	})
+	assert.Equal(t, []PortInfo{
+		{Name: "https", Port: 443, TargetPort: "https", NodePort: 31443, Protocol: "TCP"},
+		{Port: 53, TargetPort: "5353", Protocol: "UDP"},
+	}, svc.Ports)
+
+	// Services without ports have none in the output
+	assert.Nil(t, newServiceInfo(&corev1.Service{}).Ports)
+}
+
+// TestNormalizeIP tests canonicalizing IP addresses
+func TestNormalizeIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       string
+		expected string
+	}{
+		{"IPv4", "10.0.0.1", "10.0.0.1"},
+		{"Compressed IPv6", "2001:db8::1", "2001:db8::1"},
+		{"Expanded IPv6", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"Uppercase IPv6", "2001:DB8::1", "2001:db8::1"},
+		{"IPv4-mapped IPv6", "::ffff:10.0.0.1", "10.0.0.1"},
+		{"Invalid", "not-an-ip", "not-an-ip"},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeIP(tt.ip))
+		})
+	}
+}
+
+// TestSearchByIPv6Normalization tests that expanded and compressed IPv6 forms match each other
+func TestSearchByIPv6Normalization(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "v6-pod", Namespace: "default"},
+		Status: corev1.PodStatus{
+			PodIP: "2001:0db8:0000:0000:0000:0000:0000:0001",
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "v6-service", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:   "fd00:10:96::a",
+			ExternalIPs: []string{"2001:db8:0:0:0:0:0:ff"},
+			Type:        corev1.ServiceTypeLoadBalancer,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8::0:fe"}},
+			},
+		},
+	}
+	_, err := fakeClient.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = fakeClient.CoreV1().Services("default").Create(ctx, svc, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// Compressed query matches expanded pod IP
+	pods, _, err := client.SearchByIP(ctx, "2001:db8::1")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "v6-pod", pods[0].Name)
+
+	// Expanded query matches compressed ClusterIP
+	_, services, err := client.SearchByIP(ctx, "fd00:0010:0096:0000:0000:0000:0000:000a")
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+
+	// ExternalIP and LoadBalancer ingress comparisons are normalized too
+	_, services, err = client.SearchByIP(ctx, "2001:db8::ff")
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+
+	_, services, err = client.SearchByIP(ctx, "2001:0db8::00fe")
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+}
+
+// TestSearchByIPDualStack tests matching the secondary pod and host addresses of dual-stack pods
+func TestSearchByIPDualStack(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-stack-pod", Namespace: "default"},
+		Status: corev1.PodStatus{
+			PodIP:   "10.0.0.5",
+			PodIPs:  []corev1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}},
+			HostIP:  "192.168.1.5",
+			HostIPs: []corev1.HostIP{{IP: "192.168.1.5"}, {IP: "fd01::5"}},
+		},
+	}
+	_, err := fakeClient.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	for _, ip := range []string{"10.0.0.5", "fd00::5", "192.168.1.5", "fd01::5"} {
+		pods, _, err := client.SearchByIP(ctx, ip)
+		assert.NoError(t, err)
+		require.Len(t, pods, 1, ip)
+		assert.Equal(t, []string{"10.0.0.5", "fd00::5"}, pods[0].PodIPs)
+		assert.Equal(t, []string{"192.168.1.5", "fd01::5"}, pods[0].HostIPs)
+	}
+}
+
+// TestSearchByIPDualStackService tests matching the secondary cluster IP of dual-stack services
+func TestSearchByIPDualStackService(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.5", ClusterIPs: []string{"10.96.0.5", "fd00:96::5"}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.6"},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	for _, ip := range []string{"10.96.0.5", "fd00:96::5", "fd00:96:0::5"} {
+		_, services, err := client.SearchByIP(ctx, ip)
+		require.NoError(t, err)
+		require.Len(t, services, 1, ip)
+		assert.Equal(t, "web", services[0].Name)
+		assert.Equal(t, MatchedClusterIP, services[0].MatchedOn)
+		assert.Equal(t, []string{"10.96.0.5", "fd00:96::5"}, services[0].ClusterIPs)
+	}
+
+	// Services without ClusterIPs, e.g. from older API servers, fall back to the primary ClusterIP
+	_, services, err := client.SearchByIP(ctx, "10.96.0.6")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, []string{"10.96.0.6"}, services[0].ClusterIPs)
+}
+
+// TestSearchByCIDR tests matching pods and services by CIDR range, and host IPs only with HostIPOnly
+func TestSearchByCIDR(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "10.1.2.7", HostIP: "192.168.1.5"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.9", HostIP: "10.1.2.20"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "10.1.2.30", HostIP: "10.1.2.30"},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.1.2.100"},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	pods, services, err := client.SearchByIP(ctx, "10.1.2.0/24")
+	require.NoError(t, err)
+	matched := map[string]string{}
+	for _, pod := range pods {
+		matched[pod.Name] = pod.MatchedOn
+	}
+	assert.Equal(t, map[string]string{"web-1": MatchedPodIP, "web-2": MatchedHostIP, "agent": MatchedPodIP}, matched)
+	require.Len(t, services, 1)
+	assert.Equal(t, MatchedClusterIP, services[0].MatchedOn)
+
+	// Host IP searches skip pod IPs and services
+	client.Options.HostIPOnly = true
+	pods, services, err = client.SearchByIP(ctx, "10.1.2.0/24")
+	require.NoError(t, err)
+	matched = map[string]string{}
+	for _, pod := range pods {
+		matched[pod.Name] = pod.MatchedOn
+	}
+	assert.Equal(t, map[string]string{"web-2": MatchedHostIP, "agent": MatchedHostIP}, matched)
+	assert.Empty(t, services)
+
+	pods, _, err = client.SearchByIP(ctx, "10.1.2.7")
+	require.NoError(t, err)
+	assert.Empty(t, pods)
+
+	assert.True(t, ValidateCIDR("10.1.2.0/24"))
+	assert.True(t, ValidateCIDR("fd00::/64"))
+	assert.False(t, ValidateCIDR("10.1.2.0"))
+	assert.False(t, ValidateCIDR("10.1.2.0/33"))
+}
+
+// TestSearchByIPContains tests matching addresses containing part of an IP with IPContains
+func TestSearchByIPContains(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "10.1.2.137", HostIP: "192.168.1.5"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.9", HostIP: "192.168.1.137"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.137"},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{IPContains: true},
+	}
+
+	pods, services, err := client.SearchByIP(context.Background(), ".137")
+	require.NoError(t, err)
+	matched := map[string]string{}
+	for _, pod := range pods {
+		matched[pod.Name] = pod.MatchedOn
+	}
+	assert.Equal(t, map[string]string{"web-1": MatchedPodIP, "web-2": MatchedHostIP}, matched)
+	require.Len(t, services, 1)
+	assert.Equal(t, MatchedClusterIP, services[0].MatchedOn)
+
+	pods, services, err = client.SearchByIP(context.Background(), "10.1.")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "web-1", pods[0].Name)
+	assert.Empty(t, services)
+
+	assert.True(t, ValidateIPFragment(".137"))
+	assert.True(t, ValidateIPFragment("fd00::"))
+	assert.False(t, ValidateIPFragment(""))
+	assert.False(t, ValidateIPFragment("web"))
+	assert.False(t, ValidateIPFragment("10.0.0.0/24"))
+}
+
+// TestSearchSince tests filtering pods by creation time
+func TestSearchSince(t *testing.T) {
+	now := time.Now()
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-new", Namespace: "default", CreationTimestamp: metav1.NewTime(now.Add(-5 * time.Minute))},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-old", Namespace: "default", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.2"},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{Since: 30 * time.Minute},
+	}
+
+	ctx := context.Background()
+
+	pods, err := client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "nginx-new", pods[0].Name)
+	assert.True(t, pods[0].Age() < 30*time.Minute)
+
+	pods, _, err = client.SearchByIP(ctx, "10.0.0.2")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+
+	// Without the filter both pods match
+	client.Options.Since = 0
+	pods, err = client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 2)
+}
+
+// TestSearchRunningOnly tests that --running-only drops pods outside the Running phase
+func TestSearchRunningOnly(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-running", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-done", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded, PodIP: "10.0.0.2"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-pending", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{RunningOnly: true},
+	}
+
+	ctx := context.Background()
+
+	pods, err := client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "nginx-running", pods[0].Name)
+
+	pods, _, err = client.SearchByIP(ctx, "10.0.0.2")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+
+	// Without the filter every phase matches
+	client.Options.RunningOnly = false
+	pods, err = client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 3)
+}
+
+// TestSearchServicesSince tests that --since filters services by creation time in IP and port searches
+func TestSearchServicesSince(t *testing.T) {
+	now := time.Now()
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb-new", Namespace: "default", CreationTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.1", Ports: []corev1.ServicePort{{Port: 443}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb-old", Namespace: "default", CreationTimestamp: metav1.NewTime(now.Add(-48 * time.Hour))},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.2", Ports: []corev1.ServicePort{{Port: 443}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", CreationTimestamp: metav1.NewTime(now)},
+			Status:     corev1.PodStatus{PodIP: "10.96.0.5"},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{Since: time.Hour},
+	}
+	ctx := context.Background()
+
+	_, services, err := client.SearchByIP(ctx, "10.96.0.0/24")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "lb-new", services[0].Name)
+	assert.False(t, services[0].CreatedAt.IsZero())
+
+	services, err = client.SearchServicesByPort(ctx, "443")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "lb-new", services[0].Name)
+
+	// ServicesOnly skips the pods an IP search would otherwise match
+	client.Options.ServicesOnly = true
+	pods, services, err := client.SearchByIP(ctx, "10.96.0.0/24")
+	require.NoError(t, err)
+	assert.Empty(t, pods)
+	assert.Len(t, services, 1)
+
+	client.Options.ServicesOnly = false
+	pods, _, err = client.SearchByIP(ctx, "10.96.0.0/24")
+	require.NoError(t, err)
+	assert.Len(t, pods, 1)
+}
+
+// TestSortResults tests that results are ordered by context, namespace and name
+func TestSortResults(t *testing.T) {
+	results := []SearchResultWithContext{
+		{Context: "prod", Namespace: "web", Pods: []PodInfo{{Name: "web-b"}, {Name: "web-a"}}},
+		{Context: "dev", Namespace: "web"},
+		{Context: "prod", Namespace: "api", Services: []ServiceInfo{{Name: "svc-z"}, {Name: "svc-a"}}},
+	}
+
+	SortIPResults(results)
+	assert.Equal(t, "dev", results[0].Context)
+	assert.Equal(t, "api", results[1].Namespace)
+	assert.Equal(t, "web", results[2].Namespace)
+	assert.Equal(t, "web-a", results[2].Pods[0].Name)
+	assert.Equal(t, "svc-a", results[1].Services[0].Name)
+
+	podResults := []PodResultWithContext{
+		{Context: "b", Namespace: "default", Pods: []PodInfo{{Name: "nginx-2"}, {Name: "nginx-1"}}},
+		{Context: "a", Namespace: "default"},
+	}
+
+	SortPodResults(podResults)
+	assert.Equal(t, "a", podResults[0].Context)
+	assert.Equal(t, "nginx-1", podResults[1].Pods[0].Name)
+}
+
+// TestSearchOptionsValidate tests rejecting field selectors the API server would not honour
+func TestSearchOptionsValidate(t *testing.T) {
+	assert.NoError(t, SearchOptions{}.Validate())
+	assert.NoError(t, SearchOptions{FieldSelector: "status.phase=Running,spec.nodeName=node-1"}.Validate())
+	assert.NoError(t, SearchOptions{FieldSelector: "status.phase!=Succeeded"}.Validate())
+
+	assert.Error(t, SearchOptions{FieldSelector: "spec.containers=nginx"}.Validate())
+	assert.Error(t, SearchOptions{FieldSelector: "status.phase"}.Validate())
+	assert.Error(t, SearchOptions{HostIPOnly: true, ServicesOnly: true}.Validate())
+}
+
+// TestSearchFieldSelector tests that the field selector is sent with pod list calls
+func TestSearchFieldSelector(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	var selector string
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selector = action.(k8stesting.ListAction).GetListRestrictions().Fields.String()
+		return false, nil, nil
+	})
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{FieldSelector: "spec.nodeName=node-1"},
+	}
+
+	_, err := client.SearchByName(context.Background(), "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "spec.nodeName=node-1", selector)
+}
+
+// TestSearchPaginated tests that pod listing follows continue tokens across pages
+func TestSearchPaginated(t *testing.T) {
+	pages := map[string]*corev1.PodList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"}}},
+		},
+		"page-2": {
+			ListMeta: metav1.ListMeta{Continue: "page-3"},
+			Items:    []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "redis-1", Namespace: "default"}}},
+		},
+		"page-3": {
+			Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "nginx-2", Namespace: "default", UID: "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b"}}},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset()
+	requested := []string{}
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		options := action.(k8stesting.ListActionImpl).ListOptions
+		assert.Equal(t, podPageSize, options.Limit)
+		requested = append(requested, options.Continue)
+		return true, pages[options.Continue], nil
+	})
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	pods, err := client.SearchByName(ctx, "nginx")
+	assert.NoError(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "nginx-1", pods[0].Name)
+	assert.Equal(t, "nginx-2", pods[1].Name)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, requested)
+
+	requested = []string{}
+	pods, err = client.SearchByUID(ctx, "0b6f1a2e-4c3d-4e5f-8a9b-1c2d3e4f5a6b")
+	assert.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "nginx-2", pods[0].Name)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, requested)
+}
+
+// TestSearchByAnnotation tests filtering pods by annotation presence and value globs
+func TestSearchByAnnotation(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default", Annotations: map[string]string{"build/commit": "abc123"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-2", Namespace: "default", Annotations: map[string]string{"build/commit": "def456", "team": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-3", Namespace: "default"}},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	tests := []struct {
+		filters  []string
+		expected []string
+	}{
+		{[]string{"build/commit=abc123"}, []string{"nginx-1"}},
+		{[]string{"build/commit"}, []string{"nginx-1", "nginx-2"}},
+		{[]string{"build/commit=def*"}, []string{"nginx-2"}},
+		{[]string{"build/commit", "team=web"}, []string{"nginx-2"}},
+		{[]string{"missing"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		client.Options.Annotations = tt.filters
+		pods, err := client.SearchByName(ctx, "nginx")
+		assert.NoError(t, err)
+
+		names := []string{}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		assert.Equal(t, tt.expected, names, "filters %v", tt.filters)
+	}
+
+	assert.Error(t, SearchOptions{Annotations: []string{"=abc"}}.Validate())
+	assert.Error(t, SearchOptions{Annotations: []string{"build/commit=[abc"}}.Validate())
+}
+
+// TestSearchByOwnerKind tests filtering pods by the kind at the top of their owner chain
+func TestSearchByOwnerKind(t *testing.T) {
+	isController := true
+	fakeClient := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-rs", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &isController}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-rs", Controller: &isController}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "orphan-rs", Controller: &isController}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-agent", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", Controller: &isController}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-debug", Namespace: "default"}},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+	}
+
+	ctx := context.Background()
+
+	tests := []struct {
+		kinds    []string
+		expected []string
+	}{
+		{nil, []string{"web-1", "web-2", "web-agent", "web-debug"}},
+		{[]string{"Deployment"}, []string{"web-1"}},
+		{[]string{"ReplicaSet"}, []string{"web-2"}},
+		{[]string{"daemonset"}, []string{"web-agent"}},
+		{[]string{"none"}, []string{"web-debug"}},
+		{[]string{"DaemonSet", "none"}, []string{"web-agent", "web-debug"}},
+		{[]string{"StatefulSet"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		client.Options.OwnerKinds = tt.kinds
+		pods, err := client.SearchByName(ctx, "web")
+		assert.NoError(t, err)
+
+		names := []string{}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		assert.Equal(t, tt.expected, names, "owner kinds %v", tt.kinds)
+	}
+
+	assert.Error(t, SearchOptions{OwnerKinds: []string{" "}}.Validate())
+}
+
+// TestTopOwner tests resolving the kind and name at the top of a pod's owner chain
+func TestTopOwner(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-rs", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "backup-28341", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"}},
+	)
+	client := &K8sClient{Clientset: fakeClient}
+	ctx := context.Background()
+
+	tests := []struct {
+		pod  PodInfo
+		kind string
+		name string
+	}{
+		{PodInfo{Namespace: "default", OwnerKind: "ReplicaSet", OwnerName: "web-rs"}, "Deployment", "web"},
+		{PodInfo{Namespace: "default", OwnerKind: "ReplicaSet", OwnerName: "orphan-rs"}, "ReplicaSet", "orphan-rs"},
+		{PodInfo{Namespace: "default", OwnerKind: "Job", OwnerName: "backup-28341"}, "CronJob", "backup"},
+		{PodInfo{Namespace: "default", OwnerKind: "Job", OwnerName: "migrate"}, "Job", "migrate"},
+		{PodInfo{Namespace: "default", OwnerKind: "StatefulSet", OwnerName: "db"}, "StatefulSet", "db"},
+		{PodInfo{Namespace: "default"}, NoOwnerKind, ""},
+	}
+
+	for _, tt := range tests {
+		kind, name := client.TopOwner(ctx, tt.pod)
+		assert.Equal(t, tt.kind, kind, "owner %s/%s", tt.pod.OwnerKind, tt.pod.OwnerName)
+		assert.Equal(t, tt.name, name, "owner %s/%s", tt.pod.OwnerKind, tt.pod.OwnerName)
+	}
+}
+
+// TestGetCronJobForJob tests tracing a Job back to the CronJob that spawned it and its schedule
+func TestGetCronJobForJob(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"}, Spec: batchv1.CronJobSpec{Schedule: "0 2 * * *"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "backup-28341", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "report-28341", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "report"}}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"}},
+	)
+	client := &K8sClient{Clientset: fakeClient}
+	ctx := context.Background()
+
+	name, schedule, err := client.GetCronJobForJob(ctx, "default", "backup-28341")
+	require.NoError(t, err)
+	assert.Equal(t, "backup", name)
+	assert.Equal(t, "0 2 * * *", schedule)
+
+	// A deleted CronJob still names the owner, without a schedule
+	name, schedule, err = client.GetCronJobForJob(ctx, "default", "report-28341")
+	require.NoError(t, err)
+	assert.Equal(t, "report", name)
+	assert.Empty(t, schedule)
+
+	_, _, err = client.GetCronJobForJob(ctx, "default", "migrate")
+	assert.Error(t, err)
+	_, _, err = client.GetCronJobForJob(ctx, "default", "missing")
+	assert.Error(t, err)
+}
+
+// TestServicesSelectingPod tests matching services to pods by label selector
+func TestServicesSelectingPod(t *testing.T) {
+	pod := PodInfo{Name: "nginx-1", Namespace: "default", Labels: map[string]string{"app": "nginx", "tier": "web"}}
+
+	services := []ServiceInfo{
+		{Name: "nginx", Namespace: "default", Selector: map[string]string{"app": "nginx"}},
+		{Name: "nginx-web", Namespace: "default", Selector: map[string]string{"app": "nginx", "tier": "web"}},
+		{Name: "nginx-api", Namespace: "default", Selector: map[string]string{"app": "nginx", "tier": "api"}},
+		{Name: "external", Namespace: "default"},
+		{Name: "nginx", Namespace: "other", Selector: map[string]string{"app": "nginx"}},
+	}
+
+	matched := ServicesSelectingPod(services, pod)
+	require.Len(t, matched, 2)
+	assert.Equal(t, "nginx", matched[0].Name)
+	assert.Equal(t, "nginx-web", matched[1].Name)
+}
+
+// TestListNamespaces tests listing every namespace without access checks
+func TestListNamespaces(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	client := &K8sClient{Clientset: fakeClient}
+
+	names, err := client.ListNamespaces(context.Background())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"default", "kube-system"}, names)
+}
+
+// TestProbeNamespaceAccess tests probing namespaces in parallel, sorted by name whatever order they finish in
+func TestProbeNamespaceAccess(t *testing.T) {
+	var active, maxActive atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			seen := maxActive.Load()
+			if n <= seen || maxActive.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/kube-") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+			return
+		}
+		w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewK8sClient(writeServerKubeconfig(t, server.URL), "dev", []string{})
+	require.NoError(t, err)
+
+	namespaces := []string{"web", "kube-system", "default", "payments", "kube-public", "batch"}
+	probed := client.ProbeNamespaceAccess(context.Background(), namespaces, 4)
+	require.Len(t, probed, len(namespaces))
+	names := []string{}
+	for _, access := range probed {
+		names = append(names, access.Namespace)
+		assert.Equal(t, !strings.HasPrefix(access.Namespace, "kube-"), access.HasAccess, access.Namespace)
+		if !access.HasAccess {
+			assert.True(t, IsPermissionError(access.Err))
+		}
+	}
+	assert.Equal(t, []string{"batch", "default", "kube-public", "kube-system", "payments", "web"}, names)
+	assert.Greater(t, maxActive.Load(), int32(1))
+	assert.LessOrEqual(t, maxActive.Load(), int32(4))
+
+	// A cancelled probe leaves the namespaces out
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Empty(t, client.ProbeNamespaceAccess(ctx, namespaces, 4))
+}
+
+// TestListContextNamespaces tests listing the namespaces of a context without probing them, falling back
+// to the context's namespace from kubeconfig when listing namespaces is forbidden
+func TestListContextNamespaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"web"}},{"metadata":{"name":"default"}}]}`))
+	}))
+	defer server.Close()
+
+	names, err := ListContextNamespaces(context.Background(), writeServerKubeconfig(t, server.URL), "dev", SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "web"}, names)
+
+	// A context that cannot list namespaces offers its configured namespace, or fails without one
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+	}))
+	defer forbidden.Close()
+
+	kubeconfigPath := writeServerKubeconfig(t, forbidden.URL)
+	data, err := os.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	restricted := strings.Replace(string(data), "  name: prod", "    namespace: team\n  name: prod", 1)
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(restricted), 0644))
+
+	names, err = ListContextNamespaces(context.Background(), kubeconfigPath, "prod", SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team"}, names)
+
+	_, err = ListContextNamespaces(context.Background(), kubeconfigPath, "dev", SearchOptions{})
+	assert.Error(t, err)
+}
+
+// TestPlanSearch tests listing the contexts and namespaces a search would scan
+func TestPlanSearch(t *testing.T) {
+	tempDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tempDir, "kubeconfig")
+
+	kubeconfigContent := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://test-cluster-1:6443
+  name: test-cluster-1
+contexts:
+- context:
+    cluster: test-cluster-1
+    user: test-user
+  name: prod
+- context:
+    cluster: test-cluster-1
+    user: test-user
+  name: dev
+current-context: prod
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	err := os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Explicit namespaces need no API calls
+	plans, err := PlanSearch(ctx, kubeconfigPath, []string{"default", "web"}, nil, SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []ContextPlan{
+		{Context: "dev", Namespaces: []string{"default", "web"}},
+		{Context: "prod", Namespaces: []string{"default", "web"}},
+	}, plans)
+
+	plans, err = PlanSearch(ctx, kubeconfigPath, []string{"default"}, []string{"prod"}, SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []ContextPlan{{Context: "prod", Namespaces: []string{"default"}}}, plans)
+
+	_, err = PlanSearch(ctx, kubeconfigPath, []string{"default"}, []string{"missing"}, SearchOptions{})
+	assert.Error(t, err)
+}
+
+// TestSearchProgress tests the progress reported for every namespace of every context
+func TestSearchProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tempDir, "kubeconfig")
+
+	kubeconfigContent := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://test-cluster-1:6443
+  name: test-cluster-1
+contexts:
+- context:
+    cluster: test-cluster-1
+    user: test-user
+  name: prod
+- context:
+    cluster: test-cluster-1
+    user: test-user
+  name: dev
+current-context: prod
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644))
+
+	events := []SearchProgress{}
+	opts := SearchOptions{OnProgress: func(progress SearchProgress) {
+		events = append(events, progress)
+	}}
+
+	_, err := PlanSearch(context.Background(), kubeconfigPath, []string{"default", "web"}, nil, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SearchProgress{
+		{Context: "dev", ContextIndex: 1, Contexts: 2, NamespaceIndex: 1, Namespaces: 2},
+		{Context: "dev", ContextIndex: 1, Contexts: 2, NamespaceIndex: 2, Namespaces: 2},
+		{Context: "prod", ContextIndex: 2, Contexts: 2, NamespaceIndex: 1, Namespaces: 2},
+		{Context: "prod", ContextIndex: 2, Contexts: 2, NamespaceIndex: 2, Namespaces: 2},
+		{ContextIndex: 2, Contexts: 2, Done: true},
+	}, events)
+
+	assert.Equal(t, 0.0, events[0].Fraction())
+	assert.Equal(t, 0.25, events[1].Fraction())
+	assert.Equal(t, 0.75, events[3].Fraction())
+	assert.Equal(t, 1.0, events[4].Fraction())
+}
+
+// TestCheckConnectivity tests the cluster reachability precheck
+func TestCheckConnectivity(t *testing.T) {
+	newClient := func(handler http.HandlerFunc) *K8sClient {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		require.NoError(t, err)
+		return &K8sClient{Clientset: clientset}
+	}
+
+	ctx := context.Background()
+
+	reachable := newClient(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"major":"1","minor":"34"}`))
+	})
+	assert.NoError(t, reachable.CheckConnectivity(ctx, time.Second))
+
+	forbidden := newClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+	})
+	assert.NoError(t, forbidden.CheckConnectivity(ctx, time.Second))
+
+	hanging := newClient(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	assert.Error(t, hanging.CheckConnectivity(ctx, 50*time.Millisecond))
+
+	// A zero timeout disables the check
+	assert.NoError(t, hanging.CheckConnectivity(ctx, 0))
+}
+
+// TestResultLimit tests trimming matches to the cap and signalling once it is reached
+func TestResultLimit(t *testing.T) {
+	full := 0
+	limit := newResultLimit(3, func() { full++ })
+
+	pods, services := limit.takePodsAndServices([]PodInfo{{Name: "a"}, {Name: "b"}}, []ServiceInfo{{Name: "x"}, {Name: "y"}})
+	assert.Len(t, pods, 2)
+	assert.Len(t, services, 1)
+	assert.Equal(t, 1, full)
+	assert.Equal(t, 0, limit.take(5))
+
+	// No cap keeps everything
+	unlimited := newResultLimit(0, nil)
+	assert.Equal(t, 100, unlimited.take(100))
+
+	// Single client searches are capped too, pods before services
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.1", HostIP: "10.0.0.1"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}, Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.1"}},
+	)
+	client := &K8sClient{Clientset: fakeClient, Namespaces: []string{"default"}, Options: SearchOptions{MaxResults: 1}}
+	foundPods, foundServices, err := client.SearchByIP(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Len(t, foundPods, 1)
+	assert.Empty(t, foundServices)
+}
+
+// TestSearchMaxResults tests that the cap stops an all-contexts search at the namespace that fills it
+func TestSearchMaxResults(t *testing.T) {
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests look like /api/v1/namespaces/<namespace>/pods
+		requested = append(requested, r.URL.Path)
+		namespace := filepath.Base(filepath.Dir(r.URL.Path))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"nginx-1","namespace":"` + namespace + `"}},` +
+			`{"metadata":{"name":"nginx-2","namespace":"` + namespace + `"}}]}`))
+	}))
+	defer server.Close()
+
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	ctx := context.Background()
+	results, err := SearchByNameAllContexts(ctx, kubeconfigPath, "nginx", []string{"a", "b", "c"}, nil, SearchOptions{MaxResults: 3})
+	require.NoError(t, err)
+
+	// Namespace b fills the cap, so c and the prod context are never searched
+	require.Len(t, results, 2)
+	assert.Len(t, results[0].Pods, 2)
+	assert.Len(t, results[1].Pods, 1)
+	// Results carry the server URL of their context's cluster
+	assert.Equal(t, server.URL, results[0].Server)
+	assert.Equal(t, []string{"/api/v1/namespaces/a/pods", "/api/v1/namespaces/b/pods"}, requested)
+
+	// A cancelled search skips everything
+	requested = []string{}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	results, err = SearchByNameAllContexts(cancelled, kubeconfigPath, "nginx", []string{"a"}, nil, SearchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Empty(t, requested)
+}
+
+// TestSearchFailedNamespaces tests that failed namespace searches are reported while the other namespaces are still searched
+func TestSearchFailedNamespaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "/namespaces/broken/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"etcd unavailable","code":500}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"nginx-1","namespace":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	failed := []string{}
+	opts := SearchOptions{OnSearchFailed: func(contextName string, namespace string, err error) {
+		failed = append(failed, contextName+"/"+namespace+": "+err.Error())
+	}}
+	results, err := SearchByNameAllContexts(context.Background(), writeServerKubeconfig(t, server.URL), "nginx", []string{"broken", "ok"}, []string{"dev"}, opts)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "ok", results[0].Namespace)
+	require.Len(t, failed, 1)
+	assert.Contains(t, failed[0], "dev/broken: ")
+	assert.Contains(t, failed[0], "etcd unavailable")
+}
+
+// TestSearchByIPOrNameAllContexts tests searching by IP and by name in one pass, listing pods matching both ways once
+func TestSearchByIPOrNameAllContexts(t *testing.T) {
+	var podLists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/services") {
+			_, _ = w.Write([]byte(`{"kind":"ServiceList","apiVersion":"v1","metadata":{},"items":[]}`))
+			return
+		}
+		podLists.Add(1)
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"web-1","namespace":"default","uid":"a"},"status":{"podIP":"10.0.0.5"}},` +
+			`{"metadata":{"name":"probe-10.0.0.5","namespace":"default","uid":"b"},"status":{"podIP":"10.0.0.7"}},` +
+			`{"metadata":{"name":"ip-10.0.0.5","namespace":"default","uid":"c"},"status":{"podIP":"10.0.0.5"}},` +
+			`{"metadata":{"name":"static-10.0.0.5","namespace":"default"},"status":{"podIP":"10.0.0.5"}},` +
+			`{"metadata":{"name":"api","namespace":"default","uid":"d"},"status":{"podIP":"10.0.0.9"}}]}`))
+	}))
+	defer server.Close()
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	results, err := SearchByIPOrNameAllContexts(context.Background(), kubeconfigPath, "10.0.0.5", []string{"default"}, []string{"dev"}, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	matched := map[string]string{}
+	for _, pod := range results[0].Pods {
+		matched[pod.Name] = pod.MatchedOn
+	}
+	// Pods without a UID matching both ways are listed once too
+	assert.Len(t, results[0].Pods, 4)
+	assert.Equal(t, map[string]string{"ip-10.0.0.5": MatchedPodIP, "probe-10.0.0.5": MatchedName, "static-10.0.0.5": MatchedPodIP, "web-1": MatchedPodIP}, matched)
+	assert.Equal(t, int32(1), podLists.Load(), "the pods of a namespace are listed once for both matches")
+
+	// Queries that are not IPs are only searched by name
+	results, err = SearchByIPOrNameAllContexts(context.Background(), kubeconfigPath, "api", []string{"default"}, []string{"dev"}, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Pods, 1)
+	assert.Equal(t, "api", results[0].Pods[0].Name)
+	assert.Equal(t, MatchedName, results[0].Pods[0].MatchedOn)
+}
+
+// writeServerKubeconfig writes a kubeconfig with the contexts dev and prod pointing at server
+func writeServerKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfigContent := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: ` + server + `
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: dev
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: prod
+current-context: dev
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644))
+	return kubeconfigPath
+}
+
+// TestSearchTimings tests the per-context timings and API call counts reported by all-contexts searches
+func TestSearchTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`))
+	}))
+	defer server.Close()
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	timings := []ContextTiming{}
+	opts := SearchOptions{OnContextSearched: func(timing ContextTiming) {
+		timings = append(timings, timing)
+	}}
+	_, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "nginx", []string{"a", "b"}, nil, opts)
+	require.NoError(t, err)
+
+	require.Len(t, timings, 2)
+	for i, contextName := range []string{"dev", "prod"} {
+		assert.Equal(t, contextName, timings[i].Context)
+		assert.Equal(t, 2, timings[i].Namespaces)
+		assert.Equal(t, int64(2), timings[i].APICalls)
+		assert.False(t, timings[i].Skipped)
+	}
+}
+
+// TestSearchConcurrency tests that parallel searches of contexts and namespaces find the same results as sequential ones
+func TestSearchConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt64(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt64(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		// Keep requests open long enough to overlap
+		time.Sleep(20 * time.Millisecond)
+
+		namespace := filepath.Base(filepath.Dir(r.URL.Path))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"nginx-1","namespace":"` + namespace + `"}}]}`))
+	}))
+	defer server.Close()
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+	namespaces := []string{"a", "b", "c"}
+
+	sequential, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "nginx", namespaces, nil, SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), maxInFlight)
+
+	atomic.StoreInt64(&maxInFlight, 0)
+	timings := []ContextTiming{}
+	opts := SearchOptions{
+		ContextConcurrency:   2,
+		NamespaceConcurrency: 2,
+		QPS:                  100,
+		Burst:                100,
+		OnContextSearched: func(timing ContextTiming) {
+			timings = append(timings, timing)
+		},
+	}
+	parallel, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "nginx", namespaces, nil, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, sequential, parallel)
+	assert.Len(t, parallel, 6)
+	assert.Greater(t, maxInFlight, int64(1))
+	assert.LessOrEqual(t, maxInFlight, int64(4))
+	require.Len(t, timings, 2)
+	for _, timing := range timings {
+		assert.Equal(t, 3, timing.Namespaces)
+		assert.Equal(t, int64(3), timing.APICalls)
+	}
+}
+
+// TestSearchOptionsValidateConcurrency tests that negative concurrency and rate limits are rejected
+func TestSearchOptionsValidateConcurrency(t *testing.T) {
+	assert.NoError(t, SearchOptions{ContextConcurrency: 4, NamespaceConcurrency: 8, QPS: 50, Burst: 100}.Validate())
+	assert.ErrorIs(t, SearchOptions{ContextConcurrency: -1}.Validate(), ErrInvalidOptions)
+	assert.ErrorIs(t, SearchOptions{NamespaceConcurrency: -1}.Validate(), ErrInvalidOptions)
+	assert.ErrorIs(t, SearchOptions{QPS: -1}.Validate(), ErrInvalidOptions)
+	assert.ErrorIs(t, SearchOptions{Burst: -1}.Validate(), ErrInvalidOptions)
+}
+
+// TestNewK8sClientWithOptions tests that the rate limit can be set with QPS, Burst or both
+func TestNewK8sClientWithOptions(t *testing.T) {
+	kubeconfigPath := writeServerKubeconfig(t, "https://127.0.0.1:6443")
+
+	for _, opts := range []SearchOptions{{QPS: 50}, {Burst: 100}, {QPS: 50, Burst: 100}} {
+		client, err := NewK8sClientWithOptions(kubeconfigPath, "prod", []string{"default"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "prod", client.ContextName)
+		assert.Equal(t, opts, client.Options)
+	}
+}
+
+// TestSearchContextTimeout tests that a context running out of time is reported as skipped and keeps its earlier matches
+func TestSearchContextTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace := filepath.Base(filepath.Dir(r.URL.Path))
+		// Namespace slow never answers within the per-context timeout
+		if namespace == "slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` +
+			`{"metadata":{"name":"nginx-1","namespace":"` + namespace + `"}}]}`))
+	}))
+	defer server.Close()
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+
+	skipped := map[string]string{}
+	timings := []ContextTiming{}
+	opts := SearchOptions{
+		ContextTimeout: 200 * time.Millisecond,
+		OnContextSkipped: func(contextName string, err error) {
+			skipped[contextName] = err.Error()
+		},
+		OnContextSearched: func(timing ContextTiming) {
+			timings = append(timings, timing)
+		},
+	}
+	results, err := SearchByNameAllContexts(context.Background(), kubeconfigPath, "nginx", []string{"a", "slow"}, nil, opts)
+	require.NoError(t, err)
+
+	// Both contexts time out in namespace slow, after finding the pod in namespace a
+	require.Len(t, results, 2)
+	assert.Equal(t, "dev", results[0].Context)
+	assert.Equal(t, "prod", results[1].Context)
+	assert.Equal(t, map[string]string{"dev": "search timed out after 200ms", "prod": "search timed out after 200ms"}, skipped)
+	require.Len(t, timings, 2)
+	for _, timing := range timings {
+		assert.True(t, timing.TimedOut)
+		assert.False(t, timing.Skipped)
+	}
+
+	assert.ErrorIs(t, SearchOptions{ContextTimeout: -time.Second}.Validate(), ErrInvalidOptions)
+}
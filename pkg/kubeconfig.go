@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// expandKubeconfigPath expands environment variables and a leading ~ in a kubeconfig path,
+// which clientcmd leaves to the shell; scripts often pass paths like "~/.kube/config" literally
+func expandKubeconfigPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// resolveKubeconfigPath expands a kubeconfig path and follows symlinks to the file they point at,
+// reporting a missing file apart from a file that cannot be parsed
+func resolveKubeconfigPath(path string) (string, error) {
+	expanded := expandKubeconfigPath(path)
+	resolved, err := filepath.EvalSymlinks(expanded)
+	if err == nil {
+		return resolved, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		if expanded != path {
+			return "", withKind(ErrKubeconfig, fmt.Errorf("kubeconfig file %s (expanded from %s) does not exist", expanded, path))
+		}
+		return "", withKind(ErrKubeconfig, fmt.Errorf("kubeconfig file %s does not exist", expanded))
+	}
+	return "", withKind(ErrKubeconfig, fmt.Errorf("failed to resolve kubeconfig path %s: %w", expanded, err))
+}
+
+// splitKubeconfigPaths splits a kubeconfig path listing several files, separated by commas
+// or like KUBECONFIG by the OS path list separator, e.g. "~/.kube/config,./oneoff.yaml"
+func splitKubeconfigPaths(kubeconfigPath string) []string {
+	paths := []string{}
+	for _, part := range strings.Split(kubeconfigPath, ",") {
+		for _, path := range filepath.SplitList(part) {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// ContextRename is a context renamed while merging several kubeconfig files,
+// because an earlier file already has a context of that name
+type ContextRename struct {
+	File    string `json:"file"`
+	Context string `json:"context"`
+	Renamed string `json:"renamed"`
+}
+
+// KubeconfigRenames returns the contexts renamed when the files of kubeconfigPath are merged
+// by LoadKubeConfig, none for a single file
+func KubeconfigRenames(kubeconfigPath string) ([]ContextRename, error) {
+	paths := splitKubeconfigPaths(kubeconfigPath)
+	if len(paths) < 2 {
+		return []ContextRename{}, nil
+	}
+	_, renames, err := mergeKubeconfigFiles(paths)
+	return renames, err
+}
+
+// mergeKubeconfigFiles loads and merges several kubeconfig files. Unlike KUBECONFIG merging in kubectl,
+// where the first file setting a name wins, contexts of later files whose name is taken are kept under
+// "<name>@<file name>", e.g. prod@oneoff for oneoff.yaml, and reported. Clusters and users whose name
+// is taken are renamed likewise, so each context keeps pointing at the cluster and user of its file.
+// The current context is the one of the first file setting it.
+func mergeKubeconfigFiles(paths []string) (*api.Config, []ContextRename, error) {
+	merged := api.NewConfig()
+	renames := []ContextRename{}
+	loaded := map[string]bool{}
+
+	for _, kubeconfigPath := range paths {
+		path, err := resolveKubeconfigPath(kubeconfigPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		// A file given twice would only add copies of its own contexts
+		if loaded[path] {
+			continue
+		}
+		loaded[path] = true
+
+		config, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			return nil, nil, withKind(ErrKubeconfig, fmt.Errorf("failed to load kubeconfig %s: %w", path, err))
+		}
+		// Relative certificate and key paths are relative to their own file, not the working directory
+		if err := clientcmd.ResolveLocalPaths(config); err != nil {
+			return nil, nil, withKind(ErrKubeconfig, fmt.Errorf("failed to resolve paths in kubeconfig %s: %w", path, err))
+		}
+
+		suffix := "@" + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		clusters := map[string]string{}
+		for _, name := range sortedNames(config.Clusters) {
+			clusters[name] = uniqueName(name, suffix, func(name string) bool { return merged.Clusters[name] != nil })
+			merged.Clusters[clusters[name]] = config.Clusters[name]
+		}
+		users := map[string]string{}
+		for _, name := range sortedNames(config.AuthInfos) {
+			users[name] = uniqueName(name, suffix, func(name string) bool { return merged.AuthInfos[name] != nil })
+			merged.AuthInfos[users[name]] = config.AuthInfos[name]
+		}
+
+		for _, name := range GetContexts(config) {
+			kubeContext := config.Contexts[name]
+			renamed := uniqueName(name, suffix, func(name string) bool { return merged.Contexts[name] != nil })
+			if renamed != name {
+				renames = append(renames, ContextRename{File: path, Context: name, Renamed: renamed})
+			}
+			if cluster, ok := clusters[kubeContext.Cluster]; ok {
+				kubeContext.Cluster = cluster
+			}
+			if user, ok := users[kubeContext.AuthInfo]; ok {
+				kubeContext.AuthInfo = user
+			}
+			merged.Contexts[renamed] = kubeContext
+			if merged.CurrentContext == "" && config.CurrentContext == name {
+				merged.CurrentContext = renamed
+			}
+		}
+	}
+	return merged, renames, nil
+}
+
+// sortedNames returns the names of a kubeconfig map sorted, so merging renames the same way on every run
+func sortedNames[T any](items map[string]T) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uniqueName returns name when it is not taken, otherwise name with suffix, numbered when that is taken too
+func uniqueName(name string, suffix string, taken func(name string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	candidate := name + suffix
+	for i := 2; taken(candidate); i++ {
+		candidate = fmt.Sprintf("%s%s-%d", name, suffix, i)
+	}
+	return candidate
+}
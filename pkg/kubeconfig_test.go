@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://test-cluster:6443
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: test-context
+current-context: test-context
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+
+// TestLoadKubeConfigExpandsPath tests that ~, environment variables and symlinks in the kubeconfig path are resolved
+func TestLoadKubeConfigExpandsPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".kube", "configs"), 0755))
+	target := filepath.Join(home, ".kube", "configs", "work")
+	require.NoError(t, os.WriteFile(target, []byte(testKubeconfig), 0644))
+	require.NoError(t, os.Symlink(target, filepath.Join(home, ".kube", "config")))
+	t.Setenv("KUBE_DIR", filepath.Join(home, ".kube"))
+
+	for _, path := range []string{"~/.kube/config", "$HOME/.kube/config", "${KUBE_DIR}/config"} {
+		t.Run(path, func(t *testing.T) {
+			config, err := LoadKubeConfig(path)
+			require.NoError(t, err)
+			assert.Equal(t, "test-context", config.CurrentContext)
+
+			client, err := NewK8sClient(path, "", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "https://test-cluster:6443", client.Server())
+		})
+	}
+
+	resolved, err := resolveKubeconfigPath("~/.kube/config")
+	require.NoError(t, err)
+	assert.Equal(t, target, resolved)
+}
+
+// TestLoadKubeConfigMissingOrInvalid tests that a missing kubeconfig is reported apart from one that cannot be parsed
+func TestLoadKubeConfigMissingOrInvalid(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, err := LoadKubeConfig("~/missing")
+	assert.ErrorIs(t, err, ErrKubeconfig)
+	assert.EqualError(t, err, "kubeconfig file "+filepath.Join(home, "missing")+" (expanded from ~/missing) does not exist")
+
+	_, err = LoadKubeConfig(filepath.Join(home, "missing"))
+	assert.EqualError(t, err, "kubeconfig file "+filepath.Join(home, "missing")+" does not exist")
+
+	invalid := filepath.Join(home, "invalid")
+	require.NoError(t, os.WriteFile(invalid, []byte("clusters: [oops"), 0644))
+	_, err = LoadKubeConfig(invalid)
+	assert.ErrorIs(t, err, ErrKubeconfig)
+	assert.ErrorContains(t, err, "failed to load kubeconfig")
+}
+
+// TestLoadKubeConfigMerged tests merging several kubeconfig files, renaming the contexts, clusters
+// and users of later files whose names are taken
+func TestLoadKubeConfigMerged(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name string, server string, contexts ...string) string {
+		content := "apiVersion: v1\nkind: Config\nclusters:\n- cluster:\n    server: " + server + "\n  name: kubernetes\n" +
+			"users:\n- name: admin\n  user:\n    token: " + name + "\ncontexts:\n"
+		for _, context := range contexts {
+			content += "- context:\n    cluster: kubernetes\n    user: admin\n  name: " + context + "\n"
+		}
+		content += "current-context: " + contexts[0] + "\n"
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	main := writeConfig("config", "https://prod:6443", "prod", "dev")
+	oneoff := writeConfig("oneoff.yaml", "https://oneoff:6443", "prod", "oneoff")
+
+	for _, path := range []string{main + "," + oneoff, main + string(os.PathListSeparator) + oneoff} {
+		config, err := LoadKubeConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev", "oneoff", "prod", "prod@oneoff"}, GetContexts(config))
+		assert.Equal(t, "prod", config.CurrentContext)
+		assert.Equal(t, "https://oneoff:6443", clusterServer(config, "prod@oneoff"))
+		assert.Equal(t, "https://oneoff:6443", clusterServer(config, "oneoff"))
+		assert.Equal(t, "https://prod:6443", clusterServer(config, "prod"))
+
+		client, err := NewK8sClient(path, "prod@oneoff", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://oneoff:6443", client.Server())
+	}
+
+	renames, err := KubeconfigRenames(main + "," + oneoff)
+	require.NoError(t, err)
+	resolved, err := filepath.EvalSymlinks(oneoff)
+	require.NoError(t, err)
+	assert.Equal(t, []ContextRename{{File: resolved, Context: "prod", Renamed: "prod@oneoff"}}, renames)
+
+	// A file given twice is loaded once, and a single file is never renamed
+	renames, err = KubeconfigRenames(main + "," + main)
+	require.NoError(t, err)
+	assert.Empty(t, renames)
+	renames, err = KubeconfigRenames(main)
+	require.NoError(t, err)
+	assert.Empty(t, renames)
+
+	_, err = LoadKubeConfig(main + "," + filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, ErrKubeconfig)
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricsCollector records search metrics. Implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveContextSearch records how long searching one context took
+	ObserveContextSearch(contextName string, duration time.Duration)
+	// AddNamespacesScanned records how many namespaces were scanned in a context
+	AddNamespacesScanned(contextName string, count int)
+	// AddMatches records how many pods and services matched in a context
+	AddMatches(contextName string, pods int, services int)
+	// IncPermissionDenied records a namespace skipped because of missing permissions
+	IncPermissionDenied(contextName string)
+}
+
+// NoopMetrics is a MetricsCollector that discards everything, used by the CLI
+type NoopMetrics struct{}
+
+func (NoopMetrics) ObserveContextSearch(string, time.Duration) {}
+func (NoopMetrics) AddNamespacesScanned(string, int)           {}
+func (NoopMetrics) AddMatches(string, int, int)                {}
+func (NoopMetrics) IncPermissionDenied(string)                 {}
+
+var (
+	metricsMu sync.RWMutex
+	metrics   MetricsCollector = NoopMetrics{}
+)
+
+// SetMetricsCollector sets the collector used by the search functions (nil restores the no-op collector)
+func SetMetricsCollector(collector MetricsCollector) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	if collector == nil {
+		collector = NoopMetrics{}
+	}
+	metrics = collector
+}
+
+// getMetrics returns the current metrics collector
+func getMetrics() MetricsCollector {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	return metrics
+}
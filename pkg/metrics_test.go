@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// recordingMetrics is a MetricsCollector that keeps counts for assertions
+type recordingMetrics struct {
+	mu               sync.Mutex
+	permissionDenied map[string]int
+}
+
+func (m *recordingMetrics) ObserveContextSearch(string, time.Duration) {}
+func (m *recordingMetrics) AddNamespacesScanned(string, int)           {}
+func (m *recordingMetrics) AddMatches(string, int, int)                {}
+func (m *recordingMetrics) IncPermissionDenied(contextName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.permissionDenied[contextName]++
+}
+
+// TestPermissionDeniedMetrics tests that forbidden namespaces are recorded per context
+func TestPermissionDeniedMetrics(t *testing.T) {
+	recorder := &recordingMetrics{permissionDenied: map[string]int{}}
+	SetMetricsCollector(recorder)
+	defer SetMetricsCollector(nil)
+
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil)
+	})
+
+	client := &K8sClient{
+		Clientset:   fakeClient,
+		ContextName: "prod",
+		Namespaces:  []string{"kube-system", "default"},
+	}
+
+	pods, err := client.SearchByName(context.Background(), "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+	assert.Equal(t, 2, recorder.permissionDenied["prod"])
+}
@@ -0,0 +1,251 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourcePageSize is the number of custom resources requested per list call
+var resourcePageSize int64 = 500
+
+// errResourceNotServed is returned when a cluster does not serve a resource kind, e.g. its CRD is not installed
+var errResourceNotServed = errors.New("resource kind not served")
+
+// ResourceInfo represents an object of a resource kind given with ParseResourceKind, e.g. a custom resource
+type ResourceInfo struct {
+	UID        string      `json:"uid"`
+	Kind       string      `json:"kind"`
+	APIVersion string      `json:"apiVersion"`
+	Name       string      `json:"name"`
+	Namespace  string      `json:"namespace"`
+	CreatedAt  metav1.Time `json:"createdAt"`
+}
+
+// ResourceResultWithContext represents resource search results with context information
+type ResourceResultWithContext struct {
+	Context   string         `json:"context"`
+	Server    string         `json:"server,omitempty"`
+	Namespace string         `json:"namespace"`
+	Resources []ResourceInfo `json:"resources"`
+}
+
+// ParseResourceKind parses a resource kind given as group/version/kind, e.g. "cert-manager.io/v1/Certificate",
+// or as version/kind for the core group, e.g. "v1/ConfigMap"
+func ParseResourceKind(resource string) (schema.GroupVersionKind, error) {
+	parts := strings.Split(strings.TrimSpace(resource), "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}, nil
+	case 3:
+		return schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}, nil
+	}
+	return schema.GroupVersionKind{}, withKind(ErrInvalidOptions, fmt.Errorf("invalid resource %q (expected group/version/kind, e.g. cert-manager.io/v1/Certificate)", resource))
+}
+
+// ResolveResource finds the namespaced resource serving kind with the discovery client, e.g. certificates
+// for cert-manager.io/v1/Certificate. Kinds are matched case-insensitively.
+func (c *K8sClient) ResolveResource(ctx context.Context, gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	gv := gvk.GroupVersion()
+	var resources *metav1.APIResourceList
+	err := c.withRetry(ctx, func() error {
+		var err error
+		resources, err = c.Clientset.Discovery().ServerResourcesForGroupVersion(gv.String())
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return schema.GroupVersionResource{}, fmt.Errorf("%w: %s is not served", errResourceNotServed, gv)
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to discover the resources of %s: %w", gv, err)
+	}
+
+	for _, resource := range resources.APIResources {
+		// Subresources like certificates/status share the kind of their resource
+		if strings.Contains(resource.Name, "/") || !strings.EqualFold(resource.Kind, gvk.Kind) {
+			continue
+		}
+		if !resource.Namespaced {
+			return schema.GroupVersionResource{}, withKind(ErrInvalidOptions, fmt.Errorf("%s is cluster-scoped; only namespaced resources can be searched", gvk.Kind))
+		}
+		return gv.WithResource(resource.Name), nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("%w: %s has no kind %s", errResourceNotServed, gv, gvk.Kind)
+}
+
+// SearchResources searches for objects of a resource kind by name (partial match unless Options.ExactName is set)
+// using the dynamic client. A cluster that does not serve the kind has none.
+func (c *K8sClient) SearchResources(ctx context.Context, gvk schema.GroupVersionKind, name string) ([]ResourceInfo, error) {
+	gvr, err := c.ResolveResource(ctx, gvk)
+	if errors.Is(err, errResourceNotServed) {
+		return []ResourceInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.searchResources(ctx, gvr, name)
+}
+
+// searchResources lists the objects of a resolved resource in each namespace, page by page,
+// and keeps those whose name matches name
+func (c *K8sClient) searchResources(ctx context.Context, gvr schema.GroupVersionResource, name string) ([]ResourceInfo, error) {
+	resources := []ResourceInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		options := metav1.ListOptions{Limit: resourcePageSize}
+		for {
+			var list *unstructured.UnstructuredList
+			err := c.withRetry(ctx, func() error {
+				var err error
+				list, err = c.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, options)
+				return err
+			})
+			if err != nil {
+				// Skip silently if permission denied
+				if isPermissionError(err) {
+					getMetrics().IncPermissionDenied(c.ContextName)
+					break
+				}
+				return nil, fmt.Errorf("failed to list %s in namespace %s: %w", gvr.Resource, namespace, err)
+			}
+
+			for _, item := range list.Items {
+				if c.Options.matchesName(item.GetName(), name) {
+					resources = append(resources, ResourceInfo{
+						UID:        string(item.GetUID()),
+						Kind:       item.GetKind(),
+						APIVersion: item.GetAPIVersion(),
+						Name:       item.GetName(),
+						Namespace:  item.GetNamespace(),
+						CreatedAt:  item.GetCreationTimestamp(),
+					})
+				}
+			}
+
+			if list.GetContinue() == "" {
+				break
+			}
+			options.Continue = list.GetContinue()
+		}
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Namespace != resources[j].Namespace {
+			return resources[i].Namespace < resources[j].Namespace
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	resources = resources[:newResultLimit(c.Options.MaxResults, nil).take(len(resources))]
+	return resources, nil
+}
+
+// SearchResourcesAllContexts searches for objects of a resource kind by name across all (or specified) contexts
+// and all (or specified) namespaces. The kind is resolved once per context; contexts whose cluster does not serve
+// it are skipped.
+func SearchResourcesAllContexts(ctx context.Context, kubeconfigPath string, gvk schema.GroupVersionKind, name string, namespaces []string, contexts []string, opts SearchOptions) ([]ResourceResultWithContext, error) {
+	collected, ctx, cancel := newCollector[ResourceResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+	// Guards resolved, used from concurrent namespace searches
+	var mu sync.Mutex
+	resolved := map[string]schema.GroupVersionResource{}
+
+	err := forEachNamespace(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		mu.Lock()
+		gvr, ok := resolved[contextName]
+		mu.Unlock()
+		if !ok {
+			var err error
+			if gvr, err = client.ResolveResource(ctx, gvk); err != nil {
+				return errors.Is(err, errResourceNotServed), ignoreNotServed(err)
+			}
+			mu.Lock()
+			resolved[contextName] = gvr
+			mu.Unlock()
+		}
+
+		resources, err := client.searchResources(ctx, gvr, name)
+		if err != nil {
+			return false, err
+		}
+		resources = resources[:collected.limit.take(len(resources))]
+
+		// Only add results if found something
+		if len(resources) > 0 {
+			collected.add(ResourceResultWithContext{
+				Context:   contextName,
+				Server:    client.Server(),
+				Namespace: namespace,
+				Resources: resources,
+			})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	return results, nil
+}
+
+// ignoreNotServed drops errResourceNotServed, which only means a cluster has nothing to search
+func ignoreNotServed(err error) error {
+	if errors.Is(err, errResourceNotServed) {
+		return nil
+	}
+	return err
+}
+
+// DedupResourceResults removes resources already reported by another context pointing at the same cluster
+func DedupResourceResults(results []ResourceResultWithContext) []ResourceResultWithContext {
+	seen := map[string]bool{}
+	deduped := []ResourceResultWithContext{}
+
+	for _, result := range results {
+		// Results of a context without a known server cannot be told apart from other clusters, so they are all kept
+		if result.Server == "" {
+			deduped = append(deduped, result)
+			continue
+		}
+		server := result.Server
+
+		resources := []ResourceInfo{}
+		for _, resource := range result.Resources {
+			key := fmt.Sprintf("%s/%s/%s/%s", server, resource.Kind, resource.Namespace, resource.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			resources = append(resources, resource)
+		}
+
+		if len(resources) > 0 {
+			result.Resources = resources
+			deduped = append(deduped, result)
+		}
+	}
+
+	return deduped
+}
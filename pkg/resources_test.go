@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// certificate builds a cert-manager Certificate as an unstructured object
+func certificate(namespace, name string) *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	certificate.SetNamespace(namespace)
+	certificate.SetName(name)
+	return certificate
+}
+
+// certManagerResources are the discovery resources of cert-manager.io/v1
+var certManagerResources = &metav1.APIResourceList{
+	GroupVersion: "cert-manager.io/v1",
+	APIResources: []metav1.APIResource{
+		{Name: "certificates", Kind: "Certificate", Namespaced: true},
+		{Name: "certificates/status", Kind: "Certificate", Namespaced: true},
+		{Name: "clusterissuers", Kind: "ClusterIssuer"},
+	},
+}
+
+// TestParseResourceKind tests parsing group/version/kind and version/kind resources
+func TestParseResourceKind(t *testing.T) {
+	gvk, err := ParseResourceKind("cert-manager.io/v1/Certificate")
+	require.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}, gvk)
+
+	gvk, err = ParseResourceKind("v1/ConfigMap")
+	require.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, gvk)
+
+	for _, resource := range []string{"Certificate", "cert-manager.io//Certificate", "a/b/c/d", ""} {
+		_, err := ParseResourceKind(resource)
+		assert.ErrorIs(t, err, ErrInvalidOptions, resource)
+	}
+}
+
+// TestSearchResources tests searching custom resources by name with the discovery and dynamic clients
+func TestSearchResources(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.Resources = []*metav1.APIResourceList{certManagerResources}
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CertificateList"},
+		certificate("default", "web-tls"),
+		certificate("default", "api-tls"),
+		certificate("payments", "web-tls-legacy"),
+	)
+	client := &K8sClient{Clientset: fakeClient, Dynamic: dynamicClient, Namespaces: []string{"default", "payments"}}
+	ctx := context.Background()
+
+	resources, err := client.SearchResources(ctx, schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "certificate"}, "web")
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "web-tls", resources[0].Name)
+	assert.Equal(t, "Certificate", resources[0].Kind)
+	assert.Equal(t, "cert-manager.io/v1", resources[0].APIVersion)
+	assert.Equal(t, "payments", resources[1].Namespace)
+
+	// Kinds the cluster does not serve have no objects
+	resources, err = client.SearchResources(ctx, schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1", Kind: "VirtualService"}, "web")
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+	resources, err = client.SearchResources(ctx, schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Issuer"}, "web")
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	// Cluster-scoped kinds cannot be searched by namespace
+	_, err = client.SearchResources(ctx, schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer"}, "web")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+// TestSearchResourcesAllContexts tests resolving the resource once per context and listing it in each namespace
+func TestSearchResourcesAllContexts(t *testing.T) {
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/apis/cert-manager.io/v1":
+			_, _ = w.Write([]byte(`{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"cert-manager.io/v1",` +
+				`"resources":[{"name":"certificates","singularName":"certificate","namespaced":true,"kind":"Certificate","verbs":["list"]}]}`))
+		case strings.HasSuffix(r.URL.Path, "/certificates"):
+			namespace := strings.Split(r.URL.Path, "/")[5]
+			_, _ = w.Write([]byte(`{"kind":"CertificateList","apiVersion":"cert-manager.io/v1","metadata":{},"items":[` +
+				`{"apiVersion":"cert-manager.io/v1","kind":"Certificate","metadata":{"name":"web-tls","namespace":"` + namespace + `"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+
+	kubeconfigPath := writeServerKubeconfig(t, server.URL)
+	ctx := context.Background()
+
+	gvk := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	results, err := SearchResourcesAllContexts(ctx, kubeconfigPath, gvk, "web", []string{"a", "b"}, []string{"prod"}, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Namespace)
+	assert.Equal(t, "web-tls", results[1].Resources[0].Name)
+	assert.Equal(t, []string{"/apis/cert-manager.io/v1", "/apis/cert-manager.io/v1/namespaces/a/certificates", "/apis/cert-manager.io/v1/namespaces/b/certificates"}, requested)
+
+	// A cluster without the CRD is skipped after a single discovery request
+	requested = []string{}
+	gvk = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1", Kind: "VirtualService"}
+	results, err = SearchResourcesAllContexts(ctx, kubeconfigPath, gvk, "web", []string{"a", "b"}, []string{"prod"}, SearchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, []string{"/apis/networking.istio.io/v1"}, requested)
+}
@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// defaultRetryBaseDelay is the delay before the first retry, doubled for every following retry
+const defaultRetryBaseDelay = 250 * time.Millisecond
+
+// Backoff settings for retried API calls (variables so tests can shorten them)
+var (
+	retryBaseDelay = defaultRetryBaseDelay
+	retryMaxDelay  = 5 * time.Second
+	// throttleRetries is the number of times a throttled request is retried, on top of Options.Retries
+	throttleRetries = 5
+	// throttleMaxDelay caps the wait asked for by a Retry-After header
+	throttleMaxDelay = 30 * time.Second
+)
+
+// withRetry calls fn and retries transient errors with exponential backoff.
+// Permission and other non-transient errors are returned immediately, permission errors tagged with ErrNoAccess.
+// Throttled requests (429 Too Many Requests) are retried after the delay the API server asks for,
+// with a budget of their own, and lower the number of namespaces searched at once.
+// Every attempt counts as one API call.
+func (c *K8sClient) withRetry(ctx context.Context, fn func() error) error {
+	delay := retryBaseDelay
+	retries, throttled := 0, 0
+	for {
+		c.countAPICall()
+		err := fn()
+		if isPermissionError(err) {
+			return withKind(ErrNoAccess, err)
+		}
+
+		wait := delay
+		switch {
+		case err == nil:
+			return nil
+		case apierrors.IsTooManyRequests(err):
+			c.namespaceLimit.throttle()
+			if throttled >= throttleRetries {
+				return err
+			}
+			throttled++
+			wait = throttleDelay(err, delay)
+		case retries >= c.Options.Retries || !IsTransientError(err):
+			return err
+		default:
+			retries++
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
+
+// throttleDelay returns how long to wait before retrying a throttled request: the Retry-After
+// delay of the response when set (capped at throttleMaxDelay), else the backoff delay
+func throttleDelay(err error, backoff time.Duration) time.Duration {
+	seconds, ok := apierrors.SuggestsClientDelay(err)
+	if !ok || seconds <= 0 {
+		return backoff
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > throttleMaxDelay {
+		delay = throttleMaxDelay
+	}
+	return delay
+}
+
+// IsTransientError checks if an error is likely to succeed on retry (network failures, timeouts, throttling)
+func IsTransientError(err error) bool {
+	if err == nil || isPermissionError(err) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// Some failures (e.g. TLS handshake timeouts) are only reported as text
+	message := err.Error()
+	for _, fragment := range []string{"connection refused", "connection reset", "TLS handshake timeout", "i/o timeout"} {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
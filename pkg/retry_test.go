@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestIsTransientError tests classifying errors as retryable
+func TestIsTransientError(t *testing.T) {
+	podsResource := schema.GroupResource{Resource: "pods"}
+
+	assert.True(t, IsTransientError(apierrors.NewServiceUnavailable("overloaded")))
+	assert.True(t, IsTransientError(apierrors.NewTooManyRequests("slow down", 1)))
+	assert.True(t, IsTransientError(fmt.Errorf("list pods: %w", syscall.ECONNREFUSED)))
+	assert.True(t, IsTransientError(errors.New("net/http: TLS handshake timeout")))
+
+	assert.False(t, IsTransientError(nil))
+	assert.False(t, IsTransientError(apierrors.NewForbidden(podsResource, "", nil)))
+	assert.False(t, IsTransientError(apierrors.NewNotFound(podsResource, "nginx")))
+	assert.False(t, IsTransientError(context.Canceled))
+}
+
+// TestSearchRetries tests that transient list errors are retried and permission errors are not
+func TestSearchRetries(t *testing.T) {
+	retryBaseDelay = 0
+	defer func() { retryBaseDelay = defaultRetryBaseDelay }()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"},
+	}
+
+	// Fails twice before succeeding
+	fakeClient := fake.NewSimpleClientset(pod)
+	calls := 0
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls <= 2 {
+			return true, nil, apierrors.NewServiceUnavailable("overloaded")
+		}
+		return false, nil, nil
+	})
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default"},
+		Options:    SearchOptions{Retries: 2},
+		apiCalls:   new(int64),
+	}
+
+	pods, err := client.SearchByName(context.Background(), "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, 3, calls)
+	// Every attempt counts as an API call
+	assert.Equal(t, int64(3), client.APICalls())
+
+	// Retries exhausted
+	calls = 0
+	client.Options.Retries = 1
+	_, err = client.SearchByName(context.Background(), "nginx")
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Permission errors are skipped without retrying
+	forbiddenClient := fake.NewSimpleClientset()
+	forbiddenCalls := 0
+	forbiddenClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		forbiddenCalls++
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil)
+	})
+	client.Clientset = forbiddenClient
+	client.Options.Retries = 3
+
+	pods, err = client.SearchByName(context.Background(), "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+	assert.Equal(t, 1, forbiddenCalls)
+}
+
+// TestSearchThrottled tests that throttled requests are retried even without --retries and lower the namespace concurrency
+func TestSearchThrottled(t *testing.T) {
+	retryBaseDelay = 0
+	defer func() { retryBaseDelay = defaultRetryBaseDelay }()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"},
+	}
+
+	// Throttled three times before succeeding
+	fakeClient := fake.NewSimpleClientset(pod)
+	calls := 0
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls <= 3 {
+			return true, nil, apierrors.NewTooManyRequests("slow down", 0)
+		}
+		return false, nil, nil
+	})
+
+	client := &K8sClient{
+		Clientset:      fakeClient,
+		Namespaces:     []string{"default"},
+		namespaceLimit: newConcurrencyLimit(8),
+	}
+
+	pods, err := client.SearchByName(context.Background(), "nginx")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 1, client.namespaceLimit.current())
+
+	// Throttled for good
+	calls = 0
+	fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		return true, nil, apierrors.NewTooManyRequests("slow down", 0)
+	})
+	_, err = client.SearchByName(context.Background(), "nginx")
+	assert.True(t, apierrors.IsTooManyRequests(err))
+	assert.Equal(t, throttleRetries+1, calls)
+}
+
+// TestThrottleDelay tests that the Retry-After delay of a throttled response is honored and capped
+func TestThrottleDelay(t *testing.T) {
+	assert.Equal(t, 3*time.Second, throttleDelay(apierrors.NewTooManyRequests("slow down", 3), time.Second))
+	assert.Equal(t, throttleMaxDelay, throttleDelay(apierrors.NewTooManyRequests("slow down", 600), time.Second))
+	assert.Equal(t, time.Second, throttleDelay(apierrors.NewTooManyRequests("slow down", 0), time.Second))
+}
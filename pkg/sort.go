@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// SortKeys are the columns results can be sorted by
+var SortKeys = []string{"podip", "name", "namespace", "age", "restarts"}
+
+// ResultSort orders the pods and services of each result by a column instead of the default order.
+// The zero value keeps the default order.
+type ResultSort struct {
+	// Key is one of SortKeys
+	Key string
+	// Descending reverses the order, e.g. oldest pods first for age
+	Descending bool
+}
+
+// ParseResultSort parses a sort key such as "podip" or "-restarts", where a leading "-" sorts descending.
+// An empty key keeps the default order.
+func ParseResultSort(s string) (ResultSort, error) {
+	var result ResultSort
+	key := strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(key, "-") {
+		result.Descending = true
+		key = key[1:]
+	}
+	if key == "" {
+		if result.Descending {
+			return ResultSort{}, fmt.Errorf("%w: sort key %q has no column", ErrInvalidOptions, s)
+		}
+		return result, nil
+	}
+	for _, valid := range SortKeys {
+		if key == valid {
+			result.Key = key
+			return result, nil
+		}
+	}
+	return ResultSort{}, fmt.Errorf("%w: unknown sort key %q, expected one of %s", ErrInvalidOptions, s, strings.Join(SortKeys, ", "))
+}
+
+// SortIPResults sorts the pods and services of each result, leaving the results in their order
+func (s ResultSort) SortIPResults(results []SearchResultWithContext) {
+	for _, result := range results {
+		s.SortPods(result.Pods)
+		s.SortServices(result.Services)
+	}
+}
+
+// SortPodResults sorts the pods of each result, leaving the results in their order
+func (s ResultSort) SortPodResults(results []PodResultWithContext) {
+	for _, result := range results {
+		s.SortPods(result.Pods)
+	}
+}
+
+// SortPods stably sorts pods by the key: podip by address, age youngest first, restarts by the
+// restarts of all containers. Pods equal on the key keep their order.
+func (s ResultSort) SortPods(pods []PodInfo) {
+	var compare func(a, b PodInfo) int
+	switch s.Key {
+	case "podip":
+		compare = func(a, b PodInfo) int { return compareIPs(a.PodIP, b.PodIP) }
+	case "name":
+		compare = func(a, b PodInfo) int { return strings.Compare(a.Name, b.Name) }
+	case "namespace":
+		compare = func(a, b PodInfo) int { return strings.Compare(a.Namespace, b.Namespace) }
+	case "age":
+		compare = func(a, b PodInfo) int { return b.CreatedAt.Time.Compare(a.CreatedAt.Time) }
+	case "restarts":
+		compare = func(a, b PodInfo) int { return podRestarts(a) - podRestarts(b) }
+	default:
+		return
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		if s.Descending {
+			return compare(pods[i], pods[j]) > 0
+		}
+		return compare(pods[i], pods[j]) < 0
+	})
+}
+
+// SortServices stably sorts services by the key, podip comparing their cluster IPs.
+// Services have no restarts, so sorting by restarts keeps their order.
+func (s ResultSort) SortServices(services []ServiceInfo) {
+	var compare func(a, b ServiceInfo) int
+	switch s.Key {
+	case "podip":
+		compare = func(a, b ServiceInfo) int { return compareIPs(a.ClusterIP, b.ClusterIP) }
+	case "name":
+		compare = func(a, b ServiceInfo) int { return strings.Compare(a.Name, b.Name) }
+	case "namespace":
+		compare = func(a, b ServiceInfo) int { return strings.Compare(a.Namespace, b.Namespace) }
+	case "age":
+		compare = func(a, b ServiceInfo) int { return b.CreatedAt.Time.Compare(a.CreatedAt.Time) }
+	default:
+		return
+	}
+	sort.SliceStable(services, func(i, j int) bool {
+		if s.Descending {
+			return compare(services[i], services[j]) > 0
+		}
+		return compare(services[i], services[j]) < 0
+	})
+}
+
+// podRestarts returns the restarts of all containers of a pod
+func podRestarts(pod PodInfo) int {
+	restarts := 0
+	for _, container := range pod.Containers {
+		restarts += int(container.RestartCount)
+	}
+	return restarts
+}
+
+// compareIPs compares addresses numerically, so 10.0.0.9 comes before 10.0.0.10.
+// Addresses that are not IPs, e.g. of pending pods or headless services, come after all IPs.
+func compareIPs(a, b string) int {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	switch {
+	case ipA == nil && ipB == nil:
+		return strings.Compare(a, b)
+	case ipA == nil:
+		return 1
+	case ipB == nil:
+		return -1
+	}
+	return bytes.Compare(ipA.To16(), ipB.To16())
+}
@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestParseResultSort tests parsing sort keys with an optional "-" for descending order
+func TestParseResultSort(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ResultSort
+		valid    bool
+	}{
+		{"", ResultSort{}, true},
+		{"podip", ResultSort{Key: "podip"}, true},
+		{"-Restarts", ResultSort{Key: "restarts", Descending: true}, true},
+		{"-", ResultSort{}, false},
+		{"ip", ResultSort{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseResultSort(tt.input)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestSortPods tests sorting pods by each key, numerically for IPs and stably for equal keys
+func TestSortPods(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fixture := func() []PodInfo {
+		return []PodInfo{
+			{Name: "web-b", Namespace: "shop", PodIP: "10.0.0.10", CreatedAt: metav1.NewTime(now.Add(-time.Hour)),
+				Containers: []ContainerInfo{{RestartCount: 2}, {RestartCount: 3}}},
+			{Name: "pending", Namespace: "default", CreatedAt: metav1.NewTime(now)},
+			{Name: "web-a", Namespace: "shop", PodIP: "10.0.0.9", CreatedAt: metav1.NewTime(now.Add(-2 * time.Hour)),
+				Containers: []ContainerInfo{{RestartCount: 1}}},
+		}
+	}
+	names := func(pods []PodInfo) []string {
+		result := []string{}
+		for _, pod := range pods {
+			result = append(result, pod.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		sort     ResultSort
+		expected []string
+	}{
+		{ResultSort{}, []string{"web-b", "pending", "web-a"}},
+		{ResultSort{Key: "podip"}, []string{"web-a", "web-b", "pending"}},
+		{ResultSort{Key: "podip", Descending: true}, []string{"pending", "web-b", "web-a"}},
+		{ResultSort{Key: "name"}, []string{"pending", "web-a", "web-b"}},
+		{ResultSort{Key: "namespace"}, []string{"pending", "web-b", "web-a"}},
+		{ResultSort{Key: "age"}, []string{"pending", "web-b", "web-a"}},
+		{ResultSort{Key: "age", Descending: true}, []string{"web-a", "web-b", "pending"}},
+		{ResultSort{Key: "restarts", Descending: true}, []string{"web-b", "web-a", "pending"}},
+	}
+
+	for _, tt := range tests {
+		pods := fixture()
+		tt.sort.SortPods(pods)
+		assert.Equal(t, tt.expected, names(pods), "sort %+v", tt.sort)
+	}
+}
+
+// TestSortIPResults tests sorting the services of IP results by cluster IP, headless services last
+func TestSortIPResults(t *testing.T) {
+	results := []SearchResultWithContext{{Context: "prod", Namespace: "default", Services: []ServiceInfo{
+		{Name: "headless", ClusterIP: "None"},
+		{Name: "web", ClusterIP: "10.96.0.10"},
+		{Name: "api", ClusterIP: "10.96.0.2"},
+	}}}
+
+	ResultSort{Key: "podip"}.SortIPResults(results)
+	assert.Equal(t, "api", results[0].Services[0].Name)
+	assert.Equal(t, "web", results[0].Services[1].Name)
+	assert.Equal(t, "headless", results[0].Services[2].Name)
+
+	// Services have no restarts and keep their order
+	ResultSort{Key: "restarts"}.SortIPResults(results)
+	assert.Equal(t, "api", results[0].Services[0].Name)
+}
@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HealthStatus is the outcome of one check of a context's health
+type HealthStatus string
+
+// Health check outcomes; a check is not run once an earlier one failed
+const (
+	HealthOK         HealthStatus = "ok"
+	HealthFailed     HealthStatus = "failed"
+	HealthNotChecked HealthStatus = "not checked"
+)
+
+// ContextHealth reports whether a context can be searched: its client config can be built,
+// its cluster answers, the credentials are accepted and namespaces can be listed
+type ContextHealth struct {
+	Context       string       `json:"context"`
+	Server        string       `json:"server,omitempty"`
+	Config        HealthStatus `json:"config"`
+	Reachable     HealthStatus `json:"reachable"`
+	Authenticated HealthStatus `json:"authenticated"`
+	Authorized    HealthStatus `json:"authorized"`
+	// Error explains the first failed check
+	Error string `json:"error,omitempty"`
+}
+
+// Healthy reports whether every check passed
+func (h ContextHealth) Healthy() bool {
+	return h.Config == HealthOK && h.Reachable == HealthOK && h.Authenticated == HealthOK && h.Authorized == HealthOK
+}
+
+// CheckHealth checks that the cluster of the client's context answers a discovery ping within
+// timeout (0 = no timeout besides ctx) and that the credentials can list namespaces
+func (c *K8sClient) CheckHealth(ctx context.Context, timeout time.Duration) ContextHealth {
+	health := ContextHealth{
+		Context:       c.ContextName,
+		Server:        c.Server(),
+		Config:        HealthOK,
+		Reachable:     HealthNotChecked,
+		Authenticated: HealthNotChecked,
+		Authorized:    HealthNotChecked,
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// /version is readable without credentials on most clusters, so it only tells whether the cluster answers
+	if restClient := c.Clientset.Discovery().RESTClient(); restClient != nil {
+		c.countAPICall()
+		err := restClient.Get().AbsPath("/version").Do(ctx).Error()
+		if err != nil && !isPermissionError(err) {
+			health.Reachable = HealthFailed
+			health.Error = err.Error()
+			return health
+		}
+	}
+	health.Reachable = HealthOK
+
+	// Unauthorized means the credentials were rejected, forbidden that they were accepted without the right to list
+	c.countAPICall()
+	_, err := c.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	switch {
+	case err == nil:
+		health.Authenticated = HealthOK
+		health.Authorized = HealthOK
+	case apierrors.IsUnauthorized(err):
+		health.Authenticated = HealthFailed
+		health.Error = err.Error()
+	case apierrors.IsForbidden(err):
+		health.Authenticated = HealthOK
+		health.Authorized = HealthFailed
+		health.Error = err.Error()
+	default:
+		health.Reachable = HealthFailed
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// ValidateContexts checks the health of all (or specified) contexts, ContextConcurrency at a time,
+// each check bounded by PrecheckTimeout. Results are sorted by context name.
+func ValidateContexts(ctx context.Context, kubeconfigPath string, contexts []string, opts SearchOptions) ([]ContextHealth, error) {
+	config, err := LoadKubeConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	contexts, err = SelectContexts(config, contexts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ContextHealth, len(contexts))
+	var mu sync.Mutex
+	runLimited(ctx, len(contexts), newConcurrencyLimit(opts.ContextConcurrency), func(i int) {
+		health := ContextHealth{
+			Context:       contexts[i],
+			Server:        clusterServer(config, contexts[i]),
+			Config:        HealthFailed,
+			Reachable:     HealthNotChecked,
+			Authenticated: HealthNotChecked,
+			Authorized:    HealthNotChecked,
+		}
+		client, err := NewK8sClientWithOptions(kubeconfigPath, contexts[i], []string{}, opts)
+		if err != nil {
+			health.Error = err.Error()
+		} else {
+			health = client.CheckHealth(ctx, opts.PrecheckTimeout)
+		}
+
+		mu.Lock()
+		results[i] = health
+		mu.Unlock()
+	})
+
+	// Contexts left unchecked by a cancelled ctx have no name
+	checked := []ContextHealth{}
+	for _, health := range results {
+		if health.Context != "" {
+			checked = append(checked, health)
+		}
+	}
+	sort.SliceStable(checked, func(i, j int) bool {
+		return checked[i].Context < checked[j].Context
+	})
+	return checked, nil
+}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateContexts tests that each failing check of a context is reported
+func TestValidateContexts(t *testing.T) {
+	serve := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/version" {
+				_, _ = w.Write([]byte(`{"major":"1","minor":"34"}`))
+				return
+			}
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"` + http.StatusText(status) + `","code":` + strconv.Itoa(status) + `}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[]}`))
+		}))
+	}
+	healthy := serve(http.StatusOK)
+	defer healthy.Close()
+	unauthorized := serve(http.StatusUnauthorized)
+	defer unauthorized.Close()
+	forbidden := serve(http.StatusForbidden)
+	defer forbidden.Close()
+	dead := serve(http.StatusOK)
+	dead.Close()
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfigContent := "apiVersion: v1\nkind: Config\nclusters:\n"
+	for name, server := range map[string]string{"healthy": healthy.URL, "unauthorized": unauthorized.URL, "forbidden": forbidden.URL, "dead": dead.URL} {
+		kubeconfigContent += "- cluster:\n    server: " + server + "\n  name: " + name + "\n"
+	}
+	kubeconfigContent += "contexts:\n"
+	for _, name := range []string{"healthy", "unauthorized", "forbidden", "dead"} {
+		kubeconfigContent += "- context:\n    cluster: " + name + "\n    user: test-user\n  name: " + name + "\n"
+	}
+	kubeconfigContent += "- context:\n    cluster: missing\n    user: test-user\n  name: broken\n"
+	kubeconfigContent += "current-context: healthy\nusers:\n- name: test-user\n  user:\n    token: test-token\n"
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfigContent), 0644))
+
+	results, err := ValidateContexts(context.Background(), kubeconfigPath, nil, SearchOptions{ContextConcurrency: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	byContext := map[string]ContextHealth{}
+	for _, health := range results {
+		byContext[health.Context] = health
+	}
+	assert.Equal(t, "broken", results[0].Context)
+
+	assert.True(t, byContext["healthy"].Healthy())
+	assert.Equal(t, healthy.URL, byContext["healthy"].Server)
+	assert.Empty(t, byContext["healthy"].Error)
+
+	assert.Equal(t, HealthFailed, byContext["broken"].Config)
+	assert.Equal(t, HealthNotChecked, byContext["broken"].Reachable)
+
+	assert.Equal(t, HealthFailed, byContext["dead"].Reachable)
+	assert.Equal(t, HealthNotChecked, byContext["dead"].Authenticated)
+
+	assert.Equal(t, HealthOK, byContext["unauthorized"].Reachable)
+	assert.Equal(t, HealthFailed, byContext["unauthorized"].Authenticated)
+	assert.Equal(t, HealthNotChecked, byContext["unauthorized"].Authorized)
+
+	assert.Equal(t, HealthOK, byContext["forbidden"].Authenticated)
+	assert.Equal(t, HealthFailed, byContext["forbidden"].Authorized)
+	assert.NotEmpty(t, byContext["forbidden"].Error)
+
+	// Unknown contexts fail before any check
+	_, err = ValidateContexts(context.Background(), kubeconfigPath, []string{"nope"}, SearchOptions{})
+	assert.ErrorIs(t, err, ErrKubeconfig)
+}
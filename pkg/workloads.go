@@ -0,0 +1,312 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload kinds a name search can return instead of pods
+const (
+	WorkloadDeployment  = "Deployment"
+	WorkloadStatefulSet = "StatefulSet"
+	WorkloadDaemonSet   = "DaemonSet"
+)
+
+// workloadKindNames maps the lowercase names accepted for each workload kind, including plurals and short names
+var workloadKindNames = map[string]string{
+	"deployment":   WorkloadDeployment,
+	"deployments":  WorkloadDeployment,
+	"deploy":       WorkloadDeployment,
+	"statefulset":  WorkloadStatefulSet,
+	"statefulsets": WorkloadStatefulSet,
+	"sts":          WorkloadStatefulSet,
+	"daemonset":    WorkloadDaemonSet,
+	"daemonsets":   WorkloadDaemonSet,
+	"ds":           WorkloadDaemonSet,
+}
+
+// ParseWorkloadKind returns the workload kind named by kind, e.g. "deploy" or "StatefulSets"
+func ParseWorkloadKind(kind string) (string, error) {
+	workloadKind, ok := workloadKindNames[strings.ToLower(strings.TrimSpace(kind))]
+	if !ok {
+		return "", withKind(ErrInvalidOptions, fmt.Errorf("unsupported workload kind %q (supported: deployment, statefulset, daemonset)", kind))
+	}
+	return workloadKind, nil
+}
+
+// IsServiceKind reports whether kind names services ("service", "services" or "svc"),
+// which narrows IP searches to services instead of searching workloads
+func IsServiceKind(kind string) bool {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "service", "services", "svc":
+		return true
+	}
+	return false
+}
+
+// WorkloadInfo represents a Deployment, StatefulSet or DaemonSet and the state of its replicas
+type WorkloadInfo struct {
+	UID       string `json:"uid"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Desired is the number of replicas wanted, or the number of nodes a DaemonSet should run on
+	Desired   int32       `json:"desired"`
+	Ready     int32       `json:"ready"`
+	UpToDate  int32       `json:"upToDate"`
+	Available int32       `json:"available"`
+	Images    []string    `json:"images,omitempty"`
+	CreatedAt metav1.Time `json:"createdAt"`
+}
+
+// WorkloadResultWithContext represents workload search results with context information
+type WorkloadResultWithContext struct {
+	Context   string         `json:"context"`
+	Server    string         `json:"server,omitempty"`
+	Namespace string         `json:"namespace"`
+	Workloads []WorkloadInfo `json:"workloads"`
+}
+
+// GetDeployments searches for Deployments by name (partial match unless Options.ExactName is set)
+func (c *K8sClient) GetDeployments(ctx context.Context, name string) ([]WorkloadInfo, error) {
+	return c.searchWorkloads(ctx, WorkloadDeployment, name, func(namespace string) ([]WorkloadInfo, error) {
+		list, err := c.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		workloads := make([]WorkloadInfo, 0, len(list.Items))
+		for i := range list.Items {
+			workloads = append(workloads, newDeploymentInfo(&list.Items[i]))
+		}
+		return workloads, nil
+	})
+}
+
+// GetStatefulSets searches for StatefulSets by name (partial match unless Options.ExactName is set)
+func (c *K8sClient) GetStatefulSets(ctx context.Context, name string) ([]WorkloadInfo, error) {
+	return c.searchWorkloads(ctx, WorkloadStatefulSet, name, func(namespace string) ([]WorkloadInfo, error) {
+		list, err := c.Clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		workloads := make([]WorkloadInfo, 0, len(list.Items))
+		for i := range list.Items {
+			workloads = append(workloads, newStatefulSetInfo(&list.Items[i]))
+		}
+		return workloads, nil
+	})
+}
+
+// GetDaemonSets searches for DaemonSets by name (partial match unless Options.ExactName is set)
+func (c *K8sClient) GetDaemonSets(ctx context.Context, name string) ([]WorkloadInfo, error) {
+	return c.searchWorkloads(ctx, WorkloadDaemonSet, name, func(namespace string) ([]WorkloadInfo, error) {
+		list, err := c.Clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		workloads := make([]WorkloadInfo, 0, len(list.Items))
+		for i := range list.Items {
+			workloads = append(workloads, newDaemonSetInfo(&list.Items[i]))
+		}
+		return workloads, nil
+	})
+}
+
+// SearchWorkloads searches for workloads of a kind returned by ParseWorkloadKind by name
+func (c *K8sClient) SearchWorkloads(ctx context.Context, kind string, name string) ([]WorkloadInfo, error) {
+	switch kind {
+	case WorkloadDeployment:
+		return c.GetDeployments(ctx, name)
+	case WorkloadStatefulSet:
+		return c.GetStatefulSets(ctx, name)
+	case WorkloadDaemonSet:
+		return c.GetDaemonSets(ctx, name)
+	}
+	return nil, withKind(ErrInvalidOptions, fmt.Errorf("unsupported workload kind %q", kind))
+}
+
+// searchWorkloads lists the workloads of each namespace with list and keeps those whose name matches name
+func (c *K8sClient) searchWorkloads(ctx context.Context, kind string, name string, list func(namespace string) ([]WorkloadInfo, error)) ([]WorkloadInfo, error) {
+	workloads := []WorkloadInfo{}
+
+	// Search in all specified namespaces
+	for _, namespace := range c.Namespaces {
+		var items []WorkloadInfo
+		err := c.withRetry(ctx, func() error {
+			var err error
+			items, err = list(namespace)
+			return err
+		})
+		if err != nil {
+			// Skip silently if permission denied
+			if isPermissionError(err) {
+				getMetrics().IncPermissionDenied(c.ContextName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %ss in namespace %s: %w", strings.ToLower(kind), namespace, err)
+		}
+
+		for _, workload := range items {
+			if c.Options.matchesName(workload.Name, name) {
+				workloads = append(workloads, workload)
+			}
+		}
+	}
+
+	sortWorkloads(workloads)
+	workloads = workloads[:newResultLimit(c.Options.MaxResults, nil).take(len(workloads))]
+	return workloads, nil
+}
+
+// newDeploymentInfo converts a Deployment into WorkloadInfo
+func newDeploymentInfo(deployment *appsv1.Deployment) WorkloadInfo {
+	// An unset replica count defaults to 1
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	return WorkloadInfo{
+		UID:       string(deployment.UID),
+		Kind:      WorkloadDeployment,
+		Name:      deployment.Name,
+		Namespace: deployment.Namespace,
+		Desired:   desired,
+		Ready:     deployment.Status.ReadyReplicas,
+		UpToDate:  deployment.Status.UpdatedReplicas,
+		Available: deployment.Status.AvailableReplicas,
+		Images:    containerImages(deployment.Spec.Template.Spec),
+		CreatedAt: deployment.CreationTimestamp,
+	}
+}
+
+// newStatefulSetInfo converts a StatefulSet into WorkloadInfo
+func newStatefulSetInfo(statefulSet *appsv1.StatefulSet) WorkloadInfo {
+	// An unset replica count defaults to 1
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	return WorkloadInfo{
+		UID:       string(statefulSet.UID),
+		Kind:      WorkloadStatefulSet,
+		Name:      statefulSet.Name,
+		Namespace: statefulSet.Namespace,
+		Desired:   desired,
+		Ready:     statefulSet.Status.ReadyReplicas,
+		UpToDate:  statefulSet.Status.UpdatedReplicas,
+		Available: statefulSet.Status.AvailableReplicas,
+		Images:    containerImages(statefulSet.Spec.Template.Spec),
+		CreatedAt: statefulSet.CreationTimestamp,
+	}
+}
+
+// newDaemonSetInfo converts a DaemonSet into WorkloadInfo, counting the nodes it should run on as replicas
+func newDaemonSetInfo(daemonSet *appsv1.DaemonSet) WorkloadInfo {
+	return WorkloadInfo{
+		UID:       string(daemonSet.UID),
+		Kind:      WorkloadDaemonSet,
+		Name:      daemonSet.Name,
+		Namespace: daemonSet.Namespace,
+		Desired:   daemonSet.Status.DesiredNumberScheduled,
+		Ready:     daemonSet.Status.NumberReady,
+		UpToDate:  daemonSet.Status.UpdatedNumberScheduled,
+		Available: daemonSet.Status.NumberAvailable,
+		Images:    containerImages(daemonSet.Spec.Template.Spec),
+		CreatedAt: daemonSet.CreationTimestamp,
+	}
+}
+
+// containerImages returns the images of the containers of a pod template
+func containerImages(spec corev1.PodSpec) []string {
+	images := []string{}
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// sortWorkloads sorts workloads by namespace then name
+func sortWorkloads(workloads []WorkloadInfo) {
+	sort.SliceStable(workloads, func(i, j int) bool {
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+}
+
+// SearchWorkloadsAllContexts searches for workloads of a kind by name across all (or specified) contexts
+// and all (or specified) namespaces
+func SearchWorkloadsAllContexts(ctx context.Context, kubeconfigPath string, kind string, name string, namespaces []string, contexts []string, opts SearchOptions) ([]WorkloadResultWithContext, error) {
+	collected, ctx, cancel := newCollector[WorkloadResultWithContext](ctx, opts.MaxResults, nil)
+	defer cancel()
+
+	err := forEachNamespace(ctx, kubeconfigPath, namespaces, contexts, opts, func(ctx context.Context, client *K8sClient, contextName string, namespace string) (bool, error) {
+		workloads, err := client.SearchWorkloads(ctx, kind, name)
+		if err != nil {
+			return false, err
+		}
+		workloads = workloads[:collected.limit.take(len(workloads))]
+
+		// Only add results if found something
+		if len(workloads) > 0 {
+			collected.add(WorkloadResultWithContext{
+				Context:   contextName,
+				Server:    client.Server(),
+				Namespace: namespace,
+				Workloads: workloads,
+			})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := collected.results
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Context != results[j].Context {
+			return results[i].Context < results[j].Context
+		}
+		return results[i].Namespace < results[j].Namespace
+	})
+	return results, nil
+}
+
+// DedupWorkloadResults removes workloads already reported by another context pointing at the same cluster
+func DedupWorkloadResults(results []WorkloadResultWithContext) []WorkloadResultWithContext {
+	seen := map[string]bool{}
+	deduped := []WorkloadResultWithContext{}
+
+	for _, result := range results {
+		// Results of a context without a known server cannot be told apart from other clusters, so they are all kept
+		if result.Server == "" {
+			deduped = append(deduped, result)
+			continue
+		}
+		server := result.Server
+
+		workloads := []WorkloadInfo{}
+		for _, workload := range result.Workloads {
+			key := fmt.Sprintf("%s/%s/%s/%s", server, workload.Kind, workload.Namespace, workload.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			workloads = append(workloads, workload)
+		}
+
+		if len(workloads) > 0 {
+			result.Workloads = workloads
+			deduped = append(deduped, result)
+		}
+	}
+
+	return deduped
+}
@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseWorkloadKind tests the names accepted for each workload kind
+func TestParseWorkloadKind(t *testing.T) {
+	for name, expected := range map[string]string{
+		"deployment":  WorkloadDeployment,
+		"Deployments": WorkloadDeployment,
+		"deploy":      WorkloadDeployment,
+		"sts":         WorkloadStatefulSet,
+		"StatefulSet": WorkloadStatefulSet,
+		" ds ":        WorkloadDaemonSet,
+		"daemonsets":  WorkloadDaemonSet,
+	} {
+		kind, err := ParseWorkloadKind(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, kind, name)
+	}
+
+	_, err := ParseWorkloadKind("job")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	// Services are not workloads; --kind service narrows IP searches instead
+	assert.True(t, IsServiceKind("svc"))
+	assert.True(t, IsServiceKind(" Services"))
+	assert.False(t, IsServiceKind("deploy"))
+	_, err = ParseWorkloadKind("service")
+	assert.Error(t, err)
+}
+
+// TestSearchWorkloads tests searching Deployments, StatefulSets and DaemonSets by name
+func TestSearchWorkloads(t *testing.T) {
+	replicas := int32(3)
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}}}
+	fakeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Template: template},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-canary", Namespace: "web"},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-cache", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-agent", Namespace: "default"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, NumberReady: 4, UpdatedNumberScheduled: 2, NumberAvailable: 4},
+		},
+	)
+
+	client := &K8sClient{
+		Clientset:  fakeClient,
+		Namespaces: []string{"default", "web"},
+	}
+
+	ctx := context.Background()
+
+	deployments, err := client.SearchWorkloads(ctx, WorkloadDeployment, "web")
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, WorkloadInfo{
+		Kind:      WorkloadDeployment,
+		Name:      "web",
+		Namespace: "default",
+		Desired:   3,
+		Ready:     2,
+		UpToDate:  3,
+		Available: 2,
+		Images:    []string{"nginx:1.27"},
+	}, deployments[0])
+	// An unset replica count defaults to 1
+	assert.Equal(t, "web-canary", deployments[1].Name)
+	assert.Equal(t, int32(1), deployments[1].Desired)
+
+	statefulSets, err := client.SearchWorkloads(ctx, WorkloadStatefulSet, "web")
+	require.NoError(t, err)
+	require.Len(t, statefulSets, 1)
+	assert.Equal(t, "web-cache", statefulSets[0].Name)
+	assert.Equal(t, int32(3), statefulSets[0].Ready)
+
+	daemonSets, err := client.SearchWorkloads(ctx, WorkloadDaemonSet, "agent")
+	require.NoError(t, err)
+	require.Len(t, daemonSets, 1)
+	assert.Equal(t, int32(4), daemonSets[0].Desired)
+	assert.Equal(t, int32(2), daemonSets[0].UpToDate)
+
+	// Exact matching skips web-canary
+	client.Options.ExactName = true
+	deployments, err = client.SearchWorkloads(ctx, WorkloadDeployment, "web")
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, "web", deployments[0].Name)
+
+	// The result cap keeps the first workloads in namespace and name order
+	client.Options.ExactName = false
+	client.Options.MaxResults = 1
+	deployments, err = client.SearchWorkloads(ctx, WorkloadDeployment, "")
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, "api", deployments[0].Name)
+
+	_, err = client.SearchWorkloads(ctx, "Job", "web")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+// TestDedupWorkloadResults tests dropping workloads reported by two contexts of the same cluster
+func TestDedupWorkloadResults(t *testing.T) {
+	web := WorkloadInfo{Kind: WorkloadDeployment, Name: "web", Namespace: "default"}
+	cache := WorkloadInfo{Kind: WorkloadStatefulSet, Name: "web", Namespace: "default"}
+
+	results := DedupWorkloadResults([]WorkloadResultWithContext{
+		{Context: "admin", Server: "https://cluster", Namespace: "default", Workloads: []WorkloadInfo{web}},
+		{Context: "viewer", Server: "https://cluster", Namespace: "default", Workloads: []WorkloadInfo{web, cache}},
+	})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, []WorkloadInfo{web}, results[0].Workloads)
+	assert.Equal(t, []WorkloadInfo{cache}, results[1].Workloads)
+}